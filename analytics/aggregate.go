@@ -0,0 +1,168 @@
+package analytics
+
+import (
+	"sort"
+	"time"
+
+	redmine "github.com/nixys/nxs-go-redmine/v5"
+)
+
+// GroupBy defines issues grouping type for aggregation
+type GroupBy string
+
+// GroupBy const
+const (
+	GroupByProject GroupBy = "project"
+	GroupByTracker GroupBy = "tracker"
+	GroupByVersion GroupBy = "version"
+)
+
+// AggregatedStats contains statistics for group of issues
+type AggregatedStats struct {
+	Group                redmine.IDName // Group ID is 0 for issues without version
+	Issues               int
+	Closed               int
+	LeadTime             DurationStats
+	CycleTime            DurationStats
+	StatusDurations      map[int64]time.Duration // Total time spent by issues in every status
+	StatusDurationsMeans map[int64]time.Duration // Mean time spent by issue in every status
+}
+
+// DurationStats contains statistics for set of durations
+type DurationStats struct {
+	Count  int
+	Min    time.Duration
+	Max    time.Duration
+	Mean   time.Duration
+	Median time.Duration
+	P85    time.Duration
+}
+
+func (g GroupBy) String() string {
+	return string(g)
+}
+
+// Aggregate groups issues statistics and calculates aggregated values for every group.
+// Result is ordered by group ID
+func Aggregate(stats []IssueStats, by GroupBy) []AggregatedStats {
+
+	var (
+		keys   []int64
+		groups = make(map[int64][]IssueStats)
+		names  = make(map[int64]string)
+	)
+
+	for _, s := range stats {
+
+		var g redmine.IDName
+
+		switch by {
+		case GroupByProject:
+			g = s.Project
+		case GroupByTracker:
+			g = s.Tracker
+		case GroupByVersion:
+			if s.Version != nil {
+				g = *s.Version
+			}
+		}
+
+		if _, b := groups[g.ID]; b == false {
+			keys = append(keys, g.ID)
+			names[g.ID] = g.Name
+		}
+
+		groups[g.ID] = append(groups[g.ID], s)
+	}
+
+	sort.Slice(keys, func(i, j int) bool {
+		return keys[i] < keys[j]
+	})
+
+	var as []AggregatedStats
+
+	for _, k := range keys {
+
+		var lt, ct []time.Duration
+
+		a := AggregatedStats{
+			Group: redmine.IDName{
+				ID:   k,
+				Name: names[k],
+			},
+			Issues:               len(groups[k]),
+			StatusDurations:      make(map[int64]time.Duration),
+			StatusDurationsMeans: make(map[int64]time.Duration),
+		}
+
+		for _, s := range groups[k] {
+
+			if s.ClosedOn != nil {
+				a.Closed++
+			}
+
+			if s.LeadTime != nil {
+				lt = append(lt, *s.LeadTime)
+			}
+
+			if s.CycleTime != nil {
+				ct = append(ct, *s.CycleTime)
+			}
+
+			for id, d := range s.StatusDurations {
+				a.StatusDurations[id] += d
+			}
+		}
+
+		for id, d := range a.StatusDurations {
+			a.StatusDurationsMeans[id] = d / time.Duration(a.Issues)
+		}
+
+		a.LeadTime = durationStats(lt)
+		a.CycleTime = durationStats(ct)
+
+		as = append(as, a)
+	}
+
+	return as
+}
+
+func durationStats(ds []time.Duration) DurationStats {
+
+	var (
+		s   DurationStats
+		sum time.Duration
+	)
+
+	if len(ds) == 0 {
+		return s
+	}
+
+	sort.Slice(ds, func(i, j int) bool {
+		return ds[i] < ds[j]
+	})
+
+	for _, d := range ds {
+		sum += d
+	}
+
+	s.Count = len(ds)
+	s.Min = ds[0]
+	s.Max = ds[len(ds)-1]
+	s.Mean = sum / time.Duration(len(ds))
+	s.Median = percentile(ds, 50)
+	s.P85 = percentile(ds, 85)
+
+	return s
+}
+
+// percentile calculates percentile for sorted durations using nearest-rank method
+func percentile(ds []time.Duration, p int) time.Duration {
+
+	n := (p*len(ds) + 99) / 100
+	if n < 1 {
+		n = 1
+	}
+
+	return ds[n-1]
+}
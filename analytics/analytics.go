@@ -0,0 +1,228 @@
+package analytics
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	redmine "github.com/nixys/nxs-go-redmine/v5"
+)
+
+// Settings contains data to init analyzer
+type Settings struct {
+
+	// Statuses meaning the work on issue has been started. Cycle time is counted
+	// from the first transition into any of them. If not set cycle time is counted
+	// from the first transition out of the status issue has been created with
+	StartStatusIDs []int64
+}
+
+// Analyzer calculates issues time statistics based on issue journals
+type Analyzer struct {
+	r        *redmine.Context
+	statuses map[int64]redmine.IssueStatusObject
+	start    map[int64]bool
+}
+
+// IssueStats contains time statistics for single issue
+type IssueStats struct {
+	IssueID         int64
+	Subject         string
+	Project         redmine.IDName
+	Tracker         redmine.IDName
+	Version         *redmine.IDName
+	Status          redmine.IDName
+	CreatedOn       time.Time
+	StartedOn       *time.Time
+	ClosedOn        *time.Time              // Moment of the last transition into closed status. Nil if issue is open
+	LeadTime        *time.Duration          // From creation to close. Nil if issue is open
+	CycleTime       *time.Duration          // From start to close. Nil if issue is open or has not been started
+	StatusDurations map[int64]time.Duration // Time spent in every open status (key is a status ID)
+}
+
+// Init creates new analyzer. Issue statuses are requested from Redmine
+func Init(r *redmine.Context, s Settings) (*Analyzer, error) {
+
+	statuses, _, err := r.IssueStatusAllGet()
+	if err != nil {
+		return nil, fmt.Errorf("analytics: issue statuses get error: %w", err)
+	}
+
+	return InitWithStatuses(r, statuses, s), nil
+}
+
+// InitWithStatuses creates new analyzer with specified issue statuses
+func InitWithStatuses(r *redmine.Context, statuses []redmine.IssueStatusObject, s Settings) *Analyzer {
+
+	a := &Analyzer{
+		r:        r,
+		statuses: make(map[int64]redmine.IssueStatusObject),
+		start:    make(map[int64]bool),
+	}
+
+	for _, e := range statuses {
+		a.statuses[e.ID] = e
+	}
+
+	for _, id := range s.StartStatusIDs {
+		a.start[id] = true
+	}
+
+	return a
+}
+
+// Statuses returns issue statuses known by analyzer ordered by ID
+func (a *Analyzer) Statuses() []redmine.IssueStatusObject {
+
+	var ss []redmine.IssueStatusObject
+
+	for _, s := range a.statuses {
+		ss = append(ss, s)
+	}
+
+	sort.Slice(ss, func(i, j int) bool {
+		return ss[i].ID < ss[j].ID
+	})
+
+	return ss
+}
+
+// IssueStatsGet gets issue with specified ID and calculates its statistics
+func (a *Analyzer) IssueStatsGet(id int64) (IssueStats, error) {
+
+	i, _, err := a.r.IssueSingleGet(
+		id,
+		redmine.IssueSingleGetRequest{
+			Includes: []redmine.IssueInclude{
+				redmine.IssueIncludeJournals,
+			},
+		},
+	)
+	if err != nil {
+		return IssueStats{}, fmt.Errorf("analytics: issue %d get error: %w", id, err)
+	}
+
+	return a.IssueStatsBuild(i, time.Now())
+}
+
+// IssuesStatsGet calculates statistics for all issues satisfying specified filters.
+// Redmine returns only open issues if status filter is not set, so `status_id=*`
+// is added into a copy of the filters (nil filters are allowed) to include closed
+// issues lead and cycle times are counted for
+func (a *Analyzer) IssuesStatsGet(filters *redmine.IssueGetRequestFilters) ([]IssueStats, error) {
+
+	var stats []IssueStats
+
+	if filters == nil {
+		filters = redmine.IssueGetRequestFiltersInit()
+	}
+
+	if filters.IsSet(redmine.IssueFilterFieldStatusID.String()) == false {
+		filters = filters.Clone().Add(redmine.IssueFilterFieldStatusID, redmine.FilterOperatorAny)
+	}
+
+	issues, _, err := a.r.IssuesAllGet(
+		redmine.IssueAllGetRequest{
+			Filters: filters,
+		},
+	)
+	if err != nil {
+		return nil, fmt.Errorf("analytics: issues get error: %w", err)
+	}
+
+	for _, i := range issues.Issues {
+
+		s, err := a.IssueStatsGet(i.ID)
+		if err != nil {
+			return nil, err
+		}
+
+		stats = append(stats, s)
+	}
+
+	return stats, nil
+}
+
+// IssueStatsBuild calculates statistics for specified issue. Issue must be got with
+// `IssueIncludeJournals` include. Time in current status of open issue is counted till `now`
+func (a *Analyzer) IssueStatsBuild(issue redmine.IssueObject, now time.Time) (IssueStats, error) {
+
+	s := IssueStats{
+		IssueID:         issue.ID,
+		Subject:         issue.Subject,
+		Project:         issue.Project,
+		Tracker:         issue.Tracker,
+		Version:         issue.FixedVersion,
+		Status:          redmine.IDName{ID: issue.Status.ID, Name: issue.Status.Name},
+		StatusDurations: make(map[int64]time.Duration),
+	}
+
+	createdOn := issue.CreatedOn.Time
+	s.CreatedOn = createdOn
+
+	h, err := redmine.IssueHistoryBuild(issue)
+	if err != nil {
+		return s, fmt.Errorf("analytics: issue %d: %w", issue.ID, err)
+	}
+
+	changes := h.FieldTimeline("status_id")
+
+	// Status issue has been created with
+	status := issue.Status.ID
+	if len(changes) > 0 {
+		if status, err = strconv.ParseInt(changes[0].OldValue, 10, 64); err != nil {
+			return s, fmt.Errorf("analytics: issue %d: incorrect status id `%s`", issue.ID, changes[0].OldValue)
+		}
+	}
+
+	from := createdOn
+	closed := a.statuses[status].IsClosed
+
+	for _, c := range changes {
+
+		next, err := strconv.ParseInt(c.NewValue, 10, 64)
+		if err != nil {
+			return s, fmt.Errorf("analytics: issue %d: incorrect status id `%s`", issue.ID, c.NewValue)
+		}
+
+		if closed == false {
+			s.StatusDurations[status] += c.CreatedOn.Sub(from)
+		}
+
+		if s.StartedOn == nil && (a.start[next] == true || (len(a.start) == 0 && next != status)) {
+			t := c.CreatedOn
+			s.StartedOn = &t
+		}
+
+		closed = a.statuses[next].IsClosed
+		if closed == true {
+			t := c.CreatedOn
+			s.ClosedOn = &t
+		}
+
+		status = next
+		from = c.CreatedOn
+	}
+
+	if closed == false {
+		s.ClosedOn = nil
+		s.StatusDurations[status] += now.Sub(from)
+		return s, nil
+	}
+
+	if s.ClosedOn == nil {
+		// Issue has been created in closed status
+		s.ClosedOn = &createdOn
+	}
+
+	lt := s.ClosedOn.Sub(createdOn)
+	s.LeadTime = &lt
+
+	if s.StartedOn != nil {
+		ct := s.ClosedOn.Sub(*s.StartedOn)
+		s.CycleTime = &ct
+	}
+
+	return s, nil
+}
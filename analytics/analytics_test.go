@@ -0,0 +1,152 @@
+package analytics
+
+import (
+	"bytes"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	redmine "github.com/nixys/nxs-go-redmine/v5"
+	"github.com/nixys/nxs-go-redmine/v5/redminetest"
+)
+
+var testStatuses = []redmine.IssueStatusObject{
+	{ID: 1, Name: "New"},
+	{ID: 2, Name: "In Progress"},
+	{ID: 3, Name: "Review"},
+	{ID: 5, Name: "Closed", IsClosed: true},
+}
+
+func TestIssueStats(t *testing.T) {
+
+	a := InitWithStatuses(nil, testStatuses, Settings{StartStatusIDs: []int64{2}})
+
+	closed, err := a.IssueStatsBuild(redmine.IssueObject{
+		ID:        1,
+		Project:   redmine.IDName{ID: 1, Name: "Project"},
+		Status:    redmine.IssueStatusObject{ID: 5, Name: "Closed"},
+		CreatedOn: testDateTime("2024-01-01T00:00:00Z"),
+		Journals: &[]redmine.IssueJournalObject{
+			{ID: 1, CreatedOn: testDateTime("2024-01-01T10:00:00Z"), Details: []redmine.IssueJournalDetailObject{{Property: "attr", Name: "status_id", OldValue: "1", NewValue: "2"}}},
+			{ID: 2, CreatedOn: testDateTime("2024-01-02T10:00:00Z"), Details: []redmine.IssueJournalDetailObject{{Property: "attr", Name: "status_id", OldValue: "2", NewValue: "3"}}},
+			{ID: 3, CreatedOn: testDateTime("2024-01-02T12:00:00Z"), Details: []redmine.IssueJournalDetailObject{{Property: "attr", Name: "status_id", OldValue: "3", NewValue: "5"}}},
+		},
+	}, time.Now())
+	if err != nil {
+		t.Fatal("Issue stats build error:", err)
+	}
+
+	if closed.LeadTime == nil || *closed.LeadTime != 36*time.Hour {
+		t.Fatal("Issue stats build error: incorrect lead time")
+	}
+
+	if closed.CycleTime == nil || *closed.CycleTime != 26*time.Hour {
+		t.Fatal("Issue stats build error: incorrect cycle time")
+	}
+
+	if closed.StatusDurations[1] != 10*time.Hour || closed.StatusDurations[2] != 24*time.Hour || closed.StatusDurations[3] != 2*time.Hour {
+		t.Fatal("Issue stats build error: incorrect status durations")
+	}
+
+	open, err := a.IssueStatsBuild(redmine.IssueObject{
+		ID:        2,
+		Project:   redmine.IDName{ID: 1, Name: "Project"},
+		Status:    redmine.IssueStatusObject{ID: 1, Name: "New"},
+		CreatedOn: testDateTime("2024-01-01T00:00:00Z"),
+		Journals:  &[]redmine.IssueJournalObject{},
+	}, time.Date(2024, 1, 1, 5, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatal("Issue stats build error:", err)
+	}
+
+	if open.ClosedOn != nil || open.LeadTime != nil || open.StatusDurations[1] != 5*time.Hour {
+		t.Fatal("Issue stats build error: incorrect open issue stats")
+	}
+
+	aggs := Aggregate([]IssueStats{closed, open}, GroupByProject)
+	if len(aggs) != 1 || aggs[0].Issues != 2 || aggs[0].Closed != 1 || aggs[0].LeadTime.Median != 36*time.Hour {
+		t.Fatal("Issue stats aggregate error: incorrect aggregated stats")
+	}
+
+	var b bytes.Buffer
+
+	if err := WriteIssuesCSV(&b, []IssueStats{closed, open}, testStatuses); err != nil {
+		t.Fatal("Issue stats write error:", err)
+	}
+
+	if l := strings.Split(strings.TrimSpace(b.String()), "\n"); len(l) != 3 || strings.HasPrefix(l[1], "1,,Project,,,Closed,") == false {
+		t.Fatal("Issue stats write error: incorrect CSV:", b.String())
+	}
+
+	b.Reset()
+
+	if err := WriteAggregatesJSON(&b, aggs, testStatuses); err != nil {
+		t.Fatal("Issue stats write error:", err)
+	}
+
+	if strings.Contains(b.String(), `"In Progress":24`) == false {
+		t.Fatal("Issue stats write error: incorrect JSON:", b.String())
+	}
+
+	t.Logf("Issue stats: success")
+}
+
+func testDateTime(s string) redmine.DateTime {
+	d, _ := redmine.DateTimeParse(s)
+	return d
+}
+
+func TestIssuesStatsGet(t *testing.T) {
+
+	s := redminetest.Init(redminetest.Settings{})
+	defer s.Close()
+
+	r := s.Context()
+
+	p, _, err := r.ProjectCreate(redmine.ProjectCreate{Project: redmine.ProjectCreateObject{Name: "acme", Identifier: "acme"}})
+	if err != nil {
+		t.Fatal("Project create error:", err)
+	}
+
+	for _, st := range []int64{redminetest.StatusNewID, redminetest.StatusClosedID} {
+		if _, _, err := r.IssueCreate(redmine.IssueCreate{Issue: redmine.IssueCreateObject{ProjectID: p.ID, Subject: "Issue", StatusID: redmine.Int64Ptr(st)}}); err != nil {
+			t.Fatal("Issue create error:", err)
+		}
+	}
+
+	a, err := Init(r, Settings{})
+	if err != nil {
+		t.Fatal("Analyzer init error:", err)
+	}
+
+	// Closed issues are included if status filter is not set
+	stats, err := a.IssuesStatsGet(nil)
+	if err != nil {
+		t.Fatal("Issues stats get error:", err)
+	}
+
+	if len(stats) != 2 {
+		t.Fatal("Issues stats get error: unexpected issues count:", len(stats))
+	}
+
+	// Caller filters are not changed
+	f := redmine.IssueGetRequestFiltersInit().Add(redmine.IssueFilterFieldProjectID, redmine.FilterOperatorEqual, strconv.FormatInt(p.ID, 10))
+
+	if stats, err = a.IssuesStatsGet(f); err != nil || len(stats) != 2 {
+		t.Fatal("Issues stats get error:", err, len(stats))
+	}
+
+	if f.IsSet(redmine.IssueFilterFieldStatusID.String()) == true {
+		t.Fatal("Issues stats get error: caller filters are changed")
+	}
+
+	stats, err = a.IssuesStatsGet(redmine.IssueGetRequestFiltersInit().Add(redmine.IssueFilterFieldStatusID, redmine.FilterOperatorOpen))
+	if err != nil {
+		t.Fatal("Issues stats get error:", err)
+	}
+
+	if len(stats) != 1 {
+		t.Fatal("Issues stats get error: status filter is overridden")
+	}
+}
@@ -0,0 +1,276 @@
+package analytics
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"strconv"
+	"time"
+
+	redmine "github.com/nixys/nxs-go-redmine/v5"
+)
+
+type issueStatsJSON struct {
+	IssueID     int64              `json:"issue_id"`
+	Subject     string             `json:"subject"`
+	Project     redmine.IDName     `json:"project"`
+	Tracker     redmine.IDName     `json:"tracker"`
+	Version     *redmine.IDName    `json:"version,omitempty"`
+	Status      redmine.IDName     `json:"status"`
+	CreatedOn   time.Time          `json:"created_on"`
+	StartedOn   *time.Time         `json:"started_on,omitempty"`
+	ClosedOn    *time.Time         `json:"closed_on,omitempty"`
+	LeadTime    *float64           `json:"lead_time_hours,omitempty"`
+	CycleTime   *float64           `json:"cycle_time_hours,omitempty"`
+	StatusHours map[string]float64 `json:"status_hours"`
+}
+
+type aggregatedStatsJSON struct {
+	Group            redmine.IDName     `json:"group"`
+	Issues           int                `json:"issues"`
+	Closed           int                `json:"closed"`
+	LeadTime         durationStatsJSON  `json:"lead_time_hours"`
+	CycleTime        durationStatsJSON  `json:"cycle_time_hours"`
+	StatusHours      map[string]float64 `json:"status_hours"`
+	StatusHoursMeans map[string]float64 `json:"status_hours_means"`
+}
+
+type durationStatsJSON struct {
+	Count  int     `json:"count"`
+	Min    float64 `json:"min"`
+	Max    float64 `json:"max"`
+	Mean   float64 `json:"mean"`
+	Median float64 `json:"median"`
+	P85    float64 `json:"p85"`
+}
+
+// WriteIssuesJSON writes issues statistics in JSON format.
+// Durations are written in hours, status durations are keyed by status names
+func WriteIssuesJSON(w io.Writer, stats []IssueStats, statuses []redmine.IssueStatusObject) error {
+
+	names := statusNames(statuses)
+
+	out := []issueStatsJSON{}
+
+	for _, s := range stats {
+		out = append(out, issueStatsJSON{
+			IssueID:     s.IssueID,
+			Subject:     s.Subject,
+			Project:     s.Project,
+			Tracker:     s.Tracker,
+			Version:     s.Version,
+			Status:      s.Status,
+			CreatedOn:   s.CreatedOn,
+			StartedOn:   s.StartedOn,
+			ClosedOn:    s.ClosedOn,
+			LeadTime:    hoursPtr(s.LeadTime),
+			CycleTime:   hoursPtr(s.CycleTime),
+			StatusHours: statusHours(s.StatusDurations, names),
+		})
+	}
+
+	return json.NewEncoder(w).Encode(out)
+}
+
+// WriteIssuesCSV writes issues statistics in CSV format with a column
+// for every specified status. Durations are written in hours
+func WriteIssuesCSV(w io.Writer, stats []IssueStats, statuses []redmine.IssueStatusObject) error {
+
+	c := csv.NewWriter(w)
+
+	h := []string{
+		"issue_id",
+		"subject",
+		"project",
+		"tracker",
+		"version",
+		"status",
+		"created_on",
+		"started_on",
+		"closed_on",
+		"lead_time_hours",
+		"cycle_time_hours",
+	}
+	for _, s := range statuses {
+		h = append(h, "status_hours:"+s.Name)
+	}
+
+	if err := c.Write(h); err != nil {
+		return err
+	}
+
+	for _, s := range stats {
+
+		r := []string{
+			strconv.FormatInt(s.IssueID, 10),
+			s.Subject,
+			s.Project.Name,
+			s.Tracker.Name,
+			func() string {
+				if s.Version == nil {
+					return ""
+				}
+				return s.Version.Name
+			}(),
+			s.Status.Name,
+			s.CreatedOn.Format(time.RFC3339),
+			timeString(s.StartedOn),
+			timeString(s.ClosedOn),
+			hoursString(s.LeadTime),
+			hoursString(s.CycleTime),
+		}
+
+		for _, st := range statuses {
+			d := s.StatusDurations[st.ID]
+			r = append(r, hoursString(&d))
+		}
+
+		if err := c.Write(r); err != nil {
+			return err
+		}
+	}
+
+	c.Flush()
+
+	return c.Error()
+}
+
+// WriteAggregatesJSON writes aggregated statistics in JSON format.
+// Durations are written in hours, status durations are keyed by status names
+func WriteAggregatesJSON(w io.Writer, aggs []AggregatedStats, statuses []redmine.IssueStatusObject) error {
+
+	names := statusNames(statuses)
+
+	out := []aggregatedStatsJSON{}
+
+	for _, a := range aggs {
+		out = append(out, aggregatedStatsJSON{
+			Group:            a.Group,
+			Issues:           a.Issues,
+			Closed:           a.Closed,
+			LeadTime:         durationStatsHours(a.LeadTime),
+			CycleTime:        durationStatsHours(a.CycleTime),
+			StatusHours:      statusHours(a.StatusDurations, names),
+			StatusHoursMeans: statusHours(a.StatusDurationsMeans, names),
+		})
+	}
+
+	return json.NewEncoder(w).Encode(out)
+}
+
+// WriteAggregatesCSV writes aggregated statistics in CSV format with a column
+// of mean time for every specified status. Durations are written in hours
+func WriteAggregatesCSV(w io.Writer, aggs []AggregatedStats, statuses []redmine.IssueStatusObject) error {
+
+	c := csv.NewWriter(w)
+
+	h := []string{
+		"group_id",
+		"group",
+		"issues",
+		"closed",
+		"lead_time_mean_hours",
+		"lead_time_median_hours",
+		"lead_time_p85_hours",
+		"cycle_time_mean_hours",
+		"cycle_time_median_hours",
+		"cycle_time_p85_hours",
+	}
+	for _, s := range statuses {
+		h = append(h, "status_hours_mean:"+s.Name)
+	}
+
+	if err := c.Write(h); err != nil {
+		return err
+	}
+
+	for _, a := range aggs {
+
+		r := []string{
+			strconv.FormatInt(a.Group.ID, 10),
+			a.Group.Name,
+			strconv.Itoa(a.Issues),
+			strconv.Itoa(a.Closed),
+			hoursString(&a.LeadTime.Mean),
+			hoursString(&a.LeadTime.Median),
+			hoursString(&a.LeadTime.P85),
+			hoursString(&a.CycleTime.Mean),
+			hoursString(&a.CycleTime.Median),
+			hoursString(&a.CycleTime.P85),
+		}
+
+		for _, st := range statuses {
+			d := a.StatusDurationsMeans[st.ID]
+			r = append(r, hoursString(&d))
+		}
+
+		if err := c.Write(r); err != nil {
+			return err
+		}
+	}
+
+	c.Flush()
+
+	return c.Error()
+}
+
+func statusNames(statuses []redmine.IssueStatusObject) map[int64]string {
+
+	names := make(map[int64]string)
+
+	for _, s := range statuses {
+		names[s.ID] = s.Name
+	}
+
+	return names
+}
+
+func statusHours(ds map[int64]time.Duration, names map[int64]string) map[string]float64 {
+
+	h := make(map[string]float64)
+
+	for id, d := range ds {
+
+		n, b := names[id]
+		if b == false {
+			n = strconv.FormatInt(id, 10)
+		}
+
+		h[n] = d.Hours()
+	}
+
+	return h
+}
+
+func durationStatsHours(s DurationStats) durationStatsJSON {
+	return durationStatsJSON{
+		Count:  s.Count,
+		Min:    s.Min.Hours(),
+		Max:    s.Max.Hours(),
+		Mean:   s.Mean.Hours(),
+		Median: s.Median.Hours(),
+		P85:    s.P85.Hours(),
+	}
+}
+
+func hoursPtr(d *time.Duration) *float64 {
+	if d == nil {
+		return nil
+	}
+	h := d.Hours()
+	return &h
+}
+
+func hoursString(d *time.Duration) string {
+	if d == nil {
+		return ""
+	}
+	return strconv.FormatFloat(d.Hours(), 'f', 2, 64)
+}
+
+func timeString(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.Format(time.RFC3339)
+}
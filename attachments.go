@@ -15,15 +15,15 @@ import (
 
 // AttachmentObject struct used for attachments get operations
 type AttachmentObject struct {
-	ID           int64  `json:"id"`
-	FileName     string `json:"filename"`
-	FileSize     string `json:"filesize"`
-	ContentType  string `json:"content_type"`
-	Description  string `json:"description"`
-	ContentURL   string `json:"content_url"`
-	ThumbnailURL string `json:"thumbnail_url"`
-	Author       IDName `json:"author"`
-	CreatedOn    string `json:"created_on"`
+	ID           int64    `json:"id"`
+	FileName     string   `json:"filename"`
+	FileSize     int64    `json:"filesize"`
+	ContentType  string   `json:"content_type"`
+	Description  string   `json:"description"`
+	ContentURL   string   `json:"content_url"`
+	ThumbnailURL string   `json:"thumbnail_url"`
+	Author       IDName   `json:"author"`
+	CreatedOn    DateTime `json:"created_on"`
 }
 
 /* Upload */
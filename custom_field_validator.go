@@ -0,0 +1,315 @@
+package redmine
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// CustomFieldsValidator checks custom field values of create and update
+// objects against custom fields metadata before sending them to Redmine
+type CustomFieldsValidator struct {
+	fields      map[int64]CustomFieldObject
+	ids         []int64 // Custom fields IDs in metadata order
+	issueFields map[int64]bool
+}
+
+// CustomFieldError contains validation error for a custom field
+type CustomFieldError struct {
+	ID      int64
+	Name    string
+	Message string
+}
+
+// CustomFieldErrors contains all custom fields validation errors
+type CustomFieldErrors []CustomFieldError
+
+// Custom field customized types
+const (
+	customFieldTypeIssue     = "issue"
+	customFieldTypeProject   = "project"
+	customFieldTypeUser      = "user"
+	customFieldTypeTimeEntry = "time_entry"
+)
+
+// CustomFieldsValidatorInit creates validator with custom fields
+// metadata got by `CustomFieldAllGet`
+func CustomFieldsValidatorInit(cfs []CustomFieldObject) CustomFieldsValidator {
+
+	v := CustomFieldsValidator{
+		fields: make(map[int64]CustomFieldObject),
+	}
+
+	for _, c := range cfs {
+		v.fields[c.ID] = c
+		v.ids = append(v.ids, c.ID)
+	}
+
+	return v
+}
+
+// IssueCustomFieldsSet restricts issue custom fields to the ones enabled in the project
+// (see `ProjectIncludeIssueCustomFields`). Otherwise all issue custom fields are
+// considered enabled
+func (v CustomFieldsValidator) IssueCustomFieldsSet(cfs []IDName) CustomFieldsValidator {
+
+	v.issueFields = make(map[int64]bool)
+
+	for _, c := range cfs {
+		v.issueFields[c.ID] = true
+	}
+
+	return v
+}
+
+// IssueCreate validates issue create object. Required custom fields are
+// checked only if tracker is specified
+func (v CustomFieldsValidator) IssueCreate(o IssueCreateObject) error {
+	return v.validate(customFieldTypeIssue, o.TrackerID, o.CustomFields, true)
+}
+
+// IssueUpdate validates issue update object
+func (v CustomFieldsValidator) IssueUpdate(o IssueUpdateObject) error {
+	return v.validate(customFieldTypeIssue, o.TrackerID, o.CustomFields, false)
+}
+
+// ProjectCreate validates project create object
+func (v CustomFieldsValidator) ProjectCreate(o ProjectCreateObject) error {
+	return v.validate(customFieldTypeProject, nil, o.CustomFields, true)
+}
+
+// ProjectUpdate validates project update object
+func (v CustomFieldsValidator) ProjectUpdate(o ProjectUpdateObject) error {
+	return v.validate(customFieldTypeProject, nil, o.CustomFields, false)
+}
+
+// UserCreate validates user create object
+func (v CustomFieldsValidator) UserCreate(o UserCreateObject) error {
+	return v.validate(customFieldTypeUser, nil, o.CustomFields, true)
+}
+
+// UserUpdate validates user update object
+func (v CustomFieldsValidator) UserUpdate(o UserUpdateObject) error {
+	return v.validate(customFieldTypeUser, nil, o.CustomFields, false)
+}
+
+// TimeEntryCreate validates time entry create object
+func (v CustomFieldsValidator) TimeEntryCreate(o TimeEntryCreateObject) error {
+	return v.validate(customFieldTypeTimeEntry, nil, o.CustomFields, true)
+}
+
+// TimeEntryUpdate validates time entry update object
+func (v CustomFieldsValidator) TimeEntryUpdate(o TimeEntryUpdateObject) error {
+	return v.validate(customFieldTypeTimeEntry, nil, o.CustomFields, false)
+}
+
+func (e CustomFieldError) Error() string {
+	return fmt.Sprintf("custom field %q (id %d) %s", e.Name, e.ID, e.Message)
+}
+
+func (e CustomFieldErrors) Error() string {
+
+	var s []string
+
+	for _, c := range e {
+		s = append(s, c.Error())
+	}
+
+	return strings.Join(s, "\n")
+}
+
+func (v CustomFieldsValidator) validate(customizedType string, trackerID *int64, in *[]CustomFieldUpdateObject, create bool) error {
+
+	var errs CustomFieldErrors
+
+	values := make(map[int64]CustomFieldValue)
+
+	if in != nil {
+		for _, e := range *in {
+
+			c, b := v.fields[e.ID]
+			if b == false {
+				errs = append(errs, CustomFieldError{ID: e.ID, Message: "is unknown"})
+				continue
+			}
+
+			if v.available(c, customizedType, trackerID) == false {
+				errs = append(errs, CustomFieldError{ID: c.ID, Name: c.Name, Message: "is not available"})
+				continue
+			}
+
+			cv, err := customFieldValueConvert(e.Value)
+			if err != nil {
+				errs = append(errs, CustomFieldError{ID: c.ID, Name: c.Name, Message: "has invalid value: " + err.Error()})
+				continue
+			}
+
+			values[c.ID] = cv
+
+			for _, m := range customFieldValueCheck(c, cv) {
+				errs = append(errs, CustomFieldError{ID: c.ID, Name: c.Name, Message: m})
+			}
+		}
+	}
+
+	for _, id := range v.ids {
+
+		c := v.fields[id]
+
+		if c.IsRequired == false || v.available(c, customizedType, trackerID) == false {
+			continue
+		}
+
+		cv, b := values[c.ID]
+		if b == false {
+			// On update only passed values are changed.
+			// Issue tracker must be known to check required fields on create
+			if create == false || (customizedType == customFieldTypeIssue && trackerID == nil) {
+				continue
+			}
+			if c.DefaultValue != nil && *c.DefaultValue != "" {
+				continue
+			}
+		} else if cv.IsEmpty() == false {
+			continue
+		}
+
+		errs = append(errs, CustomFieldError{ID: c.ID, Name: c.Name, Message: "cannot be blank"})
+	}
+
+	if len(errs) == 0 {
+		return nil
+	}
+
+	return errs
+}
+
+// available checks custom field can be set for specified customized type and tracker
+func (v CustomFieldsValidator) available(c CustomFieldObject, customizedType string, trackerID *int64) bool {
+
+	if c.CustomizedType != customizedType {
+		return false
+	}
+
+	if customizedType != customFieldTypeIssue {
+		return true
+	}
+
+	if v.issueFields != nil && v.issueFields[c.ID] == false {
+		return false
+	}
+
+	if trackerID == nil {
+		return true
+	}
+
+	for _, t := range c.Trackers {
+		if t.ID == *trackerID {
+			return true
+		}
+	}
+
+	return false
+}
+
+// customFieldValueConvert converts value of update object into `CustomFieldValue`
+func customFieldValueConvert(value interface{}) (CustomFieldValue, error) {
+
+	if cv, b := value.(CustomFieldValue); b == true {
+		return cv, nil
+	}
+
+	j, err := json.Marshal(value)
+	if err != nil {
+		return CustomFieldValue{}, err
+	}
+
+	var cv CustomFieldValue
+
+	if err := json.Unmarshal(j, &cv); err != nil {
+		return CustomFieldValue{}, err
+	}
+
+	return cv, nil
+}
+
+// customFieldValueCheck checks value by custom field format and returns error messages
+func customFieldValueCheck(c CustomFieldObject, cv CustomFieldValue) []string {
+
+	var msgs []string
+
+	if len(cv.List()) > 1 && c.Multiple == false {
+		msgs = append(msgs, "cannot have multiple values")
+	}
+
+	for _, s := range cv.List() {
+
+		switch c.FieldFormat {
+		case "int", "user", "version":
+			if _, err := strconv.ParseInt(s, 10, 64); err != nil {
+				msgs = append(msgs, fmt.Sprintf("value %q is not a number", s))
+				continue
+			}
+		case "float":
+			if _, err := strconv.ParseFloat(s, 64); err != nil {
+				msgs = append(msgs, fmt.Sprintf("value %q is not a number", s))
+				continue
+			}
+		case "date":
+			if _, err := DateParse(s); err != nil {
+				msgs = append(msgs, fmt.Sprintf("value %q is not a valid date", s))
+			}
+			continue
+		case "bool":
+			if s != "0" && s != "1" {
+				msgs = append(msgs, fmt.Sprintf("value %q is not a boolean", s))
+			}
+			continue
+		case "list", "enumeration":
+			if customFieldPossibleValue(c, s) == false {
+				msgs = append(msgs, fmt.Sprintf("value %q is not included in the list", s))
+			}
+			continue
+		}
+
+		if c.FieldFormat != "string" && c.FieldFormat != "text" && c.FieldFormat != "link" &&
+			c.FieldFormat != "int" && c.FieldFormat != "float" {
+			continue
+		}
+
+		if c.MinLength > 0 && int64(len([]rune(s))) < c.MinLength {
+			msgs = append(msgs, fmt.Sprintf("value %q is too short (minimum is %d characters)", s, c.MinLength))
+		}
+
+		if c.MaxLength > 0 && int64(len([]rune(s))) > c.MaxLength {
+			msgs = append(msgs, fmt.Sprintf("value %q is too long (maximum is %d characters)", s, c.MaxLength))
+		}
+
+		if c.Regexp != "" {
+			// Redmine uses Ruby regular expressions, skip ones not supported by Go
+			if re, err := regexp.Compile(c.Regexp); err == nil && re.MatchString(s) == false {
+				msgs = append(msgs, fmt.Sprintf("value %q is invalid", s))
+			}
+		}
+	}
+
+	return msgs
+}
+
+func customFieldPossibleValue(c CustomFieldObject, s string) bool {
+
+	// Possible values may be unavailable (e.g. for enumerations in old Redmine versions)
+	if c.PossibleValues == nil {
+		return true
+	}
+
+	for _, p := range *c.PossibleValues {
+		if p.Value == s {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,436 @@
+package redmine
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// CustomFieldValueInt creates custom field value for integer field
+func CustomFieldValueInt(v int64) CustomFieldValue {
+	return CustomFieldValueInit(strconv.FormatInt(v, 10))
+}
+
+// CustomFieldValueFloat creates custom field value for float field
+func CustomFieldValueFloat(v float64) CustomFieldValue {
+	return CustomFieldValueInit(strconv.FormatFloat(v, 'f', -1, 64))
+}
+
+// CustomFieldValueBool creates custom field value for boolean field
+func CustomFieldValueBool(v bool) CustomFieldValue {
+	if v == true {
+		return CustomFieldValueInit("1")
+	}
+	return CustomFieldValueInit("0")
+}
+
+// CustomFieldValueDate creates custom field value for date field
+func CustomFieldValueDate(v Date) CustomFieldValue {
+	return CustomFieldValueInit(v.String())
+}
+
+// CustomFieldValuesInt creates multiple custom field value from IDs
+// (e.g. for user or version fields)
+func CustomFieldValuesInt(vs []int64) CustomFieldValue {
+	c := CustomFieldValue{
+		Multiple: true,
+		Values:   []string{},
+	}
+	for _, v := range vs {
+		c.Values = append(c.Values, strconv.FormatInt(v, 10))
+	}
+	return c
+}
+
+// Int returns value of integer custom field. Also used to get ID
+// from user or version fields. Zero is returned for empty value
+func (v CustomFieldValue) Int() (int64, error) {
+
+	s := v.String()
+	if s == "" {
+		return 0, nil
+	}
+
+	i, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("custom field value %q is not an integer", s)
+	}
+
+	return i, nil
+}
+
+// Float returns value of float custom field. Zero is returned for empty value
+func (v CustomFieldValue) Float() (float64, error) {
+
+	s := v.String()
+	if s == "" {
+		return 0, nil
+	}
+
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0, fmt.Errorf("custom field value %q is not a float", s)
+	}
+
+	return f, nil
+}
+
+// Bool returns value of boolean custom field. False is returned for empty value
+func (v CustomFieldValue) Bool() (bool, error) {
+
+	switch v.String() {
+	case "":
+		return false, nil
+	case "1":
+		return true, nil
+	case "0":
+		return false, nil
+	}
+
+	return false, fmt.Errorf("custom field value %q is not a boolean", v.String())
+}
+
+// Date returns value of date custom field. Zero date is returned for empty value
+func (v CustomFieldValue) Date() (Date, error) {
+
+	s := v.String()
+	if s == "" {
+		return Date{}, nil
+	}
+
+	return DateParse(s)
+}
+
+// List returns non-empty values of custom field
+func (v CustomFieldValue) List() []string {
+
+	l := []string{}
+
+	for _, e := range v.Values {
+		if e != "" {
+			l = append(l, e)
+		}
+	}
+
+	return l
+}
+
+// Ints returns non-empty values of custom field as integers
+// (e.g. IDs of multiple user or version fields)
+func (v CustomFieldValue) Ints() ([]int64, error) {
+
+	is := []int64{}
+
+	for _, e := range v.List() {
+		i, err := strconv.ParseInt(e, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("custom field value %q is not an integer", e)
+		}
+		is = append(is, i)
+	}
+
+	return is, nil
+}
+
+// CustomFieldGetByID looks up custom field with specified ID
+func CustomFieldGetByID(cfs []CustomFieldGetObject, id int64) (CustomFieldGetObject, bool) {
+	for _, c := range cfs {
+		if c.ID == id {
+			return c, true
+		}
+	}
+	return CustomFieldGetObject{}, false
+}
+
+// CustomFieldGetByName looks up custom field with specified name
+func CustomFieldGetByName(cfs []CustomFieldGetObject, name string) (CustomFieldGetObject, bool) {
+	for _, c := range cfs {
+		if c.Name == name {
+			return c, true
+		}
+	}
+	return CustomFieldGetObject{}, false
+}
+
+// CustomFieldsSet sets value for custom field with specified ID.
+// Existing value for this custom field is replaced
+func CustomFieldsSet(cfs []CustomFieldUpdateObject, id int64, v CustomFieldValue) []CustomFieldUpdateObject {
+
+	for i, c := range cfs {
+		if c.ID == id {
+			cfs[i].Value = v
+			return cfs
+		}
+	}
+
+	return append(cfs, CustomFieldUpdateObject{
+		ID:    id,
+		Value: v,
+	})
+}
+
+// CustomFieldsSetByName sets value for custom field with specified name.
+// Custom field ID is looked up within `fields` (e.g. custom fields of got issue)
+func CustomFieldsSetByName(cfs []CustomFieldUpdateObject, fields []CustomFieldGetObject, name string, v CustomFieldValue) ([]CustomFieldUpdateObject, error) {
+
+	c, b := CustomFieldGetByName(fields, name)
+	if b == false {
+		return cfs, fmt.Errorf("custom field %q not found", name)
+	}
+
+	return CustomFieldsSet(cfs, c.ID, v), nil
+}
+
+// CustomFieldsUnmarshal fills struct pointed by `v` with custom field values.
+// Struct fields are mapped with `redmine:"cf=ID"` tags. Supported field types are
+// strings, integers, floats, bools, `Date`, `CustomFieldValue`, slices of strings
+// and integers and pointers to these types. Pointers stay nil for empty values
+func CustomFieldsUnmarshal(cfs []CustomFieldGetObject, v interface{}) error {
+
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() == true || rv.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("custom fields unmarshal: pointer to struct expected, got %T", v)
+	}
+
+	return customFieldsWalk(rv.Elem(), func(f reflect.Value, t customFieldTag) error {
+
+		c, b := CustomFieldGetByID(cfs, t.id)
+		if b == false || c.Value.IsEmpty() == true {
+			return nil
+		}
+
+		if f.Kind() == reflect.Ptr {
+			e := reflect.New(f.Type().Elem())
+			if err := customFieldValueToField(c.Value, e.Elem()); err != nil {
+				return err
+			}
+			f.Set(e)
+			return nil
+		}
+
+		return customFieldValueToField(c.Value, f)
+	})
+}
+
+// CustomFieldsMarshal creates custom fields update objects from struct `v`
+// with `redmine:"cf=ID"` tags. See `CustomFieldsUnmarshal` for supported types.
+// Nil pointers clear custom fields values. With `omitempty` tag option
+// (e.g. `redmine:"cf=12,omitempty"`) zero values are skipped
+func CustomFieldsMarshal(v interface{}) ([]CustomFieldUpdateObject, error) {
+
+	rv := reflect.ValueOf(v)
+	if rv.Kind() == reflect.Ptr {
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("custom fields marshal: struct expected, got %T", v)
+	}
+
+	cfs := []CustomFieldUpdateObject{}
+
+	err := customFieldsWalk(rv, func(f reflect.Value, t customFieldTag) error {
+
+		if t.omitEmpty == true && f.IsZero() == true {
+			return nil
+		}
+
+		if f.Kind() == reflect.Ptr {
+			if f.IsNil() == true {
+				cfs = CustomFieldsSet(cfs, t.id, customFieldValueEmpty(f.Type().Elem()))
+				return nil
+			}
+			f = f.Elem()
+		}
+
+		c, err := customFieldValueFromField(f)
+		if err != nil {
+			return err
+		}
+
+		cfs = CustomFieldsSet(cfs, t.id, c)
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return cfs, nil
+}
+
+type customFieldTag struct {
+	id        int64
+	omitEmpty bool
+}
+
+func customFieldsWalk(rv reflect.Value, fn func(reflect.Value, customFieldTag) error) error {
+
+	rt := rv.Type()
+
+	for i := 0; i < rt.NumField(); i++ {
+
+		sf := rt.Field(i)
+
+		tag, b := sf.Tag.Lookup("redmine")
+		if b == false || sf.IsExported() == false {
+			continue
+		}
+
+		t, err := customFieldTagParse(tag)
+		if err != nil {
+			return fmt.Errorf("custom fields: field %s: %w", sf.Name, err)
+		}
+
+		if err := fn(rv.Field(i), t); err != nil {
+			return fmt.Errorf("custom fields: field %s: %w", sf.Name, err)
+		}
+	}
+
+	return nil
+}
+
+func customFieldTagParse(tag string) (customFieldTag, error) {
+
+	var t customFieldTag
+
+	for i, o := range strings.Split(tag, ",") {
+
+		if i > 0 {
+			if o != "omitempty" {
+				return t, fmt.Errorf("unknown tag option %q", o)
+			}
+			t.omitEmpty = true
+			continue
+		}
+
+		id, b := strings.CutPrefix(o, "cf=")
+		if b == false {
+			return t, fmt.Errorf("tag %q must be in `cf=ID` format", tag)
+		}
+
+		v, err := strconv.ParseInt(id, 10, 64)
+		if err != nil {
+			return t, fmt.Errorf("tag %q contains invalid custom field ID", tag)
+		}
+
+		t.id = v
+	}
+
+	return t, nil
+}
+
+var (
+	customFieldValueType = reflect.TypeOf(CustomFieldValue{})
+	dateType             = reflect.TypeOf(Date{})
+)
+
+func customFieldValueToField(c CustomFieldValue, f reflect.Value) error {
+
+	switch f.Type() {
+	case customFieldValueType:
+		f.Set(reflect.ValueOf(c))
+		return nil
+	case dateType:
+		d, err := c.Date()
+		if err != nil {
+			return err
+		}
+		f.Set(reflect.ValueOf(d))
+		return nil
+	}
+
+	switch f.Kind() {
+	case reflect.String:
+		f.SetString(c.String())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, err := c.Int()
+		if err != nil {
+			return err
+		}
+		f.SetInt(i)
+	case reflect.Float32, reflect.Float64:
+		v, err := c.Float()
+		if err != nil {
+			return err
+		}
+		f.SetFloat(v)
+	case reflect.Bool:
+		v, err := c.Bool()
+		if err != nil {
+			return err
+		}
+		f.SetBool(v)
+	case reflect.Slice:
+		switch f.Type().Elem().Kind() {
+		case reflect.String:
+			f.Set(reflect.ValueOf(c.List()).Convert(f.Type()))
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			is, err := c.Ints()
+			if err != nil {
+				return err
+			}
+			s := reflect.MakeSlice(f.Type(), len(is), len(is))
+			for i, v := range is {
+				s.Index(i).SetInt(v)
+			}
+			f.Set(s)
+		default:
+			return fmt.Errorf("unsupported type %s", f.Type())
+		}
+	default:
+		return fmt.Errorf("unsupported type %s", f.Type())
+	}
+
+	return nil
+}
+
+func customFieldValueFromField(f reflect.Value) (CustomFieldValue, error) {
+
+	switch f.Type() {
+	case customFieldValueType:
+		return f.Interface().(CustomFieldValue), nil
+	case dateType:
+		return CustomFieldValueDate(f.Interface().(Date)), nil
+	}
+
+	switch f.Kind() {
+	case reflect.String:
+		return CustomFieldValueInit(f.String()), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return CustomFieldValueInt(f.Int()), nil
+	case reflect.Float32, reflect.Float64:
+		return CustomFieldValueFloat(f.Float()), nil
+	case reflect.Bool:
+		return CustomFieldValueBool(f.Bool()), nil
+	case reflect.Slice:
+		if f.Len() == 0 {
+			return customFieldValueEmpty(f.Type()), nil
+		}
+		switch f.Type().Elem().Kind() {
+		case reflect.String:
+			vs := []string{}
+			for i := 0; i < f.Len(); i++ {
+				vs = append(vs, f.Index(i).String())
+			}
+			return CustomFieldValuesInit(vs), nil
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			is := []int64{}
+			for i := 0; i < f.Len(); i++ {
+				is = append(is, f.Index(i).Int())
+			}
+			return CustomFieldValuesInt(is), nil
+		}
+	}
+
+	return CustomFieldValue{}, fmt.Errorf("unsupported type %s", f.Type())
+}
+
+// customFieldValueEmpty returns value to clear custom field of specified type.
+// Redmine ignores empty lists, so list with empty string is used for multiple fields
+func customFieldValueEmpty(t reflect.Type) CustomFieldValue {
+	if t.Kind() == reflect.Slice {
+		return CustomFieldValuesInit([]string{""})
+	}
+	return CustomFieldValueInit("")
+}
@@ -1,8 +1,11 @@
 package redmine
 
 import (
+	"encoding/json"
+	"fmt"
 	"net/http"
 	"net/url"
+	"reflect"
 )
 
 /* Get */
@@ -35,10 +38,17 @@ type CustomFieldPossibleValueObject struct {
 
 // CustomFieldGetObject struct used for custom fields get operations in other methods
 type CustomFieldGetObject struct {
-	ID       int64     `json:"id"`
-	Name     string    `json:"name"`
-	Multiple *bool     `json:"multiple"`
-	Value    *[]string `json:"value"`
+	ID       int64            `json:"id"`
+	Name     string           `json:"name"`
+	Multiple *bool            `json:"multiple"`
+	Value    CustomFieldValue `json:"value"`
+}
+
+// CustomFieldValue contains custom field value. Redmine returns a string
+// for regular custom fields and a strings list for fields with `multiple` option
+type CustomFieldValue struct {
+	Multiple bool
+	Values   []string
 }
 
 /* Update */
@@ -49,6 +59,74 @@ type CustomFieldUpdateObject struct {
 	Value interface{} `json:"value"` // can be a string or strings slice
 }
 
+// CustomFieldValueInit creates single custom field value
+func CustomFieldValueInit(v string) CustomFieldValue {
+	return CustomFieldValue{
+		Values: []string{v},
+	}
+}
+
+// CustomFieldValuesInit creates multiple custom field value
+func CustomFieldValuesInit(vs []string) CustomFieldValue {
+	return CustomFieldValue{
+		Multiple: true,
+		Values:   append([]string{}, vs...),
+	}
+}
+
+// String returns single custom field value. For multiple custom fields
+// the first value is returned. Empty string is returned if value is not set
+func (v CustomFieldValue) String() string {
+	if len(v.Values) == 0 {
+		return ""
+	}
+	return v.Values[0]
+}
+
+// IsEmpty checks custom field value has no non-empty values
+func (v CustomFieldValue) IsEmpty() bool {
+	for _, e := range v.Values {
+		if e != "" {
+			return false
+		}
+	}
+	return true
+}
+
+func (v CustomFieldValue) MarshalJSON() ([]byte, error) {
+
+	if v.Multiple == true {
+		if v.Values == nil {
+			return json.Marshal([]string{})
+		}
+		return json.Marshal(v.Values)
+	}
+
+	if v.Values == nil {
+		return []byte("null"), nil
+	}
+
+	return json.Marshal(v.String())
+}
+
+func (v *CustomFieldValue) UnmarshalJSON(b []byte) error {
+
+	var e interface{}
+
+	if err := json.Unmarshal(b, &e); err != nil {
+		return err
+	}
+
+	c, err := customFieldValueDecode(e)
+	if err != nil {
+		return err
+	}
+
+	*v = c
+
+	return nil
+}
+
 /* Internal types */
 
 type customFieldAllResult struct {
@@ -72,3 +150,62 @@ func (r *Context) CustomFieldAllGet() ([]CustomFieldObject, StatusCode, error) {
 
 	return c.CustomFields, status, err
 }
+
+// customFieldValueHook is a mapstructure decode hook to decode
+// strings and lists into `CustomFieldValue`
+func customFieldValueHook(f reflect.Type, t reflect.Type, data interface{}) (interface{}, error) {
+
+	if t != reflect.TypeOf(CustomFieldValue{}) {
+		return data, nil
+	}
+
+	return customFieldValueDecode(data)
+}
+
+func customFieldValueDecode(data interface{}) (CustomFieldValue, error) {
+
+	switch v := data.(type) {
+	case nil:
+		return CustomFieldValue{}, nil
+	case CustomFieldValue:
+		return v, nil
+	case string:
+		return CustomFieldValueInit(v), nil
+	case []string:
+		return CustomFieldValuesInit(v), nil
+	case []interface{}:
+		c := CustomFieldValue{
+			Multiple: true,
+			Values:   []string{},
+		}
+		for _, e := range v {
+			s, err := customFieldValueScalar(e)
+			if err != nil {
+				return CustomFieldValue{}, err
+			}
+			c.Values = append(c.Values, s)
+		}
+		return c, nil
+	}
+
+	s, err := customFieldValueScalar(data)
+	if err != nil {
+		return CustomFieldValue{}, err
+	}
+
+	return CustomFieldValueInit(s), nil
+}
+
+func customFieldValueScalar(data interface{}) (string, error) {
+
+	switch v := data.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return v, nil
+	case bool, float64, json.Number, int, int64:
+		return fmt.Sprint(v), nil
+	}
+
+	return "", fmt.Errorf("unexpected custom field value type %T", data)
+}
@@ -1,6 +1,7 @@
 package redmine
 
 import (
+	"encoding/json"
 	"testing"
 )
 
@@ -29,3 +30,186 @@ func testCustomFieldAllGet(t *testing.T, r Context) {
 
 	t.Fatal("Custom fields get error: can't find any custom fields")
 }
+
+func TestCustomFieldValue(t *testing.T) {
+
+	var cfs []CustomFieldGetObject
+
+	if err := decodeTest(
+		[]interface{}{
+			map[string]interface{}{"id": 1, "value": "a"},
+			map[string]interface{}{"id": 2, "multiple": true, "value": []interface{}{"b", "c"}},
+			map[string]interface{}{"id": 3, "value": nil},
+		},
+		&cfs,
+	); err != nil {
+		t.Fatal("Custom field value decode error:", err)
+	}
+
+	if cfs[0].Value.Multiple == true || cfs[0].Value.String() != "a" {
+		t.Fatalf("Custom field value decode error: unexpected single value %+v", cfs[0].Value)
+	}
+
+	if cfs[1].Value.Multiple == false || len(cfs[1].Value.Values) != 2 || cfs[1].Value.Values[1] != "c" {
+		t.Fatalf("Custom field value decode error: unexpected multiple value %+v", cfs[1].Value)
+	}
+
+	if cfs[2].Value.IsEmpty() == false {
+		t.Fatalf("Custom field value decode error: unexpected empty value %+v", cfs[2].Value)
+	}
+
+	b, err := json.Marshal(cfs)
+	if err != nil {
+		t.Fatal("Custom field value encode error:", err)
+	}
+
+	var e []CustomFieldGetObject
+	if err := json.Unmarshal(b, &e); err != nil {
+		t.Fatal("Custom field value decode error:", err)
+	}
+
+	if e[0].Value.String() != "a" || e[1].Value.Multiple == false || len(e[1].Value.Values) != 2 || e[2].Value.Values != nil {
+		t.Fatalf("Custom field value decode error: unexpected values %+v", e)
+	}
+}
+
+func TestCustomFieldsCodec(t *testing.T) {
+
+	type fields struct {
+		Billable bool     `redmine:"cf=1"`
+		Hours    *float64 `redmine:"cf=2"`
+		Deadline Date     `redmine:"cf=3,omitempty"`
+		Owner    int64    `redmine:"cf=4"`
+		Versions []int64  `redmine:"cf=5"`
+		Tags     []string `redmine:"cf=6"`
+		Comment  *string  `redmine:"cf=7"`
+		Other    string
+	}
+
+	cfs := []CustomFieldGetObject{
+		{ID: 1, Name: "Billable", Value: CustomFieldValueInit("1")},
+		{ID: 2, Name: "Hours", Value: CustomFieldValueInit("1.5")},
+		{ID: 3, Name: "Deadline", Value: CustomFieldValueInit("2024-05-01")},
+		{ID: 4, Name: "Owner", Value: CustomFieldValueInit("3")},
+		{ID: 5, Name: "Versions", Value: CustomFieldValuesInit([]string{"1", "2"})},
+		{ID: 6, Name: "Tags", Value: CustomFieldValuesInit([]string{"a", ""})},
+		{ID: 7, Name: "Comment", Value: CustomFieldValueInit("")},
+	}
+
+	if c, b := CustomFieldGetByName(cfs, "Owner"); b == false || c.ID != 4 {
+		t.Fatal("Custom field get error: field not found by name")
+	}
+
+	if d, err := cfs[2].Value.Date(); err != nil || d.Equal(DateInit(2024, 5, 1).Time) == false {
+		t.Fatal("Custom field value error: unexpected date:", d, err)
+	}
+
+	if _, err := cfs[5].Value.Ints(); err == nil {
+		t.Fatal("Custom field value error: non-integer values accepted")
+	}
+
+	var f fields
+
+	if err := CustomFieldsUnmarshal(cfs, &f); err != nil {
+		t.Fatal("Custom fields unmarshal error:", err)
+	}
+
+	if f.Billable == false || f.Hours == nil || *f.Hours != 1.5 || f.Owner != 3 || f.Comment != nil {
+		t.Fatalf("Custom fields unmarshal error: unexpected values %+v", f)
+	}
+
+	if f.Deadline.String() != "2024-05-01" || len(f.Versions) != 2 || f.Versions[1] != 2 || len(f.Tags) != 1 {
+		t.Fatalf("Custom fields unmarshal error: unexpected values %+v", f)
+	}
+
+	f.Deadline = Date{}
+	f.Tags = nil
+
+	u, err := CustomFieldsMarshal(f)
+	if err != nil {
+		t.Fatal("Custom fields marshal error:", err)
+	}
+
+	u, err = CustomFieldsSetByName(u, cfs, "Owner", CustomFieldValueInt(5))
+	if err != nil {
+		t.Fatal("Custom fields set error:", err)
+	}
+
+	b, err := json.Marshal(u)
+	if err != nil {
+		t.Fatal("Custom fields encode error:", err)
+	}
+
+	if string(b) != `[{"id":1,"value":"1"},{"id":2,"value":"1.5"},{"id":4,"value":"5"},{"id":5,"value":["1","2"]},{"id":6,"value":[""]},{"id":7,"value":""}]` {
+		t.Fatal("Custom fields marshal error: unexpected result:", string(b))
+	}
+
+	if err := CustomFieldsUnmarshal(cfs, &struct {
+		Owner string `redmine:"owner"`
+	}{}); err == nil {
+		t.Fatal("Custom fields unmarshal error: invalid tag accepted")
+	}
+}
+
+func TestCustomFieldsValidator(t *testing.T) {
+
+	v := CustomFieldsValidatorInit([]CustomFieldObject{
+		{ID: 1, Name: "Code", CustomizedType: "issue", FieldFormat: "string", Regexp: "^[A-Z]+$", MaxLength: 3, IsRequired: true, Trackers: []IDName{{ID: 1}}},
+		{ID: 2, Name: "Size", CustomizedType: "issue", FieldFormat: "list", Multiple: true, Trackers: []IDName{{ID: 1}, {ID: 2}},
+			PossibleValues: &[]CustomFieldPossibleValueObject{{Value: "S"}, {Value: "M"}}},
+		{ID: 3, Name: "Bug only", CustomizedType: "issue", FieldFormat: "int", IsRequired: true, Trackers: []IDName{{ID: 2}}},
+		{ID: 4, Name: "Budget", CustomizedType: "project", FieldFormat: "float", IsRequired: true},
+	})
+
+	if err := v.IssueCreate(IssueCreateObject{
+		TrackerID: Int64Ptr(1),
+		CustomFields: &[]CustomFieldUpdateObject{
+			{ID: 1, Value: "ABC"},
+			{ID: 2, Value: []string{"S", "M"}},
+		},
+	}); err != nil {
+		t.Fatal("Custom fields validate error:", err)
+	}
+
+	err := v.IssueCreate(IssueCreateObject{
+		TrackerID: Int64Ptr(1),
+		CustomFields: &[]CustomFieldUpdateObject{
+			{ID: 2, Value: CustomFieldValuesInit([]string{"S", "XL"})},
+			{ID: 3, Value: "1"},
+			{ID: 5, Value: "1"},
+		},
+	})
+
+	errs, b := err.(CustomFieldErrors)
+	if b == false || len(errs) != 4 {
+		t.Fatal("Custom fields validate error: unexpected errors:", err)
+	}
+
+	for i, e := range []CustomFieldError{
+		{ID: 2, Name: "Size", Message: `value "XL" is not included in the list`},
+		{ID: 3, Name: "Bug only", Message: "is not available"},
+		{ID: 5, Message: "is unknown"},
+		{ID: 1, Name: "Code", Message: "cannot be blank"},
+	} {
+		if errs[i] != e {
+			t.Fatalf("Custom fields validate error: expected %+v, got %+v", e, errs[i])
+		}
+	}
+
+	err = v.IssueUpdate(IssueUpdateObject{
+		CustomFields: &[]CustomFieldUpdateObject{
+			{ID: 1, Value: "abcd"},
+		},
+	})
+	if errs, b := err.(CustomFieldErrors); b == false || len(errs) != 2 {
+		t.Fatal("Custom fields validate error: unexpected errors:", err)
+	}
+
+	if err := v.ProjectCreate(ProjectCreateObject{}); err == nil {
+		t.Fatal("Custom fields validate error: required field not checked")
+	}
+
+	if err := v.ProjectUpdate(ProjectUpdateObject{}); err != nil {
+		t.Fatal("Custom fields validate error:", err)
+	}
+}
@@ -0,0 +1,188 @@
+package redmine
+
+import (
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"time"
+)
+
+const (
+	dateLayout     = "2006-01-02"
+	dateTimeLayout = time.RFC3339
+)
+
+// Date represents Redmine date (e.g. issue start date or time entry spent on date).
+// It is encoded as `YYYY-MM-DD` string, zero date is encoded as `null`
+type Date struct {
+	time.Time
+}
+
+// DateTime represents Redmine timestamp (e.g. issue created on time).
+// It is encoded as RFC3339 string, zero timestamp is encoded as `null`
+type DateTime struct {
+	time.Time
+}
+
+// DateInit creates date for specified year, month and day
+func DateInit(year int, month time.Month, day int) Date {
+	return Date{time.Date(year, month, day, 0, 0, 0, 0, time.UTC)}
+}
+
+// DateFromTime creates date from calendar day of specified time in its location
+func DateFromTime(t time.Time) Date {
+	return DateInit(t.Year(), t.Month(), t.Day())
+}
+
+// DateParse parses date in `YYYY-MM-DD` format
+func DateParse(s string) (Date, error) {
+
+	t, err := time.Parse(dateLayout, s)
+	if err != nil {
+		return Date{}, fmt.Errorf("invalid date %q: expected YYYY-MM-DD", s)
+	}
+
+	return Date{t}, nil
+}
+
+// DateTimeInit creates timestamp from specified time
+func DateTimeInit(t time.Time) DateTime {
+	return DateTime{t}
+}
+
+// DateTimeParse parses timestamp in RFC3339 format
+func DateTimeParse(s string) (DateTime, error) {
+
+	t, err := time.Parse(dateTimeLayout, s)
+	if err != nil {
+		return DateTime{}, fmt.Errorf("invalid timestamp %q: expected RFC3339", s)
+	}
+
+	return DateTime{t}, nil
+}
+
+// String returns date in `YYYY-MM-DD` format or empty string for zero date
+func (d Date) String() string {
+	if d.IsZero() == true {
+		return ""
+	}
+	return d.Format(dateLayout)
+}
+
+func (d Date) MarshalText() ([]byte, error) {
+	return []byte(d.String()), nil
+}
+
+func (d *Date) UnmarshalText(b []byte) error {
+
+	if len(b) == 0 {
+		*d = Date{}
+		return nil
+	}
+
+	v, err := DateParse(string(b))
+	if err != nil {
+		return err
+	}
+
+	*d = v
+
+	return nil
+}
+
+func (d Date) MarshalJSON() ([]byte, error) {
+	if d.IsZero() == true {
+		return []byte("null"), nil
+	}
+	return json.Marshal(d.String())
+}
+
+func (d *Date) UnmarshalJSON(b []byte) error {
+
+	var s *string
+
+	if err := json.Unmarshal(b, &s); err != nil {
+		return err
+	}
+
+	if s == nil {
+		*d = Date{}
+		return nil
+	}
+
+	return d.UnmarshalText([]byte(*s))
+}
+
+// String returns timestamp in RFC3339 format or empty string for zero timestamp
+func (d DateTime) String() string {
+	if d.IsZero() == true {
+		return ""
+	}
+	return d.Format(dateTimeLayout)
+}
+
+func (d DateTime) MarshalText() ([]byte, error) {
+	return []byte(d.String()), nil
+}
+
+func (d *DateTime) UnmarshalText(b []byte) error {
+
+	if len(b) == 0 {
+		*d = DateTime{}
+		return nil
+	}
+
+	v, err := DateTimeParse(string(b))
+	if err != nil {
+		return err
+	}
+
+	*d = v
+
+	return nil
+}
+
+func (d DateTime) MarshalJSON() ([]byte, error) {
+	if d.IsZero() == true {
+		return []byte("null"), nil
+	}
+	return json.Marshal(d.String())
+}
+
+func (d *DateTime) UnmarshalJSON(b []byte) error {
+
+	var s *string
+
+	if err := json.Unmarshal(b, &s); err != nil {
+		return err
+	}
+
+	if s == nil {
+		*d = DateTime{}
+		return nil
+	}
+
+	return d.UnmarshalText([]byte(*s))
+}
+
+// textUnmarshalerHook is a mapstructure decode hook to decode strings
+// into types implementing `encoding.TextUnmarshaler` (e.g. `Date` and `DateTime`)
+func textUnmarshalerHook(f reflect.Type, t reflect.Type, data interface{}) (interface{}, error) {
+
+	if f.Kind() != reflect.String {
+		return data, nil
+	}
+
+	if reflect.PointerTo(t).Implements(reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()) == false {
+		return data, nil
+	}
+
+	v := reflect.New(t)
+
+	if err := v.Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(reflect.ValueOf(data).String())); err != nil {
+		return nil, err
+	}
+
+	return v.Elem().Interface(), nil
+}
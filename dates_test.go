@@ -0,0 +1,79 @@
+package redmine
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/mitchellh/mapstructure"
+)
+
+func TestDates(t *testing.T) {
+
+	var o struct {
+		StartDate *Date     `json:"start_date"`
+		DueDate   *Date     `json:"due_date"`
+		CreatedOn DateTime  `json:"created_on"`
+		ClosedOn  *DateTime `json:"closed_on"`
+	}
+
+	if err := decodeTest(map[string]interface{}{
+		"start_date": "2024-02-29",
+		"due_date":   nil,
+		"created_on": "2024-01-02T03:04:05Z",
+		"closed_on":  nil,
+	}, &o); err != nil {
+		t.Fatal("Decode error:", err)
+	}
+
+	if o.StartDate == nil || o.StartDate.Equal(time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC)) == false {
+		t.Fatal("Decode error: incorrect start date:", o.StartDate)
+	}
+
+	if o.DueDate != nil || o.ClosedOn != nil {
+		t.Fatal("Decode error: null values decoded")
+	}
+
+	if o.CreatedOn.Equal(time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)) == false {
+		t.Fatal("Decode error: incorrect created on:", o.CreatedOn)
+	}
+
+	if err := decodeTest(map[string]interface{}{"start_date": "2024-02-30"}, &o); err == nil {
+		t.Fatal("Decode error: invalid date accepted")
+	}
+
+	b, err := json.Marshal(IssueUpdateObject{
+		StartDate: &Date{},
+		DueDate: func() *Date {
+			d := DateInit(2024, time.March, 1)
+			return &d
+		}(),
+	})
+	if err != nil {
+		t.Fatal("Encode error:", err)
+	}
+
+	if string(b) != `{"start_date":null,"due_date":"2024-03-01"}` {
+		t.Fatal("Encode error: unexpected result:", string(b))
+	}
+
+	if _, err := DateParse("01.03.2024"); err == nil {
+		t.Fatal("Date parse error: invalid date accepted")
+	}
+}
+
+// decodeTest decodes data the same way as API responses are decoded
+func decodeTest(in, out interface{}) error {
+
+	d, err := mapstructure.NewDecoder(&mapstructure.DecoderConfig{
+		WeaklyTypedInput: true,
+		DecodeHook:       decodeHook,
+		Result:           out,
+		TagName:          "json",
+	})
+	if err != nil {
+		return err
+	}
+
+	return d.Decode(in)
+}
@@ -0,0 +1,301 @@
+package redmine
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// FilterOperator defines operator for filters of issues and time entries
+type FilterOperator string
+
+// FilterOperator const
+const (
+	FilterOperatorEqual          FilterOperator = "=" // Only one value for `estimated_hours` and `spent_time`
+	FilterOperatorNotEqual       FilterOperator = "!"
+	FilterOperatorOpen           FilterOperator = "o" // Only for `status_id`
+	FilterOperatorClosed         FilterOperator = "c" // Only for `status_id`
+	FilterOperatorAny            FilterOperator = "*"
+	FilterOperatorNone           FilterOperator = "!*"
+	FilterOperatorGreaterOrEqual FilterOperator = ">="
+	FilterOperatorLessOrEqual    FilterOperator = "<="
+	FilterOperatorBetween        FilterOperator = "><"
+	FilterOperatorContains       FilterOperator = "~"
+	FilterOperatorNotContains    FilterOperator = "!~"
+	FilterOperatorStartsWith     FilterOperator = "^"
+	FilterOperatorEndsWith       FilterOperator = "$"
+
+	// Relative dates operators with days count value
+	FilterOperatorLessThanDaysAgo FilterOperator = ">t-"
+	FilterOperatorMoreThanDaysAgo FilterOperator = "<t-"
+	FilterOperatorInPastDays      FilterOperator = "><t-"
+	FilterOperatorDaysAgo         FilterOperator = "t-"
+	FilterOperatorInMoreThanDays  FilterOperator = ">t+"
+	FilterOperatorInLessThanDays  FilterOperator = "<t+"
+	FilterOperatorInNextDays      FilterOperator = "><t+"
+	FilterOperatorInDays          FilterOperator = "t+"
+
+	// Relative dates operators without value
+	FilterOperatorToday     FilterOperator = "t"
+	FilterOperatorYesterday FilterOperator = "ld"
+	FilterOperatorTomorrow  FilterOperator = "nd"
+	FilterOperatorThisWeek  FilterOperator = "w"
+	FilterOperatorLastWeek  FilterOperator = "lw"
+	FilterOperatorLast2Week FilterOperator = "l2w"
+	FilterOperatorNextWeek  FilterOperator = "nw"
+	FilterOperatorThisMonth FilterOperator = "m"
+	FilterOperatorLastMonth FilterOperator = "lm"
+	FilterOperatorNextMonth FilterOperator = "nm"
+	FilterOperatorThisYear  FilterOperator = "y"
+)
+
+// IssueFilterField defines standard issues filter field
+type IssueFilterField string
+
+// IssueFilterField const
+const (
+	IssueFilterFieldIssueID        IssueFilterField = "issue_id"
+	IssueFilterFieldProjectID      IssueFilterField = "project_id"
+	IssueFilterFieldSubprojectID   IssueFilterField = "subproject_id"
+	IssueFilterFieldTrackerID      IssueFilterField = "tracker_id"
+	IssueFilterFieldStatusID       IssueFilterField = "status_id"
+	IssueFilterFieldPriorityID     IssueFilterField = "priority_id"
+	IssueFilterFieldAuthorID       IssueFilterField = "author_id"
+	IssueFilterFieldAssignedToID   IssueFilterField = "assigned_to_id"
+	IssueFilterFieldCategoryID     IssueFilterField = "category_id"
+	IssueFilterFieldFixedVersionID IssueFilterField = "fixed_version_id"
+	IssueFilterFieldParentID       IssueFilterField = "parent_id"
+	IssueFilterFieldWatcherID      IssueFilterField = "watcher_id"
+	IssueFilterFieldMemberOfGroup  IssueFilterField = "member_of_group"
+	IssueFilterFieldIsPrivate      IssueFilterField = "is_private"
+	IssueFilterFieldSubject        IssueFilterField = "subject"
+	IssueFilterFieldDescription    IssueFilterField = "description"
+	IssueFilterFieldNotes          IssueFilterField = "notes"
+	IssueFilterFieldCreatedOn      IssueFilterField = "created_on"
+	IssueFilterFieldUpdatedOn      IssueFilterField = "updated_on"
+	IssueFilterFieldClosedOn       IssueFilterField = "closed_on"
+	IssueFilterFieldStartDate      IssueFilterField = "start_date"
+	IssueFilterFieldDueDate        IssueFilterField = "due_date"
+	IssueFilterFieldEstimatedHours IssueFilterField = "estimated_hours"
+	IssueFilterFieldSpentTime      IssueFilterField = "spent_time"
+	IssueFilterFieldDoneRatio      IssueFilterField = "done_ratio"
+)
+
+// ProjectFilterField defines standard projects filter field
+// (project queries are available since Redmine 4.1)
+type ProjectFilterField string
+
+// ProjectFilterField const
+const (
+	ProjectFilterFieldID          ProjectFilterField = "id"
+	ProjectFilterFieldName        ProjectFilterField = "name"
+	ProjectFilterFieldIdentifier  ProjectFilterField = "identifier"
+	ProjectFilterFieldDescription ProjectFilterField = "description"
+	ProjectFilterFieldParentID    ProjectFilterField = "parent_id"
+	ProjectFilterFieldIsPublic    ProjectFilterField = "is_public"
+	ProjectFilterFieldCreatedOn   ProjectFilterField = "created_on"
+	ProjectFilterFieldUpdatedOn   ProjectFilterField = "updated_on"
+)
+
+// filterType defines kind of values filter field contains
+type filterType int
+
+const (
+	filterTypeAny filterType = iota // Unknown field, e.g. custom field
+	filterTypeList
+	filterTypeStatus
+	filterTypeText
+	filterTypeDate
+	filterTypeInteger // Several values of `=` operator are comma-separated, e.g. `issue_id=1,2`
+	filterTypeFloat   // Redmine takes only one value for `=` operator
+)
+
+// filter contains field filter expression
+type filter struct {
+	t      filterType
+	op     FilterOperator
+	values []string
+}
+
+var issueFilterTypes = map[IssueFilterField]filterType{
+	IssueFilterFieldIssueID:        filterTypeInteger,
+	IssueFilterFieldProjectID:      filterTypeList,
+	IssueFilterFieldSubprojectID:   filterTypeList,
+	IssueFilterFieldTrackerID:      filterTypeList,
+	IssueFilterFieldStatusID:       filterTypeStatus,
+	IssueFilterFieldPriorityID:     filterTypeList,
+	IssueFilterFieldAuthorID:       filterTypeList,
+	IssueFilterFieldAssignedToID:   filterTypeList,
+	IssueFilterFieldCategoryID:     filterTypeList,
+	IssueFilterFieldFixedVersionID: filterTypeList,
+	IssueFilterFieldParentID:       filterTypeList,
+	IssueFilterFieldWatcherID:      filterTypeList,
+	IssueFilterFieldMemberOfGroup:  filterTypeList,
+	IssueFilterFieldIsPrivate:      filterTypeList,
+	IssueFilterFieldSubject:        filterTypeText,
+	IssueFilterFieldDescription:    filterTypeText,
+	IssueFilterFieldNotes:          filterTypeText,
+	IssueFilterFieldCreatedOn:      filterTypeDate,
+	IssueFilterFieldUpdatedOn:      filterTypeDate,
+	IssueFilterFieldClosedOn:       filterTypeDate,
+	IssueFilterFieldStartDate:      filterTypeDate,
+	IssueFilterFieldDueDate:        filterTypeDate,
+	IssueFilterFieldEstimatedHours: filterTypeFloat,
+	IssueFilterFieldSpentTime:      filterTypeFloat,
+	IssueFilterFieldDoneRatio:      filterTypeInteger,
+}
+
+var projectFilterTypes = map[ProjectFilterField]filterType{
+	ProjectFilterFieldID:          filterTypeList,
+	ProjectFilterFieldName:        filterTypeText,
+	ProjectFilterFieldIdentifier:  filterTypeText,
+	ProjectFilterFieldDescription: filterTypeText,
+	ProjectFilterFieldParentID:    filterTypeList,
+	ProjectFilterFieldIsPublic:    filterTypeList,
+	ProjectFilterFieldCreatedOn:   filterTypeDate,
+	ProjectFilterFieldUpdatedOn:   filterTypeDate,
+}
+
+var filterOperatorsRelative = map[FilterOperator]bool{
+	FilterOperatorLessThanDaysAgo: true,
+	FilterOperatorMoreThanDaysAgo: true,
+	FilterOperatorInPastDays:      true,
+	FilterOperatorDaysAgo:         true,
+	FilterOperatorInMoreThanDays:  true,
+	FilterOperatorInLessThanDays:  true,
+	FilterOperatorInNextDays:      true,
+	FilterOperatorInDays:          true,
+}
+
+var filterOperatorsPeriod = map[FilterOperator]bool{
+	FilterOperatorToday:     true,
+	FilterOperatorYesterday: true,
+	FilterOperatorTomorrow:  true,
+	FilterOperatorThisWeek:  true,
+	FilterOperatorLastWeek:  true,
+	FilterOperatorLast2Week: true,
+	FilterOperatorNextWeek:  true,
+	FilterOperatorThisMonth: true,
+	FilterOperatorLastMonth: true,
+	FilterOperatorNextMonth: true,
+	FilterOperatorThisYear:  true,
+}
+
+func (o FilterOperator) String() string {
+	return string(o)
+}
+
+func (f IssueFilterField) String() string {
+	return string(f)
+}
+
+func (f ProjectFilterField) String() string {
+	return string(f)
+}
+
+// filterInit checks operator is applicable to field type and values count
+// and values format are correct for operator
+func filterInit(t filterType, op FilterOperator, values []string) (filter, error) {
+
+	var allowed bool
+
+	switch op {
+	case FilterOperatorEqual, FilterOperatorNotEqual, FilterOperatorAny, FilterOperatorNone:
+		allowed = true
+	case FilterOperatorOpen, FilterOperatorClosed:
+		allowed = t == filterTypeStatus
+	case FilterOperatorGreaterOrEqual, FilterOperatorLessOrEqual, FilterOperatorBetween:
+		allowed = t == filterTypeDate || t == filterTypeInteger || t == filterTypeFloat || t == filterTypeAny
+	case FilterOperatorContains, FilterOperatorNotContains, FilterOperatorStartsWith, FilterOperatorEndsWith:
+		allowed = t == filterTypeText || t == filterTypeAny
+	default:
+		if filterOperatorsRelative[op] == false && filterOperatorsPeriod[op] == false {
+			return filter{}, fmt.Errorf("unknown operator %q", op)
+		}
+		allowed = t == filterTypeDate || t == filterTypeAny
+	}
+
+	if allowed == false {
+		return filter{}, fmt.Errorf("operator %q is not applicable", op)
+	}
+
+	// Check values count
+	var count bool
+
+	switch {
+	case op == FilterOperatorEqual && t == filterTypeFloat:
+		count = len(values) == 1
+	case op == FilterOperatorEqual || op == FilterOperatorNotEqual:
+		count = len(values) > 0
+	case op == FilterOperatorBetween:
+		count = len(values) == 2
+	case op == FilterOperatorOpen || op == FilterOperatorClosed || op == FilterOperatorAny ||
+		op == FilterOperatorNone || filterOperatorsPeriod[op] == true:
+		count = len(values) == 0
+	default:
+		count = len(values) == 1
+	}
+
+	if count == false {
+		return filter{}, fmt.Errorf("invalid values count %d for operator %q", len(values), op)
+	}
+
+	// Check values format
+	for _, v := range values {
+
+		if filterOperatorsRelative[op] == true {
+			if _, err := strconv.ParseUint(v, 10, 64); err != nil {
+				return filter{}, fmt.Errorf("value %q for operator %q must be a days count", v, op)
+			}
+			continue
+		}
+
+		switch t {
+		case filterTypeDate:
+			if _, err := DateParse(v); err != nil {
+				if _, err := DateTimeParse(v); err != nil {
+					return filter{}, fmt.Errorf("value %q is not a date", v)
+				}
+			}
+		case filterTypeInteger:
+			if _, err := strconv.ParseInt(v, 10, 64); err != nil {
+				return filter{}, fmt.Errorf("value %q is not an integer", v)
+			}
+		case filterTypeFloat:
+			if _, err := strconv.ParseFloat(v, 64); err != nil {
+				return filter{}, fmt.Errorf("value %q is not a number", v)
+			}
+		}
+
+		if strings.Contains(v, "|") == true {
+			return filter{}, fmt.Errorf("value %q must not contain `|`", v)
+		}
+	}
+
+	return filter{
+		t:      t,
+		op:     op,
+		values: append([]string{}, values...),
+	}, nil
+}
+
+// expression encodes filter into Redmine query syntax
+func (f filter) expression() string {
+
+	sep := "|"
+
+	// Redmine takes only the first value of `=` operator for number fields
+	if f.op == FilterOperatorEqual && f.t == filterTypeInteger {
+		sep = ","
+	}
+
+	v := strings.Join(f.values, sep)
+
+	// Equal operator is used by default, so it's omitted unless
+	// the value may be taken as another operator
+	if f.op == FilterOperatorEqual && (v == "" || strings.ContainsAny(v[:1], "=!*<>~^$") == false) {
+		return v
+	}
+
+	return f.op.String() + v
+}
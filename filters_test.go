@@ -0,0 +1,179 @@
+package redmine
+
+import (
+	"net/url"
+	"testing"
+)
+
+func TestIssueFilters(t *testing.T) {
+
+	f := IssueGetRequestFiltersInit().
+		Add(IssueFilterFieldStatusID, FilterOperatorOpen).
+		Add(IssueFilterFieldTrackerID, FilterOperatorNotEqual, "1", "2").
+		Add(IssueFilterFieldCreatedOn, FilterOperatorBetween, "2024-01-01", "2024-01-31").
+		Add(IssueFilterFieldUpdatedOn, FilterOperatorLessThanDaysAgo, "7").
+		Add(IssueFilterFieldDueDate, FilterOperatorThisWeek).
+		Add(IssueFilterFieldSubject, FilterOperatorEqual, "~tilde").
+		Add(IssueFilterFieldDescription, FilterOperatorContains, "text").
+		Add(IssueFilterFieldIssueID, FilterOperatorEqual, "1", "2").
+		Add(IssueFilterFieldDoneRatio, FilterOperatorNotEqual, "0", "100").
+		Add(IssueFilterFieldEstimatedHours, FilterOperatorEqual, "1.5").
+		CustomFieldFilterAdd(5, FilterOperatorNone)
+
+	if err := f.Err(); err != nil {
+		t.Fatal("Issue filters error:", err)
+	}
+
+	v := url.Values{}
+	f.url(&v)
+
+	for k, e := range map[string]string{
+		"status_id":       "o",
+		"tracker_id":      "!1|2",
+		"created_on":      "><2024-01-01|2024-01-31",
+		"updated_on":      ">t-7",
+		"due_date":        "w",
+		"subject":         "=~tilde",
+		"description":     "~text",
+		"issue_id":        "1,2",
+		"done_ratio":      "!0|100",
+		"estimated_hours": "1.5",
+		"cf_5":            "!*",
+	} {
+		if v.Get(k) != e {
+			t.Fatalf("Issue filters error: expected %s=%s, got %s", k, e, v.Get(k))
+		}
+	}
+
+	for _, f := range []*IssueGetRequestFilters{
+		IssueGetRequestFiltersInit().Add(IssueFilterFieldTrackerID, FilterOperatorOpen),
+		IssueGetRequestFiltersInit().Add(IssueFilterFieldSubject, FilterOperatorGreaterOrEqual, "a"),
+		IssueGetRequestFiltersInit().Add(IssueFilterFieldCreatedOn, FilterOperatorBetween, "2024-01-01"),
+		IssueGetRequestFiltersInit().Add(IssueFilterFieldCreatedOn, FilterOperatorGreaterOrEqual, "yesterday"),
+		IssueGetRequestFiltersInit().Add(IssueFilterFieldDueDate, FilterOperatorInPastDays, "-1"),
+		IssueGetRequestFiltersInit().Add(IssueFilterFieldStatusID, FilterOperatorClosed, "5"),
+		IssueGetRequestFiltersInit().Add(IssueFilterFieldStatusID, "?"),
+		IssueGetRequestFiltersInit().Add(IssueFilterFieldIssueID, FilterOperatorEqual, "1.5"),
+		IssueGetRequestFiltersInit().Add(IssueFilterFieldSpentTime, FilterOperatorEqual, "1", "2"),
+	} {
+		if f.Err() == nil {
+			t.Fatal("Issue filters error: invalid filter accepted")
+		}
+	}
+
+	if _, _, err := (&Context{}).IssuesMultiGet(IssueMultiGetRequest{
+		Filters: IssueGetRequestFiltersInit().Add(IssueFilterFieldStatusID, FilterOperatorBetween),
+	}); err == nil {
+		t.Fatal("Issue filters error: request with invalid filter is sent")
+	}
+}
+
+func TestIssueFiltersClone(t *testing.T) {
+
+	f := IssueGetRequestFiltersInit().
+		FieldAdd("project_id", "acme").
+		Add(IssueFilterFieldTrackerID, FilterOperatorEqual, "1")
+
+	c := f.Clone().
+		FieldAdd("project_id", "beta").
+		Add(IssueFilterFieldStatusID, FilterOperatorAny)
+
+	v, cv := url.Values{}, url.Values{}
+	f.url(&v)
+	c.url(&cv)
+
+	if v.Get("project_id") != "acme" || v.Has("status_id") == true {
+		t.Fatal("Issue filters clone error: original filters are changed:", v.Encode())
+	}
+
+	if cv.Get("project_id") != "beta" || cv.Get("tracker_id") != "1" || cv.Get("status_id") != "*" {
+		t.Fatal("Issue filters clone error: unexpected cloned filters:", cv.Encode())
+	}
+}
+
+func TestTimeEntryFilters(t *testing.T) {
+
+	f := TimeEntryGetRequestFiltersInit().
+		IssueIDSet(10, true).
+		UserIDsSet(1, 2).
+		CustomFieldFilterAdd(3, FilterOperatorGreaterOrEqual, "5")
+
+	if err := f.Err(); err != nil {
+		t.Fatal("Time entry filters error:", err)
+	}
+
+	v := url.Values{}
+	f.url(&v)
+
+	if v.Get("issue_id") != "~10" || v.Get("user_id") != "1|2" || v.Get("cf_3") != ">=5" {
+		t.Fatal("Time entry filters error: unexpected query:", v.Encode())
+	}
+
+	if TimeEntryGetRequestFiltersInit().CustomFieldFilterAdd(3, FilterOperatorBetween, "1").Err() == nil {
+		t.Fatal("Time entry filters error: invalid filter accepted")
+	}
+}
+
+func TestProjectFilters(t *testing.T) {
+
+	f := ProjectGetRequestFiltersInit().
+		StatusSet(ProjectStatusActive).
+		ParentIDSet(1, 2).
+		IsPublicSet(false).
+		Add(ProjectFilterFieldName, FilterOperatorContains, "web").
+		Add(ProjectFilterFieldUpdatedOn, FilterOperatorGreaterOrEqual, "2024-01-01").
+		CustomFieldFilterAdd(4, FilterOperatorAny)
+
+	if err := f.Err(); err != nil {
+		t.Fatal("Project filters error:", err)
+	}
+
+	v := url.Values{}
+	f.url(&v)
+
+	for k, e := range map[string]string{
+		"status":     "1",
+		"parent_id":  "1|2",
+		"is_public":  "0",
+		"name":       "~web",
+		"updated_on": ">=2024-01-01",
+		"cf_4":       "*",
+	} {
+		if v.Get(k) != e {
+			t.Fatalf("Project filters error: expected %s=%s, got %s", k, e, v.Get(k))
+		}
+	}
+
+	for _, f := range []*ProjectGetRequestFilters{
+		ProjectGetRequestFiltersInit().ParentIDSet(),
+		ProjectGetRequestFiltersInit().Add(ProjectFilterFieldIdentifier, FilterOperatorLessOrEqual, "a"),
+		ProjectGetRequestFiltersInit().Add(ProjectFilterFieldCreatedOn, FilterOperatorEqual, "today"),
+	} {
+		if f.Err() == nil {
+			t.Fatal("Project filters error: invalid filter accepted")
+		}
+	}
+
+	if _, _, err := (&Context{}).ProjectAllGet(ProjectAllGetRequest{
+		Filters: ProjectGetRequestFiltersInit().ParentIDSet(),
+	}); err == nil {
+		t.Fatal("Project filters error: request with invalid filter is sent")
+	}
+}
+
+func TestUserFilters(t *testing.T) {
+
+	v := url.Values{}
+	UserGetRequestFiltersInit().StatusSet(UserStatusLocked).url(&v)
+
+	if v.Get("status") != "3" {
+		t.Fatal("User filters error: unexpected query:", v.Encode())
+	}
+
+	v = url.Values{}
+	UserGetRequestFiltersInit().StatusSet(UserStatusLocked).StatusAnySet().url(&v)
+
+	if s, b := v["status"]; b == false || len(s) != 1 || s[0] != "" {
+		t.Fatal("User filters error: unexpected query:", v.Encode())
+	}
+}
@@ -2,4 +2,7 @@ module github.com/nixys/nxs-go-redmine/v5
 
 go 1.21
 
-require github.com/mitchellh/mapstructure v1.3.3
+require (
+	github.com/mitchellh/mapstructure v1.3.3
+	gopkg.in/yaml.v3 v3.0.1
+)
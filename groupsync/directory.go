@@ -0,0 +1,318 @@
+package groupsync
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/base64"
+	"encoding/csv"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/nixys/nxs-go-redmine/v5/internal/yamlstrict"
+)
+
+// Directory contains groups exported from external directory
+type Directory struct {
+	Groups []DirectoryGroup `json:"groups" yaml:"groups"`
+}
+
+// DirectoryGroup describes external group
+type DirectoryGroup struct {
+	Name    string   `json:"name" yaml:"name"`
+	Members []string `json:"members" yaml:"members"` // Users logins or emails
+}
+
+// ldifEntry contains LDIF entry attributes with lower-cased names
+type ldifEntry struct {
+	dn    string
+	attrs map[string][]string
+}
+
+var ldifGroupClasses = []string{
+	"groupofnames",
+	"groupofuniquenames",
+	"posixgroup",
+	"group",
+}
+
+// ParseYAML decodes directory written by hand or exported by scripts as YAML
+// or JSON. Unknown fields are errors, members are users logins or emails, e.g.:
+//
+//	groups:
+//	  - name: Developers
+//	    members: [jdoe, asmith@example.net]
+func ParseYAML(data []byte) (Directory, error) {
+
+	var d Directory
+
+	if err := yamlstrict.Decode(data, &d); err != nil {
+		return Directory{}, fmt.Errorf("groupsync: directory decode error: %w", err)
+	}
+
+	for _, g := range d.Groups {
+		if g.Name == "" {
+			return Directory{}, fmt.Errorf("groupsync: directory decode error: group name is empty")
+		}
+	}
+
+	return d, nil
+}
+
+// ParseCSV decodes directory from CSV with `group` and `member` columns
+// in the header. Each row adds one member to the group, rows with empty
+// member describe groups without members
+func ParseCSV(data []byte) (Directory, error) {
+
+	rd := csv.NewReader(bytes.NewReader(data))
+	rd.TrimLeadingSpace = true
+
+	rows, err := rd.ReadAll()
+	if err != nil {
+		return Directory{}, fmt.Errorf("groupsync: directory decode error: %w", err)
+	}
+
+	if len(rows) == 0 {
+		return Directory{}, nil
+	}
+
+	gi, mi := -1, -1
+
+	for i, h := range rows[0] {
+		switch strings.ToLower(strings.TrimSpace(h)) {
+		case "group":
+			gi = i
+		case "member":
+			mi = i
+		}
+	}
+
+	if gi < 0 || mi < 0 {
+		return Directory{}, fmt.Errorf("groupsync: directory decode error: `group` and `member` columns are required")
+	}
+
+	var d Directory
+
+	for n, row := range rows[1:] {
+
+		g := strings.TrimSpace(row[gi])
+		if g == "" {
+			return Directory{}, fmt.Errorf("groupsync: directory decode error: row %d: group name is empty", n+2)
+		}
+
+		d.add(g, strings.TrimSpace(row[mi]))
+	}
+
+	return d, nil
+}
+
+// ParseLDIF decodes directory from LDIF. Entries with `member`, `uniqueMember`
+// or `memberUid` attributes or with group object classes are taken as groups
+// named by `cn`. Members DNs are resolved into `uid` or `mail` of the entries
+// from the same LDIF, otherwise the first RDN value of DN is used
+func ParseLDIF(data []byte) (Directory, error) {
+
+	es, err := ldifParse(data)
+	if err != nil {
+		return Directory{}, fmt.Errorf("groupsync: directory decode error: %w", err)
+	}
+
+	users := make(map[string]string)
+
+	for _, e := range es {
+		for _, a := range []string{"uid", "mail", "samaccountname"} {
+			if v := e.attrs[a]; len(v) > 0 {
+				users[dnNormalize(e.dn)] = v[0]
+				break
+			}
+		}
+	}
+
+	var d Directory
+
+	for _, e := range es {
+
+		if e.isGroup() == false {
+			continue
+		}
+
+		cn := e.attrs["cn"]
+		if len(cn) == 0 {
+			return Directory{}, fmt.Errorf("groupsync: directory decode error: group %q has no cn", e.dn)
+		}
+
+		d.add(cn[0], "")
+
+		for _, m := range e.attrs["memberuid"] {
+			d.add(cn[0], m)
+		}
+
+		for _, a := range []string{"member", "uniquemember"} {
+			for _, dn := range e.attrs[a] {
+
+				// Optional unique identifier of `uniqueMember`, e.g. `uid=jdoe,dc=example#'0101'B`
+				if i := strings.LastIndex(dn, "#"); i > 0 && a == "uniquemember" {
+					dn = dn[:i]
+				}
+
+				if u, b := users[dnNormalize(dn)]; b == true {
+					d.add(cn[0], u)
+				} else if u := dnFirstValue(dn); u != "" {
+					d.add(cn[0], u)
+				}
+			}
+		}
+	}
+
+	return d, nil
+}
+
+// add adds member into group keeping groups order. Empty member only adds the group
+func (d *Directory) add(group, member string) {
+
+	i := slices.IndexFunc(d.Groups, func(g DirectoryGroup) bool {
+		return g.Name == group
+	})
+	if i < 0 {
+		d.Groups = append(d.Groups, DirectoryGroup{Name: group, Members: []string{}})
+		i = len(d.Groups) - 1
+	}
+
+	if member != "" && slices.Contains(d.Groups[i].Members, member) == false {
+		d.Groups[i].Members = append(d.Groups[i].Members, member)
+	}
+}
+
+func (e ldifEntry) isGroup() bool {
+
+	for _, a := range []string{"member", "uniquemember", "memberuid"} {
+		if len(e.attrs[a]) > 0 {
+			return true
+		}
+	}
+
+	for _, c := range e.attrs["objectclass"] {
+		if slices.Contains(ldifGroupClasses, strings.ToLower(c)) == true {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ldifParse parses LDIF content records
+func ldifParse(data []byte) ([]ldifEntry, error) {
+
+	var (
+		es    []ldifEntry
+		lines []string
+	)
+
+	sc := bufio.NewScanner(bytes.NewReader(data))
+	sc.Buffer(make([]byte, 64*1024), 1024*1024)
+
+	// Unfold continuation lines
+	for sc.Scan() {
+
+		l := strings.TrimRight(sc.Text(), "\r")
+
+		if strings.HasPrefix(l, " ") == true && len(lines) > 0 && lines[len(lines)-1] != "" {
+			lines[len(lines)-1] += l[1:]
+			continue
+		}
+
+		lines = append(lines, l)
+	}
+
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+
+	e := ldifEntry{attrs: make(map[string][]string)}
+
+	flush := func() {
+		if e.dn != "" {
+			es = append(es, e)
+		}
+		e = ldifEntry{attrs: make(map[string][]string)}
+	}
+
+	for n, l := range lines {
+
+		if l == "" {
+			flush()
+			continue
+		}
+
+		if strings.HasPrefix(l, "#") == true {
+			continue
+		}
+
+		i := strings.Index(l, ":")
+		if i <= 0 {
+			return nil, fmt.Errorf("line %d: invalid attribute", n+1)
+		}
+
+		name := strings.ToLower(l[:i])
+		value := l[i+1:]
+
+		// Attribute options, e.g. `cn;lang-en`
+		if j := strings.Index(name, ";"); j > 0 {
+			name = name[:j]
+		}
+
+		switch {
+		case strings.HasPrefix(value, ":"):
+			b, err := base64.StdEncoding.DecodeString(strings.TrimSpace(value[1:]))
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", n+1, err)
+			}
+			value = string(b)
+		case strings.HasPrefix(value, "<"):
+			return nil, fmt.Errorf("line %d: URL values are not supported", n+1)
+		default:
+			value = strings.TrimLeft(value, " ")
+		}
+
+		switch name {
+		case "version":
+			if e.dn == "" {
+				continue
+			}
+		case "dn":
+			e.dn = value
+			continue
+		}
+
+		e.attrs[name] = append(e.attrs[name], value)
+	}
+
+	flush()
+
+	return es, nil
+}
+
+// dnNormalize normalizes DN for comparison
+func dnNormalize(dn string) string {
+
+	var rdns []string
+
+	for _, r := range strings.Split(dn, ",") {
+		rdns = append(rdns, strings.ToLower(strings.TrimSpace(r)))
+	}
+
+	return strings.Join(rdns, ",")
+}
+
+// dnFirstValue returns value of the first RDN, e.g. `jdoe` for `uid=jdoe,ou=people,dc=example,dc=net`
+func dnFirstValue(dn string) string {
+
+	r, _, _ := strings.Cut(dn, ",")
+
+	_, v, b := strings.Cut(r, "=")
+	if b == false {
+		return ""
+	}
+
+	return strings.TrimSpace(v)
+}
@@ -0,0 +1,294 @@
+package groupsync
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	redmine "github.com/nixys/nxs-go-redmine/v5"
+)
+
+// Settings contains group sync settings
+type Settings struct {
+	DryRun         bool              // Only collect the result, no changes are made
+	Mapping        map[string]string // Redmine groups names by external groups names. Unmapped groups are synced into groups with the same names
+	OnlyMapped     bool              // Skip external groups absent in mapping
+	KeepExtraUsers bool              // Do not remove users absent in external groups from Redmine groups
+}
+
+// Syncer syncs Redmine groups with external directory
+type Syncer struct {
+	r *redmine.Context
+	s Settings
+}
+
+// Result contains changes made (or to be made in dry run mode) by group sync
+type Result struct {
+	DryRun     bool
+	Groups     []GroupResult
+	Unresolved []UnresolvedMember
+}
+
+// GroupResult describes synced Redmine group
+type GroupResult struct {
+	ID      int64 // Zero for groups to be created in dry run mode
+	Name    string
+	Created bool
+	Added   []string // Logins of added users
+	Removed []string // Logins of removed users
+}
+
+// UnresolvedMember describes external group member not found in Redmine
+type UnresolvedMember struct {
+	Group  string // External group name
+	Member string
+}
+
+// syncGroup contains desired Redmine group members
+type syncGroup struct {
+	name    string
+	userIDs []int64
+}
+
+// Init creates new syncer
+func Init(r *redmine.Context, s Settings) *Syncer {
+	return &Syncer{
+		r: r,
+		s: s,
+	}
+}
+
+// Sync creates missing Redmine groups and adds and removes groups users to
+// match the directory. Members are resolved into users of any status by
+// login or email (case insensitive). Unresolved members are skipped and
+// listed in the result. External groups mapped into the same Redmine group
+// are merged. Redmine groups absent in the directory are not changed
+func (sy *Syncer) Sync(d Directory) (Result, error) {
+
+	res := Result{
+		DryRun: sy.s.DryRun,
+	}
+
+	us, _, err := sy.r.UserAllGet(redmine.UserAllGetRequest{
+		Filters: redmine.UserGetRequestFiltersInit().StatusAnySet(),
+	})
+	if err != nil {
+		return res, fmt.Errorf("groupsync: users get error: %w", err)
+	}
+
+	users := make(map[string]int64)
+	logins := make(map[int64]string)
+
+	for _, u := range us.Users {
+		if u.Mail != "" {
+			users[strings.ToLower(u.Mail)] = u.ID
+		}
+		logins[u.ID] = u.Login
+	}
+
+	// Logins take precedence over emails
+	for _, u := range us.Users {
+		users[strings.ToLower(u.Login)] = u.ID
+	}
+
+	var sgs []syncGroup
+
+	for _, g := range d.Groups {
+
+		name, b := sy.s.Mapping[g.Name]
+		if b == false {
+			if sy.s.OnlyMapped == true {
+				continue
+			}
+			name = g.Name
+		}
+
+		i := slices.IndexFunc(sgs, func(sg syncGroup) bool {
+			return strings.EqualFold(sg.name, name)
+		})
+		if i < 0 {
+			sgs = append(sgs, syncGroup{name: name, userIDs: []int64{}})
+			i = len(sgs) - 1
+		}
+
+		for _, m := range g.Members {
+
+			id, b := users[strings.ToLower(m)]
+			if b == false {
+				res.Unresolved = append(res.Unresolved, UnresolvedMember{Group: g.Name, Member: m})
+				continue
+			}
+
+			if slices.Contains(sgs[i].userIDs, id) == false {
+				sgs[i].userIDs = append(sgs[i].userIDs, id)
+			}
+		}
+	}
+
+	gs, _, err := sy.r.GroupAllGet()
+	if err != nil {
+		return res, fmt.Errorf("groupsync: groups get error: %w", err)
+	}
+
+	for _, sg := range sgs {
+
+		i := slices.IndexFunc(gs.Groups, func(g redmine.GroupObject) bool {
+			return strings.EqualFold(g.Name, sg.name)
+		})
+
+		var (
+			gr  GroupResult
+			err error
+		)
+
+		if i < 0 {
+			gr, err = sy.groupCreate(sg, logins)
+		} else {
+			gr, err = sy.groupUpdate(gs.Groups[i], sg, logins)
+		}
+		if err != nil {
+			return res, fmt.Errorf("groupsync: group %q: %w", sg.name, err)
+		}
+
+		res.Groups = append(res.Groups, gr)
+	}
+
+	return res, nil
+}
+
+func (sy *Syncer) groupCreate(sg syncGroup, logins map[int64]string) (GroupResult, error) {
+
+	gr := GroupResult{
+		Name:    sg.name,
+		Created: true,
+	}
+
+	for _, id := range sg.userIDs {
+		gr.Added = append(gr.Added, logins[id])
+	}
+
+	if sy.s.DryRun == true {
+		return gr, nil
+	}
+
+	g, _, err := sy.r.GroupCreate(redmine.GroupCreate{
+		Group: redmine.GroupCreateObject{
+			Name:    sg.name,
+			UserIDs: &sg.userIDs,
+		},
+	})
+	if err != nil {
+		return gr, err
+	}
+
+	gr.ID = g.ID
+
+	return gr, nil
+}
+
+func (sy *Syncer) groupUpdate(g redmine.GroupObject, sg syncGroup, logins map[int64]string) (GroupResult, error) {
+
+	gr := GroupResult{
+		ID:   g.ID,
+		Name: g.Name,
+	}
+
+	g, _, err := sy.r.GroupSingleGet(g.ID, redmine.GroupSingleGetRequest{
+		Includes: []redmine.GroupInclude{redmine.GroupIncludeUsers},
+	})
+	if err != nil {
+		return gr, err
+	}
+
+	current := []int64{}
+	if g.Users != nil {
+		for _, u := range *g.Users {
+			current = append(current, u.ID)
+		}
+	}
+
+	for _, id := range sg.userIDs {
+
+		if slices.Contains(current, id) == true {
+			continue
+		}
+
+		if sy.s.DryRun == false {
+			if _, err := sy.r.GroupAddUser(g.ID, redmine.GroupAddUserObject{UserID: id}); err != nil {
+				return gr, fmt.Errorf("user %s add: %w", logins[id], err)
+			}
+		}
+
+		gr.Added = append(gr.Added, logins[id])
+	}
+
+	if sy.s.KeepExtraUsers == true {
+		return gr, nil
+	}
+
+	for _, id := range current {
+
+		if slices.Contains(sg.userIDs, id) == true {
+			continue
+		}
+
+		if sy.s.DryRun == false {
+			if _, err := sy.r.GroupDeleteUser(g.ID, id); err != nil {
+				return gr, fmt.Errorf("user %s delete: %w", logins[id], err)
+			}
+		}
+
+		gr.Removed = append(gr.Removed, logins[id])
+	}
+
+	return gr, nil
+}
+
+// Write writes human-readable result: one line per change prefixed with
+// `+` (group created or user added), `-` (user removed) or `?` (member is
+// not resolved), followed by changes summary
+func (res Result) Write(w io.Writer) error {
+
+	var created, added, removed int
+
+	for _, g := range res.Groups {
+
+		if g.Created == true {
+			created++
+			if _, err := fmt.Fprintf(w, "+ group %q\n", g.Name); err != nil {
+				return err
+			}
+		}
+
+		for _, l := range g.Added {
+			if _, err := fmt.Fprintf(w, "+ user %q\n", g.Name+"/"+l); err != nil {
+				return err
+			}
+		}
+
+		for _, l := range g.Removed {
+			if _, err := fmt.Fprintf(w, "- user %q\n", g.Name+"/"+l); err != nil {
+				return err
+			}
+		}
+
+		added += len(g.Added)
+		removed += len(g.Removed)
+	}
+
+	for _, u := range res.Unresolved {
+		if _, err := fmt.Fprintf(w, "? member %q\n", u.Group+"/"+u.Member); err != nil {
+			return err
+		}
+	}
+
+	format := "\nGroups created: %d, users added: %d, users removed: %d, members unresolved: %d\n"
+	if res.DryRun == true {
+		format = "\nGroups to create: %d, users to add: %d, users to remove: %d, members unresolved: %d\n"
+	}
+
+	_, err := fmt.Fprintf(w, format, created, added, removed, len(res.Unresolved))
+
+	return err
+}
@@ -0,0 +1,202 @@
+package groupsync
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	redmine "github.com/nixys/nxs-go-redmine/v5"
+	"github.com/nixys/nxs-go-redmine/v5/redminetest"
+)
+
+const (
+	testYAML = `
+groups:
+  - name: Developers
+    members: [jdoe, asmith@example.net, unknown]
+  - name: Ops
+    members: [bjones]
+`
+
+	testCSV = `member,group
+jdoe,Developers
+asmith@example.net,Developers
+unknown,Developers
+bjones,Ops
+`
+
+	testLDIF = `version: 1
+
+# People
+dn: uid=jdoe,ou=people,dc=example,dc=net
+objectClass: inetOrgPerson
+uid: jdoe
+mail: jdoe@example.net
+
+dn: cn=Alice Smith,ou=people,dc=example,dc=net
+objectClass: inetOrgPerson
+cn: Alice Smith
+mail: asmith@example.net
+
+# Groups
+dn: cn=Developers,ou=groups,dc=example,dc=net
+objectClass: groupOfNames
+cn: Developers
+member: uid=jdoe,ou=people,dc=example,dc=net
+member: cn=Alice Smith, ou=people, dc=example, dc=net
+member: uid=unknown,ou=people,dc=example,dc=net
+
+dn:: Y249T3BzLG91PWdyb3VwcyxkYz1leGFtcGxlLGRjPW5ldA==
+objectClass: posixGroup
+cn: Ops
+memberUid: bj
+ ones
+`
+)
+
+func TestParse(t *testing.T) {
+
+	y, err := ParseYAML([]byte(testYAML))
+	if err != nil {
+		t.Fatal("Parse YAML error:", err)
+	}
+
+	c, err := ParseCSV([]byte(testCSV))
+	if err != nil {
+		t.Fatal("Parse CSV error:", err)
+	}
+
+	l, err := ParseLDIF([]byte(testLDIF))
+	if err != nil {
+		t.Fatal("Parse LDIF error:", err)
+	}
+
+	if reflect.DeepEqual(y, c) == false || reflect.DeepEqual(y, l) == false {
+		t.Fatalf("Parse error: directories differ:\n%+v\n%+v\n%+v", y, c, l)
+	}
+
+	if _, err := ParseCSV([]byte("name,login\nDevelopers,jdoe\n")); err == nil {
+		t.Fatal("Parse CSV error: CSV without required columns is accepted")
+	}
+
+	if _, err := ParseYAML([]byte(`groups: [{name: Developers, users: [jdoe]}]`)); err == nil {
+		t.Fatal("Parse YAML error: unknown field is accepted")
+	}
+
+	if _, err := ParseLDIF([]byte("dn: cn=Developers,dc=example\nmember\n")); err == nil {
+		t.Fatal("Parse LDIF error: invalid attribute is accepted")
+	}
+}
+
+func TestSync(t *testing.T) {
+
+	s := redminetest.Init(redminetest.Settings{})
+	defer s.Close()
+
+	r := s.Context()
+
+	jid, _ := s.UserAdd("jdoe", "John", "Doe", "jdoe@example.net")
+	aid, _ := s.UserAdd("asmith", "Alice", "Smith", "asmith@example.net")
+	bid, _ := s.UserAdd("bjones", "Bob", "Jones", "bjones@example.net")
+
+	// Locked users are resolved as well
+	if _, err := r.UserLock(bid); err != nil {
+		t.Fatal("User lock error:", err)
+	}
+
+	g, _, err := r.GroupCreate(redmine.GroupCreate{Group: redmine.GroupCreateObject{Name: "developers", UserIDs: &[]int64{aid, bid}}})
+	if err != nil {
+		t.Fatal("Group create error:", err)
+	}
+
+	d, err := ParseYAML([]byte(testYAML))
+	if err != nil {
+		t.Fatal("Parse YAML error:", err)
+	}
+
+	st := Settings{
+		DryRun:  true,
+		Mapping: map[string]string{"Ops": "Operations"},
+	}
+
+	groupUsers := func(id int64) []int64 {
+		o, _, err := r.GroupSingleGet(id, redmine.GroupSingleGetRequest{Includes: []redmine.GroupInclude{redmine.GroupIncludeUsers}})
+		if err != nil {
+			t.Fatal("Group get error:", err)
+		}
+		var ids []int64
+		for _, u := range *o.Users {
+			ids = append(ids, u.ID)
+		}
+		return ids
+	}
+
+	// Dry run
+	res, err := Init(r, st).Sync(d)
+	if err != nil {
+		t.Fatal("Sync error:", err)
+	}
+
+	exp := []GroupResult{
+		{ID: g.ID, Name: "developers", Added: []string{"jdoe"}, Removed: []string{"bjones"}},
+		{Name: "Operations", Created: true, Added: []string{"bjones"}},
+	}
+
+	if reflect.DeepEqual(res.Groups, exp) == false || len(res.Unresolved) != 1 || res.Unresolved[0].Member != "unknown" {
+		t.Fatalf("Sync error: unexpected result: %+v", res)
+	}
+
+	if len(groupUsers(g.ID)) != 2 {
+		t.Fatal("Sync error: group is changed on dry run")
+	}
+
+	// Sync
+	st.DryRun = false
+
+	res, err = Init(r, st).Sync(d)
+	if err != nil {
+		t.Fatal("Sync error:", err)
+	}
+
+	var b strings.Builder
+
+	if err := res.Write(&b); err != nil {
+		t.Fatal("Result write error:", err)
+	}
+
+	for _, e := range []string{
+		`+ user "developers/jdoe"`,
+		`- user "developers/bjones"`,
+		"+ group \"Operations\"\n+ user \"Operations/bjones\"\n",
+		`? member "Developers/unknown"`,
+		"Groups created: 1, users added: 2, users removed: 1, members unresolved: 1",
+	} {
+		if strings.Contains(b.String(), e) == false {
+			t.Fatalf("Result write error: %q is not in result:\n%s", e, b.String())
+		}
+	}
+
+	if ids := groupUsers(g.ID); reflect.DeepEqual(ids, []int64{jid, aid}) == false && reflect.DeepEqual(ids, []int64{aid, jid}) == false {
+		t.Fatal("Sync error: unexpected group users:", ids)
+	}
+
+	if ids := groupUsers(res.Groups[1].ID); len(ids) != 1 || ids[0] != bid {
+		t.Fatal("Sync error: unexpected created group users:", ids)
+	}
+
+	// Nothing to change, extra users are kept
+	if _, err := r.GroupAddUser(g.ID, redmine.GroupAddUserObject{UserID: bid}); err != nil {
+		t.Fatal("Group add user error:", err)
+	}
+
+	res, err = Init(r, Settings{Mapping: st.Mapping, KeepExtraUsers: true}).Sync(d)
+	if err != nil {
+		t.Fatal("Sync error:", err)
+	}
+
+	for _, gr := range res.Groups {
+		if gr.Created == true || len(gr.Added) > 0 || len(gr.Removed) > 0 {
+			t.Fatalf("Sync error: unexpected changes: %+v", gr)
+		}
+	}
+}
@@ -0,0 +1,18 @@
+// Package yamlstrict decodes YAML and JSON documents rejecting unknown fields.
+// It is shared by packages reading declarative specs, so typos in field names
+// are reported instead of being silently ignored
+package yamlstrict
+
+import (
+	"bytes"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Decode decodes YAML or JSON document (JSON is a subset of YAML) into v.
+// Unknown fields and empty documents are errors
+func Decode(data []byte, v any) error {
+	d := yaml.NewDecoder(bytes.NewReader(data))
+	d.KnownFields(true)
+	return d.Decode(v)
+}
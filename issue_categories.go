@@ -0,0 +1,163 @@
+package redmine
+
+import (
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+/* Get */
+
+// IssueCategoryObject struct used for issue categories get operations
+type IssueCategoryObject struct {
+	ID         int64   `json:"id"`
+	Project    IDName  `json:"project"`
+	Name       string  `json:"name"`
+	AssignedTo *IDName `json:"assigned_to"`
+}
+
+/* Create */
+
+// IssueCategoryCreate struct used for issue categories create operations
+type IssueCategoryCreate struct {
+	IssueCategory IssueCategoryCreateObject `json:"issue_category"`
+}
+
+type IssueCategoryCreateObject struct {
+	Name         string `json:"name"`
+	AssignedToID *int64 `json:"assigned_to_id,omitempty"`
+}
+
+/* Update */
+
+// IssueCategoryUpdate struct used for issue categories update operations
+type IssueCategoryUpdate struct {
+	IssueCategory IssueCategoryUpdateObject `json:"issue_category"`
+}
+
+type IssueCategoryUpdateObject struct {
+	Name         *string `json:"name,omitempty"`
+	AssignedToID *int64  `json:"assigned_to_id,omitempty"`
+}
+
+/* Requests */
+
+// IssueCategoryDeleteRequest contains data for making request to delete issue category
+type IssueCategoryDeleteRequest struct {
+	ReassignToID *int64 // Category to reassign issues of deleted category to
+}
+
+/* Results */
+
+// IssueCategoryResult stores issue categories requests processing result
+type IssueCategoryResult struct {
+	IssueCategories []IssueCategoryObject `json:"issue_categories"`
+	TotalCount      int64                 `json:"total_count"`
+}
+
+/* Internal types */
+
+type issueCategorySingleResult struct {
+	IssueCategory IssueCategoryObject `json:"issue_category"`
+}
+
+// IssueCategoryAllGet gets info for all issue categories for project with specified ID
+//
+// see: https://www.redmine.org/projects/redmine/wiki/Rest_IssueCategories#GET
+func (r *Context) IssueCategoryAllGet(projectID string) (IssueCategoryResult, StatusCode, error) {
+
+	var c IssueCategoryResult
+
+	status, err := r.Get(
+		&c,
+		url.URL{
+			Path: "/projects/" + projectID + "/issue_categories.json",
+		},
+		http.StatusOK,
+	)
+
+	return c, status, err
+}
+
+// IssueCategorySingleGet gets single issue category info with specified ID
+//
+// see: https://www.redmine.org/projects/redmine/wiki/Rest_IssueCategories#GET-2
+func (r *Context) IssueCategorySingleGet(id int64) (IssueCategoryObject, StatusCode, error) {
+
+	var c issueCategorySingleResult
+
+	status, err := r.Get(
+		&c,
+		url.URL{
+			Path: "/issue_categories/" + strconv.FormatInt(id, 10) + ".json",
+		},
+		http.StatusOK,
+	)
+
+	return c.IssueCategory, status, err
+}
+
+// IssueCategoryCreate creates new issue category for project with specified ID
+//
+// see: https://www.redmine.org/projects/redmine/wiki/Rest_IssueCategories#POST
+func (r *Context) IssueCategoryCreate(projectID string, category IssueCategoryCreate) (IssueCategoryObject, StatusCode, error) {
+
+	var c issueCategorySingleResult
+
+	status, err := r.Post(
+		category,
+		&c,
+		url.URL{
+			Path: "/projects/" + projectID + "/issue_categories.json",
+		},
+		http.StatusCreated,
+	)
+
+	return c.IssueCategory, status, err
+}
+
+// IssueCategoryUpdate updates issue category with specified ID
+//
+// see: https://www.redmine.org/projects/redmine/wiki/Rest_IssueCategories#PUT
+func (r *Context) IssueCategoryUpdate(id int64, category IssueCategoryUpdate) (StatusCode, error) {
+
+	status, err := r.Put(
+		category,
+		nil,
+		url.URL{
+			Path: "/issue_categories/" + strconv.FormatInt(id, 10) + ".json",
+		},
+		http.StatusNoContent,
+	)
+
+	return status, err
+}
+
+// IssueCategoryDelete deletes issue category with specified ID
+//
+// see: https://www.redmine.org/projects/redmine/wiki/Rest_IssueCategories#DELETE
+func (r *Context) IssueCategoryDelete(id int64, request IssueCategoryDeleteRequest) (StatusCode, error) {
+
+	status, err := r.Del(
+		nil,
+		nil,
+		url.URL{
+			Path:     "/issue_categories/" + strconv.FormatInt(id, 10) + ".json",
+			RawQuery: request.url().Encode(),
+		},
+		http.StatusNoContent,
+	)
+
+	return status, err
+}
+
+func (cr IssueCategoryDeleteRequest) url() url.Values {
+
+	v := url.Values{}
+
+	if cr.ReassignToID != nil {
+		v.Set("reassign_to_id", strconv.FormatInt(*cr.ReassignToID, 10))
+	}
+
+	return v
+}
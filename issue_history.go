@@ -0,0 +1,302 @@
+package redmine
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// IssueHistory contains issue state and its changes timeline built from issue journals
+type IssueHistory struct {
+	current IssueState
+	changes []IssueFieldChange
+}
+
+// IssueState contains issue attributes and custom fields values as of specific moment.
+// Values are represented in the same format journals use (e.g. IDs for `status_id`,
+// `YYYY-MM-DD` for dates, `0` or `1` for `is_private`). Empty string means value is not set
+type IssueState struct {
+	Attributes   map[string]string  // Key is an attribute name (e.g. `status_id`, `subject`)
+	CustomFields map[int64][]string // Key is a custom field ID
+}
+
+// IssueFieldChange describes single issue field change
+type IssueFieldChange struct {
+	Field     string // Attribute name (e.g. `status_id`) or custom field key in format `cf_<ID>`
+	OldValue  string
+	NewValue  string
+	JournalID int64
+	User      IDName
+	CreatedOn time.Time
+	multiple  bool
+}
+
+// IssueHistoryGet gets issue with specified ID including its journals and builds its history
+func (r *Context) IssueHistoryGet(id int64) (IssueHistory, StatusCode, error) {
+
+	i, status, err := r.IssueSingleGet(
+		id,
+		IssueSingleGetRequest{
+			Includes: []IssueInclude{
+				IssueIncludeJournals,
+			},
+		},
+	)
+	if err != nil {
+		return IssueHistory{}, status, err
+	}
+
+	h, err := IssueHistoryBuild(i)
+
+	return h, status, err
+}
+
+// IssueHistoryBuild builds history for specified issue.
+// Issue must be got with `IssueIncludeJournals` include
+func IssueHistoryBuild(issue IssueObject) (IssueHistory, error) {
+
+	h := IssueHistory{
+		current: issueCurrentState(issue),
+	}
+
+	if issue.Journals == nil {
+		return h, fmt.Errorf("issue history build error: issue journals are not included")
+	}
+
+	multiple := make(map[int64]bool)
+	for _, c := range issue.CustomFields {
+		if c.Multiple != nil && *c.Multiple == true {
+			multiple[c.ID] = true
+		}
+	}
+
+	for _, j := range *issue.Journals {
+
+		for _, d := range j.Details {
+
+			c := IssueFieldChange{
+				OldValue:  d.OldValue,
+				NewValue:  d.NewValue,
+				JournalID: j.ID,
+				User:      j.User,
+				CreatedOn: j.CreatedOn.Time,
+			}
+
+			switch JournalDetailProperty(d.Property) {
+			case JournalDetailPropertyAttr:
+				c.Field = d.Name
+			case JournalDetailPropertyCustomField:
+				id, err := strconv.ParseInt(d.Name, 10, 64)
+				if err != nil {
+					return h, fmt.Errorf("issue history build error: journal %d: incorrect custom field id `%s`", j.ID, d.Name)
+				}
+				c.Field = issueHistoryCustomFieldKey(id)
+				c.multiple = multiple[id]
+			default:
+				continue
+			}
+
+			h.changes = append(h.changes, c)
+		}
+	}
+
+	sort.SliceStable(h.changes, func(i, j int) bool {
+		if h.changes[i].CreatedOn.Equal(h.changes[j].CreatedOn) {
+			return h.changes[i].JournalID < h.changes[j].JournalID
+		}
+		return h.changes[i].CreatedOn.Before(h.changes[j].CreatedOn)
+	})
+
+	return h, nil
+}
+
+// Current returns current issue state
+func (h IssueHistory) Current() IssueState {
+	return h.current.copy()
+}
+
+// StateAt returns issue state as of specified moment. For moments
+// before issue creation the state issue has been created with is returned
+func (h IssueHistory) StateAt(t time.Time) IssueState {
+
+	s := h.current.copy()
+
+	for i := len(h.changes) - 1; i >= 0; i-- {
+
+		c := h.changes[i]
+
+		if c.CreatedOn.After(t) == false {
+			break
+		}
+
+		id, b := issueHistoryCustomFieldID(c.Field)
+		if b == false {
+			s.Attributes[c.Field] = c.OldValue
+			continue
+		}
+
+		if c.multiple == false {
+			if c.OldValue == "" {
+				delete(s.CustomFields, id)
+			} else {
+				s.CustomFields[id] = []string{c.OldValue}
+			}
+			continue
+		}
+
+		// Multiple custom fields have separate detail for every added or removed value
+		if c.NewValue != "" {
+			s.CustomFields[id] = issueHistoryValueRemove(s.CustomFields[id], c.NewValue)
+		}
+		if c.OldValue != "" {
+			s.CustomFields[id] = append(s.CustomFields[id], c.OldValue)
+		}
+	}
+
+	return s
+}
+
+// Changes returns all issue fields changes ordered by time
+func (h IssueHistory) Changes() []IssueFieldChange {
+	return append([]IssueFieldChange{}, h.changes...)
+}
+
+// Timeline returns issue fields changes grouped by field and ordered by time.
+// Map key is an attribute name or custom field key in format `cf_<ID>`
+func (h IssueHistory) Timeline() map[string][]IssueFieldChange {
+
+	t := make(map[string][]IssueFieldChange)
+
+	for _, c := range h.changes {
+		t[c.Field] = append(t[c.Field], c)
+	}
+
+	return t
+}
+
+// FieldTimeline returns changes of specified field ordered by time
+func (h IssueHistory) FieldTimeline(field string) []IssueFieldChange {
+
+	var t []IssueFieldChange
+
+	for _, c := range h.changes {
+		if c.Field == field {
+			t = append(t, c)
+		}
+	}
+
+	return t
+}
+
+func (s IssueState) copy() IssueState {
+
+	c := IssueState{
+		Attributes:   make(map[string]string),
+		CustomFields: make(map[int64][]string),
+	}
+
+	for k, v := range s.Attributes {
+		c.Attributes[k] = v
+	}
+
+	for k, v := range s.CustomFields {
+		c.CustomFields[k] = append([]string{}, v...)
+	}
+
+	return c
+}
+
+func issueCurrentState(i IssueObject) IssueState {
+
+	s := IssueState{
+		Attributes: map[string]string{
+			"project_id":       strconv.FormatInt(i.Project.ID, 10),
+			"tracker_id":       strconv.FormatInt(i.Tracker.ID, 10),
+			"status_id":        strconv.FormatInt(i.Status.ID, 10),
+			"priority_id":      strconv.FormatInt(i.Priority.ID, 10),
+			"assigned_to_id":   issueHistoryIDName(i.AssignedTo),
+			"category_id":      issueHistoryIDName(i.Category),
+			"fixed_version_id": issueHistoryIDName(i.FixedVersion),
+			"parent_id":        "",
+			"subject":          i.Subject,
+			"description":      i.Description,
+			"start_date":       "",
+			"due_date":         "",
+			"done_ratio":       strconv.FormatInt(i.DoneRatio, 10),
+			"is_private":       issueHistoryBool(i.IsPrivate),
+			"estimated_hours":  "",
+		},
+		CustomFields: make(map[int64][]string),
+	}
+
+	if i.Parent != nil {
+		s.Attributes["parent_id"] = strconv.FormatInt(i.Parent.ID, 10)
+	}
+
+	if i.StartDate != nil {
+		s.Attributes["start_date"] = i.StartDate.String()
+	}
+
+	if i.DueDate != nil {
+		s.Attributes["due_date"] = i.DueDate.String()
+	}
+
+	if i.EstimatedHours != nil {
+		s.Attributes["estimated_hours"] = strconv.FormatFloat(*i.EstimatedHours, 'f', -1, 64)
+	}
+
+	for _, c := range i.CustomFields {
+
+		for _, v := range c.Value.Values {
+			if v != "" {
+				s.CustomFields[c.ID] = append(s.CustomFields[c.ID], v)
+			}
+		}
+	}
+
+	return s
+}
+
+func issueHistoryIDName(v *IDName) string {
+	if v == nil {
+		return ""
+	}
+	return strconv.FormatInt(v.ID, 10)
+}
+
+func issueHistoryBool(b bool) string {
+	if b == true {
+		return "1"
+	}
+	return "0"
+}
+
+func issueHistoryCustomFieldKey(id int64) string {
+	return "cf_" + strconv.FormatInt(id, 10)
+}
+
+func issueHistoryCustomFieldID(field string) (int64, bool) {
+
+	if len(field) < 4 || field[:3] != "cf_" {
+		return 0, false
+	}
+
+	id, err := strconv.ParseInt(field[3:], 10, 64)
+	if err != nil {
+		return 0, false
+	}
+
+	return id, true
+}
+
+func issueHistoryValueRemove(values []string, v string) []string {
+
+	for i, e := range values {
+		if e == v {
+			return append(values[:i:i], values[i+1:]...)
+		}
+	}
+
+	return values
+}
@@ -0,0 +1,73 @@
+package redmine
+
+import (
+	"testing"
+	"time"
+)
+
+func TestIssueHistory(t *testing.T) {
+
+	h, err := IssueHistoryBuild(IssueObject{
+		ID:      1,
+		Status:  IssueStatusObject{ID: 3},
+		Subject: "Subject 3",
+		CustomFields: []CustomFieldGetObject{
+			{ID: 5, Value: CustomFieldValueInit("b")},
+			{ID: 6, Multiple: BoolPtr(true), Value: CustomFieldValuesInit([]string{"x", "z"})},
+		},
+		Journals: &[]IssueJournalObject{
+			{
+				ID:        10,
+				CreatedOn: testDateTime("2024-01-02T00:00:00Z"),
+				Details: []IssueJournalDetailObject{
+					{Property: "attr", Name: "status_id", OldValue: "1", NewValue: "2"},
+					{Property: "attr", Name: "subject", OldValue: "Subject 1", NewValue: "Subject 3"},
+					{Property: "cf", Name: "5", NewValue: "a"},
+					{Property: "cf", Name: "6", NewValue: "z"},
+				},
+			},
+			{
+				ID:        11,
+				CreatedOn: testDateTime("2024-01-03T00:00:00Z"),
+				Details: []IssueJournalDetailObject{
+					{Property: "attr", Name: "status_id", OldValue: "2", NewValue: "3"},
+					{Property: "cf", Name: "5", OldValue: "a", NewValue: "b"},
+					{Property: "cf", Name: "6", OldValue: "y"},
+					{Property: "attachment", Name: "1", NewValue: "file.txt"},
+				},
+			},
+		},
+	})
+	if err != nil {
+		t.Fatal("Issue history build error:", err)
+	}
+
+	s := h.StateAt(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	if s.Attributes["status_id"] != "1" || s.Attributes["subject"] != "Subject 1" || len(s.CustomFields[5]) != 0 {
+		t.Fatal("Issue history error: incorrect initial state")
+	}
+	if len(s.CustomFields[6]) != 2 || s.CustomFields[6][0] != "x" || s.CustomFields[6][1] != "y" {
+		t.Fatal("Issue history error: incorrect initial multiple custom field value")
+	}
+
+	s = h.StateAt(time.Date(2024, 1, 2, 12, 0, 0, 0, time.UTC))
+	if s.Attributes["status_id"] != "2" || s.CustomFields[5][0] != "a" || len(s.CustomFields[6]) != 3 {
+		t.Fatal("Issue history error: incorrect intermediate state")
+	}
+
+	s = h.StateAt(time.Date(2024, 1, 3, 0, 0, 0, 0, time.UTC))
+	if s.Attributes["status_id"] != "3" || s.CustomFields[5][0] != "b" {
+		t.Fatal("Issue history error: incorrect current state")
+	}
+
+	if len(h.Timeline()["status_id"]) != 2 || len(h.FieldTimeline("cf_6")) != 2 || len(h.Changes()) != 7 {
+		t.Fatal("Issue history error: incorrect timeline")
+	}
+
+	t.Logf("Issue history: success")
+}
+
+func testDateTime(s string) DateTime {
+	d, _ := DateTimeParse(s)
+	return d
+}
@@ -1,6 +1,8 @@
 package redmine
 
 import (
+	"encoding/json"
+	"fmt"
 	"net/http"
 	"net/url"
 	"strconv"
@@ -35,18 +37,18 @@ type IssueObject struct {
 	Parent              *IssueParentObject      `json:"parent"`
 	Subject             string                  `json:"subject"`
 	Description         string                  `json:"description"`
-	StartDate           *string                 `json:"start_date"`
-	DueDate             *string                 `json:"due_date"`
+	StartDate           *Date                   `json:"start_date"`
+	DueDate             *Date                   `json:"due_date"`
 	DoneRatio           int64                   `json:"done_ratio"`
-	IsPrivate           int64                   `json:"is_private"`
+	IsPrivate           bool                    `json:"is_private"`
 	EstimatedHours      *float64                `json:"estimated_hours"`
 	TotalEstimatedHours *float64                `json:"total_estimated_hours"`
 	SpentHours          float64                 `json:"spent_hours"`
 	TotalSpentHours     float64                 `json:"total_spent_hours"`
 	CustomFields        []CustomFieldGetObject  `json:"custom_fields"`
-	CreatedOn           string                  `json:"created_on"`
-	UpdatedOn           string                  `json:"updated_on"`
-	ClosedOn            string                  `json:"closed_on"`
+	CreatedOn           DateTime                `json:"created_on"`
+	UpdatedOn           DateTime                `json:"updated_on"`
+	ClosedOn            *DateTime               `json:"closed_on"`
 	Children            *[]IssueChildrenObject  `json:"children"`         // used only: get single user and include specified
 	Attachments         *[]AttachmentObject     `json:"attachments"`      // used only: include specified
 	Relations           *[]IssueRelationObject  `json:"relations"`        // used only: include specified
@@ -71,10 +73,10 @@ type IssueChildrenObject struct {
 
 // IssueChangesetObject struct used for issues get operations
 type IssueChangesetObject struct {
-	Revision    string `json:"revision"`
-	User        IDName `json:"user"`
-	Comments    string `json:"comments"`
-	CommittedOn string `json:"committed_on"`
+	Revision    string   `json:"revision"`
+	User        IDName   `json:"user"`
+	Comments    string   `json:"comments"`
+	CommittedOn DateTime `json:"committed_on"`
 }
 
 // IssueRelationObject struct used for issues get operations
@@ -91,7 +93,7 @@ type IssueJournalObject struct {
 	ID           int64                      `json:"id"`
 	User         IDName                     `json:"user"`
 	Notes        string                     `json:"notes"`
-	CreatedOn    string                     `json:"created_on"`
+	CreatedOn    DateTime                   `json:"created_on"`
 	PrivateNotes bool                       `json:"private_notes"`
 	Details      []IssueJournalDetailObject `json:"details"`
 }
@@ -118,8 +120,8 @@ type IssueCreateObject struct {
 	PriorityID     *int64                     `json:"priority_id,omitempty"`
 	Subject        string                     `json:"subject"`
 	Description    *string                    `json:"description,omitempty"`
-	StartDate      *string                    `json:"start_date,omitempty"`
-	DueDate        *string                    `json:"due_date,omitempty"`
+	StartDate      *Date                      `json:"start_date,omitempty"`
+	DueDate        *Date                      `json:"due_date,omitempty"`
 	CategoryID     *int64                     `json:"category_id,omitempty"`
 	FixedVersionID *int64                     `json:"fixed_version_id,omitempty"`
 	AssignedToID   *int64                     `json:"assigned_to_id,omitempty"`
@@ -145,11 +147,12 @@ type IssueUpdateObject struct {
 	PriorityID     *int64                     `json:"priority_id,omitempty"`
 	Subject        *string                    `json:"subject,omitempty"`
 	Description    *string                    `json:"description,omitempty"`
-	StartDate      *string                    `json:"start_date,omitempty"`
-	DueDate        *string                    `json:"due_date,omitempty"`
+	StartDate      *Date                      `json:"start_date,omitempty"`
+	DueDate        *Date                      `json:"due_date,omitempty"`
 	CategoryID     *int64                     `json:"category_id,omitempty"`
 	FixedVersionID *int64                     `json:"fixed_version_id,omitempty"`
 	AssignedToID   *int64                     `json:"assigned_to_id,omitempty"`
+	Unassign       bool                       `json:"-"` // Clear issue assignee, `AssignedToID` is ignored
 	ParentIssueID  *int64                     `json:"parent_issue_id,omitempty"`
 	CustomFields   *[]CustomFieldUpdateObject `json:"custom_fields,omitempty"`
 	IsPrivate      *bool                      `json:"is_private,omitempty"`
@@ -182,15 +185,17 @@ type IssueSingleGetRequest struct {
 	Includes []IssueInclude
 }
 
+// IssueGetRequestSort contains sort keys for issues get request
 type IssueGetRequestSort struct {
-	field string
-	desc  bool
+	fields sortFields
 }
 
 // IssueGetRequestFilters contains data for making issues get request
 type IssueGetRequestFilters struct {
-	fields map[string][]string
-	cf     map[int64]string
+	fields  map[string][]string
+	cf      map[int64]string
+	filters map[string]filter
+	err     error
 }
 
 /* Results */
@@ -228,6 +233,10 @@ func (r *Context) IssuesAllGet(request IssueAllGetRequest) (IssueResult, StatusC
 		status StatusCode
 	)
 
+	if request.Filters != nil && request.Filters.err != nil {
+		return issues, 0, request.Filters.err
+	}
+
 	up := request.url()
 	up.Set("limit", strconv.FormatInt(limitDefault, 10))
 
@@ -273,6 +282,10 @@ func (r *Context) IssuesMultiGet(request IssueMultiGetRequest) (IssueResult, Sta
 
 	var i IssueResult
 
+	if request.Filters != nil && request.Filters.err != nil {
+		return i, 0, request.Filters.err
+	}
+
 	s, err := r.Get(
 		&i,
 		url.URL{
@@ -480,10 +493,29 @@ func (ir IssueSingleGetRequest) url() url.Values {
 	return v
 }
 
+// MarshalJSON encodes issue update. Redmine rejects zero assignee ID,
+// so empty `assigned_to_id` is sent to clear assignee if `Unassign` is set
+func (o IssueUpdateObject) MarshalJSON() ([]byte, error) {
+
+	type object IssueUpdateObject
+
+	if o.Unassign == false {
+		return json.Marshal(object(o))
+	}
+
+	return json.Marshal(struct {
+		object
+		AssignedToID string `json:"assigned_to_id"`
+	}{
+		object: object(o),
+	})
+}
+
 func IssueGetRequestFiltersInit() *IssueGetRequestFilters {
 	return &IssueGetRequestFilters{
-		fields: make(map[string][]string),
-		cf:     make(map[int64]string),
+		fields:  make(map[string][]string),
+		cf:      make(map[int64]string),
+		filters: make(map[string]filter),
 	}
 }
 
@@ -497,6 +529,71 @@ func (f *IssueGetRequestFilters) CustomFieldAdd(id int64, value string) *IssueGe
 	return f
 }
 
+// Add adds filter for standard field with specified operator, e.g.:
+// `Add(IssueFilterFieldCreatedOn, FilterOperatorBetween, "2024-01-01", "2024-01-31")`.
+// Operator must be applicable to the field and values count and format must
+// match the operator, otherwise issues get request fails with error (see `Err`)
+func (f *IssueGetRequestFilters) Add(field IssueFilterField, op FilterOperator, values ...string) *IssueGetRequestFilters {
+	return f.filterAdd(field.String(), issueFilterTypes[field], op, values)
+}
+
+// CustomFieldFilterAdd adds filter for custom field with specified operator
+func (f *IssueGetRequestFilters) CustomFieldFilterAdd(id int64, op FilterOperator, values ...string) *IssueGetRequestFilters {
+	return f.filterAdd("cf_"+strconv.FormatInt(id, 10), filterTypeAny, op, values)
+}
+
+// Err returns the first error occurred while filters adding
+func (f *IssueGetRequestFilters) Err() error {
+	return f.err
+}
+
+// Clone returns a copy of filters, so filters may be changed without affecting the original
+func (f *IssueGetRequestFilters) Clone() *IssueGetRequestFilters {
+
+	c := IssueGetRequestFiltersInit()
+
+	for n, v := range f.fields {
+		c.fields[n] = append([]string{}, v...)
+	}
+
+	for id, v := range f.cf {
+		c.cf[id] = v
+	}
+
+	for n, e := range f.filters {
+		e.values = append([]string{}, e.values...)
+		c.filters[n] = e
+	}
+
+	c.err = f.err
+
+	return c
+}
+
+// IsSet checks whether filter for the field is added either by `FieldAdd()` or `Add()`
+func (f *IssueGetRequestFilters) IsSet(field string) bool {
+	if _, b := f.fields[field]; b == true {
+		return true
+	}
+	_, b := f.filters[field]
+	return b
+}
+
+func (f *IssueGetRequestFilters) filterAdd(field string, t filterType, op FilterOperator, values []string) *IssueGetRequestFilters {
+
+	e, err := filterInit(t, op, values)
+	if err != nil {
+		if f.err == nil {
+			f.err = fmt.Errorf("issue filter `%s`: %w", field, err)
+		}
+		return f
+	}
+
+	f.filters[field] = e
+
+	return f
+}
+
 func (f *IssueGetRequestFilters) url(v *url.Values) {
 
 	// Filter fields (e.g. `issue_id`, `tracker_id`, etc)
@@ -508,24 +605,36 @@ func (f *IssueGetRequestFilters) url(v *url.Values) {
 	for id, value := range f.cf {
 		v.Set("cf_"+strconv.FormatInt(id, 10), value)
 	}
+
+	// Filters with operators
+	for n, e := range f.filters {
+		v.Set(n, e.expression())
+	}
 }
 
 func IssueGetRequestSortInit() *IssueGetRequestSort {
 	return &IssueGetRequestSort{}
 }
 
+// Set sets the only sort key replacing all previously added ones
 func (s *IssueGetRequestSort) Set(field string, desc bool) *IssueGetRequestSort {
-	s.field = field
-	s.desc = desc
+	s.fields = sortFields{}.add(field, desc)
 	return s
 }
 
-func (s *IssueGetRequestSort) url(v *url.Values) {
+// Add adds sort key (e.g. `priority`, `updated_on` or `id`).
+// Issues are sorted by keys in order they are added
+func (s *IssueGetRequestSort) Add(field string, desc bool) *IssueGetRequestSort {
+	s.fields = s.fields.add(field, desc)
+	return s
+}
 
-	f := s.field
-	if s.desc == true {
-		f += ":desc"
-	}
+// CustomFieldAdd adds sort key for custom field
+func (s *IssueGetRequestSort) CustomFieldAdd(id int64, desc bool) *IssueGetRequestSort {
+	s.fields = s.fields.add(sortCustomField(id), desc)
+	return s
+}
 
-	v.Set("sort", f)
+func (s *IssueGetRequestSort) url(v *url.Values) {
+	s.fields.url(v)
 }
@@ -4,6 +4,7 @@ import (
 	"os"
 	"strconv"
 	"testing"
+	"time"
 )
 
 var (
@@ -16,11 +17,11 @@ var (
 	testIssueNote        = "Test issue note"
 	testIssuePrivateNote = "Test issue private note"
 
-	testIssueStartDate = "2022-07-01"
-	testIssueDueDate   = "2022-07-02"
+	testIssueStartDate = DateInit(2022, time.July, 1)
+	testIssueDueDate   = DateInit(2022, time.July, 2)
 
-	testIssueStartDate2 = "2022-07-03"
-	testIssueDueDate2   = "2022-07-04"
+	testIssueStartDate2 = DateInit(2022, time.July, 3)
+	testIssueDueDate2   = DateInit(2022, time.July, 4)
 )
 
 func TestIssuesCRUD(t *testing.T) {
@@ -115,7 +116,7 @@ func testIssueCreate(t *testing.T, r Context, projectID, userID int64, upload *A
 		t.Fatal("Issue create error:", err, s)
 	}
 
-	if (o.StartDate == nil || o.DueDate == nil) || (*o.StartDate != testIssueStartDate || *o.DueDate != testIssueDueDate) {
+	if (o.StartDate == nil || o.DueDate == nil) || (o.StartDate.Equal(testIssueStartDate.Time) == false || o.DueDate.Equal(testIssueDueDate.Time) == false) {
 		t.Fatal("Issue create error: incorrect issue start or due date")
 	}
 
@@ -147,7 +148,7 @@ func testIssueUpdate(t *testing.T, r Context, id int64) {
 		t.Fatal("Issue update error:", err, s)
 	}
 
-	if (o.StartDate == nil || o.DueDate == nil) || (*o.StartDate != testIssueStartDate2 || *o.DueDate != testIssueDueDate2) {
+	if (o.StartDate == nil || o.DueDate == nil) || (o.StartDate.Equal(testIssueStartDate2.Time) == false || o.DueDate.Equal(testIssueDueDate2.Time) == false) {
 		t.Fatal("Issue update error: incorrect issue start or due date")
 	}
 
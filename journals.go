@@ -0,0 +1,365 @@
+package redmine
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// JournalDetailProperty defines journal detail property type
+type JournalDetailProperty string
+
+// JournalDetailProperty const
+const (
+	JournalDetailPropertyAttr        JournalDetailProperty = "attr"
+	JournalDetailPropertyCustomField JournalDetailProperty = "cf"
+	JournalDetailPropertyAttachment  JournalDetailProperty = "attachment"
+	JournalDetailPropertyRelation    JournalDetailProperty = "relation"
+)
+
+// JournalChange is an interface implemented by all decoded journal details
+type JournalChange interface {
+
+	// JournalDetail returns raw journal detail the change decoded from
+	JournalDetail() IssueJournalDetailObject
+}
+
+// JournalStatusChange describes issue status change
+type JournalStatusChange struct {
+	Old    *IDName
+	New    *IDName
+	Detail IssueJournalDetailObject
+}
+
+// JournalAssigneeChange describes issue assignee change.
+// Values are nil if issue has not been assigned before or has been unassigned
+type JournalAssigneeChange struct {
+	Old    *IDName
+	New    *IDName
+	Detail IssueJournalDetailObject
+}
+
+// JournalTrackerChange describes issue tracker change
+type JournalTrackerChange struct {
+	Old    *IDName
+	New    *IDName
+	Detail IssueJournalDetailObject
+}
+
+// JournalAttributeChange describes change of any other issue attribute (e.g. `subject`, `due_date`)
+type JournalAttributeChange struct {
+	Name   string
+	Old    *string
+	New    *string
+	Detail IssueJournalDetailObject
+}
+
+// JournalCustomFieldChange describes custom field value change.
+// For multiple custom fields every added or removed value is a separate change
+type JournalCustomFieldChange struct {
+	ID     int64
+	Name   string // Resolved only if custom fields lookup is set
+	Old    *string
+	New    *string
+	Detail IssueJournalDetailObject
+}
+
+// JournalAttachmentAdded describes attachment added to issue
+type JournalAttachmentAdded struct {
+	ID       int64
+	FileName string
+	Detail   IssueJournalDetailObject
+}
+
+// JournalAttachmentRemoved describes attachment removed from issue
+type JournalAttachmentRemoved struct {
+	ID       int64
+	FileName string
+	Detail   IssueJournalDetailObject
+}
+
+// JournalRelationAdded describes relation added to issue
+type JournalRelationAdded struct {
+	RelationType string
+	IssueID      int64
+	Detail       IssueJournalDetailObject
+}
+
+// JournalRelationRemoved describes relation removed from issue
+type JournalRelationRemoved struct {
+	RelationType string
+	IssueID      int64
+	Detail       IssueJournalDetailObject
+}
+
+// JournalUnknownChange describes journal detail with unknown property
+type JournalUnknownChange struct {
+	Detail IssueJournalDetailObject
+}
+
+// JournalDecoder decodes raw journal details into typed changes.
+// Names of statuses, users, trackers and custom fields are resolved
+// only if appropriate lookups are set
+type JournalDecoder struct {
+	statuses     map[int64]string
+	users        map[int64]string
+	trackers     map[int64]string
+	customFields map[int64]string
+}
+
+// JournalDetail returns raw journal detail of the status change
+func (c JournalStatusChange) JournalDetail() IssueJournalDetailObject { return c.Detail }
+
+// JournalDetail returns raw journal detail of the assignee change
+func (c JournalAssigneeChange) JournalDetail() IssueJournalDetailObject { return c.Detail }
+
+// JournalDetail returns raw journal detail of the tracker change
+func (c JournalTrackerChange) JournalDetail() IssueJournalDetailObject { return c.Detail }
+
+// JournalDetail returns raw journal detail of the attribute change
+func (c JournalAttributeChange) JournalDetail() IssueJournalDetailObject { return c.Detail }
+
+// JournalDetail returns raw journal detail of the custom field change
+func (c JournalCustomFieldChange) JournalDetail() IssueJournalDetailObject { return c.Detail }
+
+// JournalDetail returns raw journal detail of the added attachment
+func (c JournalAttachmentAdded) JournalDetail() IssueJournalDetailObject { return c.Detail }
+
+// JournalDetail returns raw journal detail of the removed attachment
+func (c JournalAttachmentRemoved) JournalDetail() IssueJournalDetailObject { return c.Detail }
+
+// JournalDetail returns raw journal detail of the added relation
+func (c JournalRelationAdded) JournalDetail() IssueJournalDetailObject { return c.Detail }
+
+// JournalDetail returns raw journal detail of the removed relation
+func (c JournalRelationRemoved) JournalDetail() IssueJournalDetailObject { return c.Detail }
+
+// JournalDetail returns raw journal detail of the unknown change
+func (c JournalUnknownChange) JournalDetail() IssueJournalDetailObject { return c.Detail }
+
+// String returns journal detail property name
+func (p JournalDetailProperty) String() string {
+	return string(p)
+}
+
+// JournalDecoderInit creates new journal decoder without lookups. Names are
+// not resolved until lookups are set with `StatusesSet()`, `UsersSet()` etc
+func JournalDecoderInit() *JournalDecoder {
+	return &JournalDecoder{
+		statuses:     make(map[int64]string),
+		users:        make(map[int64]string),
+		trackers:     make(map[int64]string),
+		customFields: make(map[int64]string),
+	}
+}
+
+// StatusesSet sets issue statuses lookup (e.g. from `IssueStatusAllGet()`)
+func (d *JournalDecoder) StatusesSet(statuses []IssueStatusObject) *JournalDecoder {
+	for _, s := range statuses {
+		d.statuses[s.ID] = s.Name
+	}
+	return d
+}
+
+// UsersSet sets users lookup (e.g. from `UserAllGet()`)
+func (d *JournalDecoder) UsersSet(users []UserObject) *JournalDecoder {
+	for _, u := range users {
+		d.users[u.ID] = strings.TrimSpace(u.FirstName + " " + u.LastName)
+	}
+	return d
+}
+
+// GroupsSet sets groups lookup (e.g. from `GroupAllGet()`). Used to resolve assignees
+// as issues may be assigned to groups
+func (d *JournalDecoder) GroupsSet(groups []GroupObject) *JournalDecoder {
+	for _, g := range groups {
+		d.users[g.ID] = g.Name
+	}
+	return d
+}
+
+// TrackersSet sets trackers lookup (e.g. from `TrackerAllGet()`)
+func (d *JournalDecoder) TrackersSet(trackers []TrackerObject) *JournalDecoder {
+	for _, t := range trackers {
+		d.trackers[t.ID] = t.Name
+	}
+	return d
+}
+
+// CustomFieldsSet sets custom fields lookup (e.g. from `CustomFieldAllGet()`)
+func (d *JournalDecoder) CustomFieldsSet(customFields []CustomFieldObject) *JournalDecoder {
+	for _, c := range customFields {
+		d.customFields[c.ID] = c.Name
+	}
+	return d
+}
+
+// Decode decodes single journal detail
+func (d *JournalDecoder) Decode(detail IssueJournalDetailObject) (JournalChange, error) {
+
+	switch JournalDetailProperty(detail.Property) {
+	case JournalDetailPropertyAttr:
+		return d.decodeAttr(detail)
+
+	case JournalDetailPropertyCustomField:
+
+		id, err := strconv.ParseInt(detail.Name, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("journal detail decode error: incorrect custom field id `%s`", detail.Name)
+		}
+
+		return JournalCustomFieldChange{
+			ID:     id,
+			Name:   d.customFields[id],
+			Old:    journalValue(detail.OldValue),
+			New:    journalValue(detail.NewValue),
+			Detail: detail,
+		}, nil
+
+	case JournalDetailPropertyAttachment:
+
+		id, err := strconv.ParseInt(detail.Name, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("journal detail decode error: incorrect attachment id `%s`", detail.Name)
+		}
+
+		if detail.NewValue != "" {
+			return JournalAttachmentAdded{
+				ID:       id,
+				FileName: detail.NewValue,
+				Detail:   detail,
+			}, nil
+		}
+
+		return JournalAttachmentRemoved{
+			ID:       id,
+			FileName: detail.OldValue,
+			Detail:   detail,
+		}, nil
+
+	case JournalDetailPropertyRelation:
+
+		if detail.NewValue != "" {
+
+			id, err := strconv.ParseInt(detail.NewValue, 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("journal detail decode error: incorrect related issue id `%s`", detail.NewValue)
+			}
+
+			return JournalRelationAdded{
+				RelationType: detail.Name,
+				IssueID:      id,
+				Detail:       detail,
+			}, nil
+		}
+
+		id, err := strconv.ParseInt(detail.OldValue, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("journal detail decode error: incorrect related issue id `%s`", detail.OldValue)
+		}
+
+		return JournalRelationRemoved{
+			RelationType: detail.Name,
+			IssueID:      id,
+			Detail:       detail,
+		}, nil
+	}
+
+	return JournalUnknownChange{
+		Detail: detail,
+	}, nil
+}
+
+// DecodeJournal decodes all details of specified journal
+func (d *JournalDecoder) DecodeJournal(journal IssueJournalObject) ([]JournalChange, error) {
+
+	var cs []JournalChange
+
+	for _, e := range journal.Details {
+
+		c, err := d.Decode(e)
+		if err != nil {
+			return nil, err
+		}
+
+		cs = append(cs, c)
+	}
+
+	return cs, nil
+}
+
+func (d *JournalDecoder) decodeAttr(detail IssueJournalDetailObject) (JournalChange, error) {
+
+	var lookup map[int64]string
+
+	switch detail.Name {
+	case "status_id":
+		lookup = d.statuses
+	case "assigned_to_id":
+		lookup = d.users
+	case "tracker_id":
+		lookup = d.trackers
+	default:
+		return JournalAttributeChange{
+			Name:   detail.Name,
+			Old:    journalValue(detail.OldValue),
+			New:    journalValue(detail.NewValue),
+			Detail: detail,
+		}, nil
+	}
+
+	o, err := journalIDName(detail.OldValue, lookup)
+	if err != nil {
+		return nil, err
+	}
+
+	n, err := journalIDName(detail.NewValue, lookup)
+	if err != nil {
+		return nil, err
+	}
+
+	switch detail.Name {
+	case "status_id":
+		return JournalStatusChange{
+			Old:    o,
+			New:    n,
+			Detail: detail,
+		}, nil
+	case "assigned_to_id":
+		return JournalAssigneeChange{
+			Old:    o,
+			New:    n,
+			Detail: detail,
+		}, nil
+	}
+
+	return JournalTrackerChange{
+		Old:    o,
+		New:    n,
+		Detail: detail,
+	}, nil
+}
+
+func journalValue(v string) *string {
+	if v == "" {
+		return nil
+	}
+	return &v
+}
+
+func journalIDName(v string, lookup map[int64]string) (*IDName, error) {
+
+	if v == "" {
+		return nil, nil
+	}
+
+	id, err := strconv.ParseInt(v, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("journal detail decode error: incorrect id `%s`", v)
+	}
+
+	return &IDName{
+		ID:   id,
+		Name: lookup[id],
+	}, nil
+}
@@ -0,0 +1,61 @@
+package redmine
+
+import (
+	"testing"
+)
+
+func TestJournalDecode(t *testing.T) {
+
+	d := JournalDecoderInit().
+		StatusesSet([]IssueStatusObject{{ID: 1, Name: "New"}, {ID: 2, Name: "In Progress"}}).
+		UsersSet([]UserObject{{ID: 5, FirstName: "John", LastName: "Doe"}}).
+		CustomFieldsSet([]CustomFieldObject{{ID: 12, Name: "Billable"}})
+
+	cs, err := d.DecodeJournal(IssueJournalObject{
+		Details: []IssueJournalDetailObject{
+			{Property: "attr", Name: "status_id", OldValue: "1", NewValue: "2"},
+			{Property: "attr", Name: "assigned_to_id", NewValue: "5"},
+			{Property: "attr", Name: "subject", OldValue: "Old", NewValue: "New"},
+			{Property: "cf", Name: "12", OldValue: "0", NewValue: "1"},
+			{Property: "attachment", Name: "33", NewValue: "file.txt"},
+			{Property: "relation", Name: "blocks", OldValue: "44"},
+		},
+	})
+	if err != nil {
+		t.Fatal("Journal decode error:", err)
+	}
+
+	if len(cs) != 6 {
+		t.Fatal("Journal decode error: incorrect changes count")
+	}
+
+	if c, b := cs[0].(JournalStatusChange); b == false || c.Old.Name != "New" || c.New.Name != "In Progress" {
+		t.Fatal("Journal decode error: incorrect status change")
+	}
+
+	if c, b := cs[1].(JournalAssigneeChange); b == false || c.Old != nil || c.New.ID != 5 || c.New.Name != "John Doe" {
+		t.Fatal("Journal decode error: incorrect assignee change")
+	}
+
+	if c, b := cs[2].(JournalAttributeChange); b == false || c.Name != "subject" || *c.New != "New" {
+		t.Fatal("Journal decode error: incorrect attribute change")
+	}
+
+	if c, b := cs[3].(JournalCustomFieldChange); b == false || c.ID != 12 || c.Name != "Billable" || *c.New != "1" {
+		t.Fatal("Journal decode error: incorrect custom field change")
+	}
+
+	if c, b := cs[4].(JournalAttachmentAdded); b == false || c.ID != 33 || c.FileName != "file.txt" {
+		t.Fatal("Journal decode error: incorrect attachment change")
+	}
+
+	if c, b := cs[5].(JournalRelationRemoved); b == false || c.IssueID != 44 || c.RelationType != "blocks" {
+		t.Fatal("Journal decode error: incorrect relation change")
+	}
+
+	if _, err := d.Decode(IssueJournalDetailObject{Property: "cf", Name: "abc"}); err == nil {
+		t.Fatal("Journal decode error: incorrect custom field id has been accepted")
+	}
+
+	t.Logf("Journal decode: success")
+}
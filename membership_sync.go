@@ -0,0 +1,342 @@
+package redmine
+
+import (
+	"fmt"
+	"slices"
+)
+
+// MembershipSyncObject describes desired project membership of either a user or a group
+type MembershipSyncObject struct {
+	UserID  int64 // Zero for groups
+	GroupID int64 // Zero for users
+	RoleIDs []int64
+}
+
+// MembershipSyncResult contains changes made by memberships sync
+type MembershipSyncResult struct {
+	Added   []MembershipObject
+	Updated []int64 // IDs of updated memberships
+	Deleted []int64 // IDs of deleted memberships
+}
+
+// MembershipSyncPlan contains changes required to bring project memberships into desired state
+type MembershipSyncPlan struct {
+	Add    []MembershipSyncObject // Memberships to add
+	Update []MembershipSyncUpdate // Memberships to set own roles
+	Delete []MembershipObject     // Memberships to delete
+}
+
+// MembershipSyncUpdate contains own roles change of existing membership.
+// Own roles are cleared in memberships with inherited roles instead of deletion
+type MembershipSyncUpdate struct {
+	Membership MembershipObject
+	OwnRoleIDs []int64 // Current own roles
+	RoleIDs    []int64 // Own roles to set
+}
+
+// membershipSyncPrincipal contains desired roles of the principal
+type membershipSyncPrincipal struct {
+	id    int64
+	roles []int64
+}
+
+// MembershipSync brings memberships of project with specified ID or identifier
+// into desired state with minimal set of changes. Roles the users inherit from
+// groups are taken into account: own roles are set only for the rest of desired
+// roles, memberships derived from groups are kept. Groups memberships are synced
+// first, so users memberships are compared with roles inherited from the groups
+// after the sync
+func (r *Context) MembershipSync(projectID string, desired []MembershipSyncObject) (MembershipSyncResult, StatusCode, error) {
+
+	var res MembershipSyncResult
+
+	users, groups, err := membershipSyncPrincipals(desired)
+	if err != nil {
+		return res, 0, err
+	}
+
+	ms, status, err := r.MembershipAllGet(projectID)
+	if err != nil {
+		return res, status, err
+	}
+
+	var gp MembershipSyncPlan
+
+	membershipSyncDiff(ms.Memberships, groups, true, &gp)
+
+	res, s, err := r.MembershipSyncApply(projectID, gp)
+	if err != nil {
+		return res, s, err
+	}
+
+	// Groups changes affect roles inherited by users
+	if s != 0 {
+		ms, status, err = r.MembershipAllGet(projectID)
+		if err != nil {
+			return res, status, err
+		}
+	}
+
+	var up MembershipSyncPlan
+
+	membershipSyncDiff(ms.Memberships, users, false, &up)
+
+	ur, s, err := r.MembershipSyncApply(projectID, up)
+
+	res.Added = append(res.Added, ur.Added...)
+	res.Updated = append(res.Updated, ur.Updated...)
+	res.Deleted = append(res.Deleted, ur.Deleted...)
+
+	if err != nil {
+		return res, s, err
+	}
+
+	if s != 0 {
+		status = s
+	}
+
+	return res, status, nil
+}
+
+// MembershipSyncDiff returns changes `MembershipSync` makes to bring current
+// project memberships into desired state, without making them. Unlike the sync,
+// users memberships are compared with roles inherited from groups before groups
+// changes. Groups changes precede users ones within the plan
+func MembershipSyncDiff(current []MembershipObject, desired []MembershipSyncObject) (MembershipSyncPlan, error) {
+
+	var p MembershipSyncPlan
+
+	users, groups, err := membershipSyncPrincipals(desired)
+	if err != nil {
+		return p, err
+	}
+
+	membershipSyncDiff(current, groups, true, &p)
+	membershipSyncDiff(current, users, false, &p)
+
+	return p, nil
+}
+
+// MembershipSyncApply makes changes of the plan within project with specified
+// ID or identifier in order: additions, updates and deletions. Zero status is
+// returned if no changes are made
+func (r *Context) MembershipSyncApply(projectID string, p MembershipSyncPlan) (MembershipSyncResult, StatusCode, error) {
+
+	var (
+		res    MembershipSyncResult
+		status StatusCode
+		err    error
+	)
+
+	for _, a := range p.Add {
+
+		id := a.UserID
+		if id == 0 {
+			id = a.GroupID
+		}
+
+		m, s, err := r.MembershipAdd(projectID, MembershipAdd{
+			Membership: MembershipAddObject{
+				UserID:  id,
+				RoleIDs: a.RoleIDs,
+			},
+		})
+		if err != nil {
+			return res, s, fmt.Errorf("membership sync: principal %d: %w", id, err)
+		}
+
+		res.Added = append(res.Added, m)
+		status = s
+	}
+
+	for _, u := range p.Update {
+
+		status, err = r.MembershipUpdate(u.Membership.ID, MembershipUpdate{
+			Membership: MembershipUpdateObject{
+				RoleIDs: u.RoleIDs,
+			},
+		})
+		if err != nil {
+			return res, status, fmt.Errorf("membership sync: principal %d: %w", membershipPrincipalID(u.Membership), err)
+		}
+
+		res.Updated = append(res.Updated, u.Membership.ID)
+	}
+
+	for _, m := range p.Delete {
+
+		status, err = r.MembershipDelete(m.ID)
+		if err != nil {
+			return res, status, fmt.Errorf("membership sync: principal %d: %w", membershipPrincipalID(m), err)
+		}
+
+		res.Deleted = append(res.Deleted, m.ID)
+	}
+
+	return res, status, nil
+}
+
+// membershipSyncPrincipals validates desired memberships and splits them into
+// users and groups ones
+func membershipSyncPrincipals(desired []MembershipSyncObject) ([]membershipSyncPrincipal, []membershipSyncPrincipal, error) {
+
+	var users, groups []membershipSyncPrincipal
+
+	for _, d := range desired {
+
+		if (d.UserID == 0) == (d.GroupID == 0) {
+			return nil, nil, fmt.Errorf("membership sync: either user or group ID must be set")
+		}
+
+		if len(d.RoleIDs) == 0 {
+			return nil, nil, fmt.Errorf("membership sync: principal %d has no roles", d.UserID+d.GroupID)
+		}
+
+		if d.UserID != 0 {
+			users = membershipSyncPrincipalAdd(users, d.UserID, d.RoleIDs)
+		} else {
+			groups = membershipSyncPrincipalAdd(groups, d.GroupID, d.RoleIDs)
+		}
+	}
+
+	return users, groups, nil
+}
+
+// membershipSyncDiff adds changes of either users or groups memberships into the plan
+func membershipSyncDiff(current []MembershipObject, desired []membershipSyncPrincipal, group bool, p *MembershipSyncPlan) {
+
+	type membership struct {
+		m         MembershipObject
+		own       []int64
+		inherited []int64
+	}
+
+	ex := make(map[int64]membership)
+
+	for _, m := range current {
+
+		pr := m.User
+		if group == true {
+			pr = m.Group
+		}
+
+		if pr == nil {
+			continue
+		}
+
+		e := membership{m: m}
+
+		for _, rl := range m.Roles {
+			if rl.Inherited == true {
+				e.inherited = append(e.inherited, rl.ID)
+			} else {
+				e.own = append(e.own, rl.ID)
+			}
+		}
+
+		slices.Sort(e.own)
+
+		ex[pr.ID] = e
+	}
+
+	ds := make(map[int64]bool)
+
+	for _, d := range desired {
+
+		ds[d.id] = true
+
+		e, b := ex[d.id]
+		if b == false {
+
+			a := MembershipSyncObject{RoleIDs: d.roles}
+			if group == true {
+				a.GroupID = d.id
+			} else {
+				a.UserID = d.id
+			}
+
+			p.Add = append(p.Add, a)
+
+			continue
+		}
+
+		// Inherited roles are not required to be own ones
+		own := []int64{}
+		for _, rl := range d.roles {
+			if slices.Contains(e.inherited, rl) == false {
+				own = append(own, rl)
+			}
+		}
+
+		if slices.Equal(e.own, own) == true {
+			continue
+		}
+
+		p.Update = append(p.Update, MembershipSyncUpdate{
+			Membership: e.m,
+			OwnRoleIDs: e.own,
+			RoleIDs:    own,
+		})
+	}
+
+	for _, m := range current {
+
+		pr := m.User
+		if group == true {
+			pr = m.Group
+		}
+
+		if pr == nil || ds[pr.ID] == true {
+			continue
+		}
+
+		e := ex[pr.ID]
+
+		// Memberships derived from groups
+		if len(e.own) == 0 {
+			continue
+		}
+
+		// Memberships with inherited roles can not be deleted, own roles are removed instead
+		if len(e.inherited) > 0 {
+			p.Update = append(p.Update, MembershipSyncUpdate{
+				Membership: m,
+				OwnRoleIDs: e.own,
+				RoleIDs:    []int64{},
+			})
+			continue
+		}
+
+		p.Delete = append(p.Delete, m)
+	}
+}
+
+// membershipPrincipalID returns ID of membership user or group
+func membershipPrincipalID(m MembershipObject) int64 {
+	if m.User != nil {
+		return m.User.ID
+	}
+	if m.Group != nil {
+		return m.Group.ID
+	}
+	return 0
+}
+
+// membershipSyncPrincipalAdd adds principal roles merging roles of duplicated principals
+func membershipSyncPrincipalAdd(ps []membershipSyncPrincipal, id int64, roles []int64) []membershipSyncPrincipal {
+
+	i := slices.IndexFunc(ps, func(p membershipSyncPrincipal) bool {
+		return p.id == id
+	})
+	if i < 0 {
+		ps = append(ps, membershipSyncPrincipal{id: id})
+		i = len(ps) - 1
+	}
+
+	rs := append(ps[i].roles, roles...)
+	slices.Sort(rs)
+
+	ps[i].roles = slices.Compact(rs)
+
+	return ps
+}
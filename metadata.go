@@ -0,0 +1,243 @@
+package redmine
+
+import (
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	metadataTTLDefault = 10 * time.Minute
+)
+
+// Metadata caches rarely changed Redmine lists (priorities, time entry activities,
+// issue statuses, trackers and custom fields) and provides lookups by ID and name.
+// Each list is fetched on first use and refetched when TTL is expired.
+// Metadata is safe for concurrent use
+type Metadata struct {
+	r     *Context
+	cache *listCache
+
+	priorities   metadataList[EnumerationPriorityObject]
+	activities   metadataList[EnumerationTimeEntryActivityObject]
+	statuses     metadataList[IssueStatusObject]
+	trackers     metadataList[TrackerObject]
+	customFields metadataList[CustomFieldObject]
+}
+
+// listCache contains settings and lock shared by cached lists
+type listCache struct {
+	ttl time.Duration
+	now func() time.Time
+	mu  sync.Mutex
+}
+
+type metadataList[T any] struct {
+	items     []T
+	fetchedAt time.Time
+}
+
+func listCacheInit(ttl time.Duration) *listCache {
+
+	if ttl <= 0 {
+		ttl = metadataTTLDefault
+	}
+
+	return &listCache{
+		ttl: ttl,
+		now: time.Now,
+	}
+}
+
+func metadataInit(r *Context, ttl time.Duration) *Metadata {
+	return &Metadata{
+		r:     r,
+		cache: listCacheInit(ttl),
+	}
+}
+
+// Metadata returns metadata cache of the context. Cache is created by `Init`,
+// so context must be created with it. Metadata is safe for concurrent use
+func (r *Context) Metadata() *Metadata {
+	return r.metadata
+}
+
+// Refresh refetches all cached lists
+func (m *Metadata) Refresh() error {
+
+	m.Invalidate()
+
+	if _, err := m.Priorities(); err != nil {
+		return err
+	}
+
+	if _, err := m.TimeEntryActivities(); err != nil {
+		return err
+	}
+
+	if _, err := m.IssueStatuses(); err != nil {
+		return err
+	}
+
+	if _, err := m.Trackers(); err != nil {
+		return err
+	}
+
+	if _, err := m.CustomFields(); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Invalidate drops all cached lists. Lists will be fetched on next use
+func (m *Metadata) Invalidate() {
+
+	m.cache.mu.Lock()
+	defer m.cache.mu.Unlock()
+
+	m.priorities = metadataList[EnumerationPriorityObject]{}
+	m.activities = metadataList[EnumerationTimeEntryActivityObject]{}
+	m.statuses = metadataList[IssueStatusObject]{}
+	m.trackers = metadataList[TrackerObject]{}
+	m.customFields = metadataList[CustomFieldObject]{}
+}
+
+// Priorities returns issue priorities
+func (m *Metadata) Priorities() ([]EnumerationPriorityObject, error) {
+	return metadataGet(m.cache, &m.priorities, m.r.EnumerationPrioritiesAllGet)
+}
+
+// PriorityByID looks up issue priority by ID
+func (m *Metadata) PriorityByID(id int64) (EnumerationPriorityObject, bool, error) {
+	return metadataFind(m.Priorities, func(e EnumerationPriorityObject) bool {
+		return e.ID == id
+	})
+}
+
+// PriorityByName looks up issue priority by name (case insensitive)
+func (m *Metadata) PriorityByName(name string) (EnumerationPriorityObject, bool, error) {
+	return metadataFind(m.Priorities, func(e EnumerationPriorityObject) bool {
+		return strings.EqualFold(e.Name, name)
+	})
+}
+
+// TimeEntryActivities returns time entry activities
+func (m *Metadata) TimeEntryActivities() ([]EnumerationTimeEntryActivityObject, error) {
+	return metadataGet(m.cache, &m.activities, m.r.EnumerationTimeEntryActivitiesAllGet)
+}
+
+// TimeEntryActivityByID looks up time entry activity by ID
+func (m *Metadata) TimeEntryActivityByID(id int64) (EnumerationTimeEntryActivityObject, bool, error) {
+	return metadataFind(m.TimeEntryActivities, func(e EnumerationTimeEntryActivityObject) bool {
+		return e.ID == id
+	})
+}
+
+// TimeEntryActivityByName looks up time entry activity by name (case insensitive)
+func (m *Metadata) TimeEntryActivityByName(name string) (EnumerationTimeEntryActivityObject, bool, error) {
+	return metadataFind(m.TimeEntryActivities, func(e EnumerationTimeEntryActivityObject) bool {
+		return strings.EqualFold(e.Name, name)
+	})
+}
+
+// IssueStatuses returns issue statuses
+func (m *Metadata) IssueStatuses() ([]IssueStatusObject, error) {
+	return metadataGet(m.cache, &m.statuses, m.r.IssueStatusAllGet)
+}
+
+// IssueStatusByID looks up issue status by ID
+func (m *Metadata) IssueStatusByID(id int64) (IssueStatusObject, bool, error) {
+	return metadataFind(m.IssueStatuses, func(e IssueStatusObject) bool {
+		return e.ID == id
+	})
+}
+
+// IssueStatusByName looks up issue status by name (case insensitive)
+func (m *Metadata) IssueStatusByName(name string) (IssueStatusObject, bool, error) {
+	return metadataFind(m.IssueStatuses, func(e IssueStatusObject) bool {
+		return strings.EqualFold(e.Name, name)
+	})
+}
+
+// Trackers returns trackers
+func (m *Metadata) Trackers() ([]TrackerObject, error) {
+	return metadataGet(m.cache, &m.trackers, m.r.TrackerAllGet)
+}
+
+// TrackerByID looks up tracker by ID
+func (m *Metadata) TrackerByID(id int64) (TrackerObject, bool, error) {
+	return metadataFind(m.Trackers, func(e TrackerObject) bool {
+		return e.ID == id
+	})
+}
+
+// TrackerByName looks up tracker by name (case insensitive)
+func (m *Metadata) TrackerByName(name string) (TrackerObject, bool, error) {
+	return metadataFind(m.Trackers, func(e TrackerObject) bool {
+		return strings.EqualFold(e.Name, name)
+	})
+}
+
+// CustomFields returns custom fields. Administrator privileges are required
+func (m *Metadata) CustomFields() ([]CustomFieldObject, error) {
+	return metadataGet(m.cache, &m.customFields, m.r.CustomFieldAllGet)
+}
+
+// CustomFieldByID looks up custom field by ID
+func (m *Metadata) CustomFieldByID(id int64) (CustomFieldObject, bool, error) {
+	return metadataFind(m.CustomFields, func(e CustomFieldObject) bool {
+		return e.ID == id
+	})
+}
+
+// CustomFieldByName looks up custom field by customized type (e.g. `issue`,
+// `project` or `user`) and name (case insensitive)
+func (m *Metadata) CustomFieldByName(customizedType, name string) (CustomFieldObject, bool, error) {
+	return metadataFind(m.CustomFields, func(e CustomFieldObject) bool {
+		return e.CustomizedType == customizedType && strings.EqualFold(e.Name, name)
+	})
+}
+
+// metadataGet returns cached list or fetches it if list is absent or expired.
+// Returned list is a copy and may be modified by the caller
+func metadataGet[T any](c *listCache, l *metadataList[T], fetch func() ([]T, StatusCode, error)) ([]T, error) {
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if l.items == nil || c.now().Sub(l.fetchedAt) >= c.ttl {
+
+		items, _, err := fetch()
+		if err != nil {
+			return nil, err
+		}
+
+		if items == nil {
+			items = []T{}
+		}
+
+		l.items = items
+		l.fetchedAt = c.now()
+	}
+
+	return append([]T{}, l.items...), nil
+}
+
+func metadataFind[T any](get func() ([]T, error), match func(T) bool) (T, bool, error) {
+
+	var z T
+
+	items, err := get()
+	if err != nil {
+		return z, false, err
+	}
+
+	for _, e := range items {
+		if match(e) == true {
+			return e, true, nil
+		}
+	}
+
+	return z, false, nil
+}
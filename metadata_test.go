@@ -0,0 +1,71 @@
+package redmine
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestMetadata(t *testing.T) {
+
+	var hits int64
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		n := atomic.AddInt64(&hits, 1)
+		fmt.Fprintf(w, `{"issue_statuses":[{"id":1,"name":"New","is_closed":false},{"id":5,"name":"Closed #%d","is_closed":true}]}`, n)
+	}))
+	defer srv.Close()
+
+	r := Init(
+		Settings{
+			Endpoint:    srv.URL,
+			MetadataTTL: time.Minute,
+		},
+	)
+
+	now := time.Now()
+	m := r.Metadata()
+	m.cache.now = func() time.Time { return now }
+
+	var wg sync.WaitGroup
+
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if s, b, err := m.IssueStatusByName("new"); err != nil || b == false || s.ID != 1 {
+				t.Error("Metadata lookup error:", s, b, err)
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	if s, b, err := m.IssueStatusByID(5); err != nil || b == false || s.IsClosed == false || s.Name != "Closed #1" {
+		t.Fatal("Metadata lookup error:", s, b, err)
+	}
+
+	if _, b, err := m.IssueStatusByID(7); err != nil || b == true {
+		t.Fatal("Metadata lookup error: unknown status found")
+	}
+
+	if hits != 1 {
+		t.Fatalf("Metadata cache error: expected 1 request, got %d", hits)
+	}
+
+	now = now.Add(2 * time.Minute)
+
+	if s, _, _ := m.IssueStatusByID(5); s.Name != "Closed #2" || hits != 2 {
+		t.Fatal("Metadata cache error: expired list is not refetched")
+	}
+
+	m.Invalidate()
+
+	if s, _, _ := m.IssueStatusByID(5); s.Name != "Closed #3" || hits != 3 {
+		t.Fatal("Metadata cache error: invalidated list is not refetched")
+	}
+}
@@ -0,0 +1,278 @@
+package offboarding
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+
+	redmine "github.com/nixys/nxs-go-redmine/v5"
+)
+
+// Settings contains offboarding settings
+type Settings struct {
+	DryRun bool // Only collect the report, no changes are made
+
+	ReassignToID        int64           // New assignee of user open issues. Zero leaves issues unassigned
+	ProjectReassignIDs  map[int64]int64 // New assignees by project IDs, override `ReassignToID`
+	ReassignNotes       string          // Notes added to reassigned issues
+	KeepWatchers        bool            // Do not remove user from issues watchers
+	KeepMemberships     bool            // Do not remove user from groups and projects
+	KeepAccountUnlocked bool            // Do not lock user account
+}
+
+// Offboarder offboards users
+type Offboarder struct {
+	r *redmine.Context
+	s Settings
+}
+
+// Init creates new offboarder
+func Init(r *redmine.Context, s Settings) *Offboarder {
+	return &Offboarder{
+		r: r,
+		s: s,
+	}
+}
+
+// Offboard locks user account, reassigns user open issues, removes user from
+// issues watchers, groups and projects. Steps are made in the order above. If
+// any step fails the report contains changes made so far. In dry run mode the
+// report describes changes to be made
+func (o *Offboarder) Offboard(userID int64) (Report, error) {
+
+	u, _, err := o.r.UserSingleGet(
+		userID,
+		redmine.UserSingleGetRequest{
+			Includes: []redmine.UserInclude{
+				redmine.UserIncludeGroups,
+				redmine.UserIncludeMemberships,
+			},
+		},
+	)
+	if err != nil {
+		return Report{}, fmt.Errorf("offboarding: user get error: %w", err)
+	}
+
+	rp := Report{
+		User:   redmine.IDName{ID: u.ID, Name: u.Login},
+		DryRun: o.s.DryRun,
+	}
+
+	// Memberships are read before groups are changed, because memberships
+	// with only inherited roles are removed with user groups
+	var ms []redmine.MembershipObject
+
+	if o.s.KeepMemberships == false && u.Memberships != nil {
+		for _, um := range *u.Memberships {
+			m, _, err := o.r.MembershipSingleGet(um.ID)
+			if err != nil {
+				return rp, fmt.Errorf("offboarding: membership %d get error: %w", um.ID, err)
+			}
+			ms = append(ms, m)
+		}
+	}
+
+	if err := o.lock(u, &rp); err != nil {
+		return rp, fmt.Errorf("offboarding: user lock error: %w", err)
+	}
+
+	if err := o.issuesReassign(u, &rp); err != nil {
+		return rp, fmt.Errorf("offboarding: issues reassign error: %w", err)
+	}
+
+	if err := o.watchersDelete(u, &rp); err != nil {
+		return rp, fmt.Errorf("offboarding: watchers delete error: %w", err)
+	}
+
+	if err := o.groupsDelete(u, &rp); err != nil {
+		return rp, fmt.Errorf("offboarding: groups delete error: %w", err)
+	}
+
+	if err := o.membershipsDelete(ms, &rp); err != nil {
+		return rp, fmt.Errorf("offboarding: memberships delete error: %w", err)
+	}
+
+	return rp, nil
+}
+
+func (o *Offboarder) lock(u redmine.UserObject, rp *Report) error {
+
+	if o.s.KeepAccountUnlocked == true || (u.Status != nil && *u.Status == redmine.UserStatusLocked) {
+		return nil
+	}
+
+	if o.s.DryRun == false {
+		if _, err := o.r.UserLock(u.ID); err != nil {
+			return err
+		}
+	}
+
+	rp.Locked = true
+
+	return nil
+}
+
+func (o *Offboarder) issuesReassign(u redmine.UserObject, rp *Report) error {
+
+	f := redmine.IssueGetRequestFiltersInit().
+		Add(redmine.IssueFilterFieldAssignedToID, redmine.FilterOperatorEqual, strconv.FormatInt(u.ID, 10)).
+		Add(redmine.IssueFilterFieldStatusID, redmine.FilterOperatorOpen)
+
+	is, _, err := o.r.IssuesAllGet(redmine.IssueAllGetRequest{Filters: f})
+	if err != nil {
+		return err
+	}
+
+	for _, i := range is.Issues {
+
+		to := o.s.ReassignToID
+		if id, b := o.s.ProjectReassignIDs[i.Project.ID]; b == true {
+			to = id
+		}
+
+		if o.s.DryRun == false {
+
+			uo := redmine.IssueUpdateObject{
+				Unassign: to == 0,
+			}
+
+			if to != 0 {
+				uo.AssignedToID = redmine.Int64Ptr(to)
+			}
+
+			if o.s.ReassignNotes != "" {
+				uo.Notes = redmine.StringPtr(o.s.ReassignNotes)
+			}
+
+			if _, err := o.r.IssueUpdate(i.ID, redmine.IssueUpdate{Issue: uo}); err != nil {
+				return fmt.Errorf("issue %d: %w", i.ID, err)
+			}
+		}
+
+		rp.Issues = append(rp.Issues, IssueReport{
+			ID:           i.ID,
+			Subject:      i.Subject,
+			Project:      i.Project,
+			AssignedToID: to,
+		})
+	}
+
+	return nil
+}
+
+func (o *Offboarder) watchersDelete(u redmine.UserObject, rp *Report) error {
+
+	if o.s.KeepWatchers == true {
+		return nil
+	}
+
+	f := redmine.IssueGetRequestFiltersInit().
+		Add(redmine.IssueFilterFieldWatcherID, redmine.FilterOperatorEqual, strconv.FormatInt(u.ID, 10)).
+		Add(redmine.IssueFilterFieldStatusID, redmine.FilterOperatorAny)
+
+	is, _, err := o.r.IssuesAllGet(redmine.IssueAllGetRequest{Filters: f})
+	if err != nil {
+		return err
+	}
+
+	for _, i := range is.Issues {
+
+		if o.s.DryRun == false {
+			if _, err := o.r.IssueWatcherDelete(i.ID, u.ID); err != nil {
+				return fmt.Errorf("issue %d: %w", i.ID, err)
+			}
+		}
+
+		rp.Watched = append(rp.Watched, i.ID)
+	}
+
+	return nil
+}
+
+func (o *Offboarder) groupsDelete(u redmine.UserObject, rp *Report) error {
+
+	if o.s.KeepMemberships == true || u.Groups == nil {
+		return nil
+	}
+
+	for _, g := range *u.Groups {
+
+		if o.s.DryRun == false {
+			if _, err := o.r.GroupDeleteUser(g.ID, u.ID); err != nil {
+				return fmt.Errorf("group %d: %w", g.ID, err)
+			}
+		}
+
+		rp.Groups = append(rp.Groups, g)
+	}
+
+	return nil
+}
+
+// membershipsDelete deletes memberships with own roles. Memberships with
+// only inherited roles are removed with user groups. Memberships keeping roles
+// inherited from parent projects can't be deleted, own roles are removed instead
+func (o *Offboarder) membershipsDelete(ms []redmine.MembershipObject, rp *Report) error {
+
+	for _, m := range ms {
+
+		mr := MembershipReport{
+			ID:        m.ID,
+			Project:   m.Project,
+			Inherited: true,
+		}
+
+		inherited := false
+
+		for _, rl := range m.Roles {
+			mr.Roles = append(mr.Roles, rl.Name)
+			if rl.Inherited == false {
+				mr.Inherited = false
+			} else {
+				inherited = true
+			}
+		}
+
+		if mr.Inherited == true {
+			rp.Memberships = append(rp.Memberships, mr)
+			continue
+		}
+
+		// Roles inherited from groups are removed with user groups, so membership
+		// is read again to check whether roles inherited from parent projects remain.
+		// In dry run mode inherited roles are not distinguished
+		if inherited == true && o.s.DryRun == false {
+
+			c, _, err := o.r.MembershipSingleGet(m.ID)
+			if err != nil {
+				return fmt.Errorf("membership %d: %w", m.ID, err)
+			}
+
+			inherited = slices.ContainsFunc(c.Roles, func(rl redmine.MembershipRoleObject) bool {
+				return rl.Inherited == true
+			})
+		}
+
+		mr.RolesCleared = inherited
+
+		if o.s.DryRun == false {
+			if inherited == true {
+				if _, err := o.r.MembershipUpdate(m.ID, redmine.MembershipUpdate{
+					Membership: redmine.MembershipUpdateObject{
+						RoleIDs: []int64{},
+					},
+				}); err != nil {
+					return fmt.Errorf("membership %d: %w", m.ID, err)
+				}
+			} else {
+				if _, err := o.r.MembershipDelete(m.ID); err != nil {
+					return fmt.Errorf("membership %d: %w", m.ID, err)
+				}
+			}
+		}
+
+		rp.Memberships = append(rp.Memberships, mr)
+	}
+
+	return nil
+}
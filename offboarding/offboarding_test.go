@@ -0,0 +1,184 @@
+package offboarding
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	redmine "github.com/nixys/nxs-go-redmine/v5"
+	"github.com/nixys/nxs-go-redmine/v5/redminetest"
+)
+
+func TestOffboard(t *testing.T) {
+
+	s := redminetest.Init(redminetest.Settings{})
+	defer s.Close()
+
+	r := s.Context()
+
+	jid, _ := s.UserAdd("jdoe", "John", "Doe", "jdoe@example.net")
+	aid, _ := s.UserAdd("asmith", "Alice", "Smith", "asmith@example.net")
+
+	g, _, err := r.GroupCreate(redmine.GroupCreate{Group: redmine.GroupCreateObject{Name: "Developers", UserIDs: &[]int64{jid}}})
+	if err != nil {
+		t.Fatal("Group create error:", err)
+	}
+
+	var ps []redmine.ProjectObject
+
+	for _, n := range []string{"acme", "beta"} {
+		p, _, err := r.ProjectCreate(redmine.ProjectCreate{Project: redmine.ProjectCreateObject{Name: n, Identifier: n}})
+		if err != nil {
+			t.Fatal("Project create error:", err)
+		}
+		ps = append(ps, p)
+	}
+
+	// Subproject membership has own roles and roles inherited from the parent project
+	if _, _, err := r.ProjectCreate(redmine.ProjectCreate{Project: redmine.ProjectCreateObject{
+		Name:           "acme-sub",
+		Identifier:     "acme-sub",
+		ParentID:       &ps[0].ID,
+		InheritMembers: redmine.BoolPtr(true),
+	}}); err != nil {
+		t.Fatal("Project create error:", err)
+	}
+
+	if _, _, err := r.MembershipAdd("acme-sub", redmine.MembershipAdd{
+		Membership: redmine.MembershipAddObject{
+			UserID:  jid,
+			RoleIDs: []int64{redminetest.RoleManagerID},
+		},
+	}); err != nil {
+		t.Fatal("Membership add error:", err)
+	}
+
+	for _, m := range []struct {
+		project   string
+		principal int64
+	}{
+		{"acme", jid},
+		{"acme", aid},
+		{"beta", g.ID},
+	} {
+		if _, _, err := r.MembershipAdd(m.project, redmine.MembershipAdd{
+			Membership: redmine.MembershipAddObject{
+				UserID:  m.principal,
+				RoleIDs: []int64{redminetest.RoleDeveloperID},
+			},
+		}); err != nil {
+			t.Fatal("Membership add error:", err)
+		}
+	}
+
+	var is []redmine.IssueObject
+
+	for _, i := range []redmine.IssueCreateObject{
+		{ProjectID: ps[0].ID, Subject: "Open", AssignedToID: &jid},
+		{ProjectID: ps[0].ID, Subject: "Closed", AssignedToID: &jid, StatusID: redmine.Int64Ptr(redminetest.StatusClosedID)},
+		{ProjectID: ps[1].ID, Subject: "Beta", AssignedToID: &jid},
+	} {
+		o, _, err := r.IssueCreate(redmine.IssueCreate{Issue: i})
+		if err != nil {
+			t.Fatal("Issue create error:", err)
+		}
+		is = append(is, o)
+	}
+
+	if _, err := r.IssueWatcherAdd(is[1].ID, jid); err != nil {
+		t.Fatal("Issue watcher add error:", err)
+	}
+
+	st := Settings{
+		ReassignToID:       aid,
+		ProjectReassignIDs: map[int64]int64{ps[1].ID: 0},
+		ReassignNotes:      "Reassigned on offboarding",
+		DryRun:             true,
+	}
+
+	// Dry run
+	rp, err := Init(r, st).Offboard(jid)
+	if err != nil {
+		t.Fatal("Offboard error:", err)
+	}
+
+	if rp.Locked == false || len(rp.Issues) != 2 || len(rp.Watched) != 1 || len(rp.Groups) != 1 || len(rp.Memberships) != 3 {
+		t.Fatalf("Offboard error: unexpected report: %+v", rp)
+	}
+
+	var b strings.Builder
+
+	if err := rp.Write(&b); err != nil {
+		t.Fatal("Report write error:", err)
+	}
+
+	if strings.Contains(b.String(), "Issues to reassign (2):") == false ||
+		strings.Contains(b.String(), "acme-sub: Manager, Developer (own roles to remove)") == false {
+		t.Fatal("Report write error: dry run report is not written as planned changes:", b.String())
+	}
+
+	u, _, err := r.UserSingleGet(jid, redmine.UserSingleGetRequest{Includes: []redmine.UserInclude{redmine.UserIncludeGroups}})
+	if err != nil {
+		t.Fatal("User get error:", err)
+	}
+
+	if *u.Status != redmine.UserStatusActive || len(*u.Groups) != 1 {
+		t.Fatal("Offboard error: user is changed on dry run")
+	}
+
+	// Offboard
+	st.DryRun = false
+
+	rp, err = Init(r, st).Offboard(jid)
+	if err != nil {
+		t.Fatal("Offboard error:", err)
+	}
+
+	b.Reset()
+
+	if err := rp.Write(&b); err != nil {
+		t.Fatal("Report write error:", err)
+	}
+
+	for _, e := range []string{
+		"Account locked",
+		fmt.Sprintf("#%d Open (acme) => user %d", is[0].ID, aid),
+		fmt.Sprintf("#%d Beta (beta) => nobody", is[2].ID),
+		"beta: Developer (via groups)",
+		"acme-sub: Manager, Developer (own roles removed)",
+	} {
+		if strings.Contains(b.String(), e) == false {
+			t.Fatalf("Report write error: %q is not in report:\n%s", e, b.String())
+		}
+	}
+
+	u, _, err = r.UserSingleGet(jid, redmine.UserSingleGetRequest{Includes: []redmine.UserInclude{redmine.UserIncludeGroups, redmine.UserIncludeMemberships}})
+	if err != nil {
+		t.Fatal("User get error:", err)
+	}
+
+	if *u.Status != redmine.UserStatusLocked || len(*u.Groups) != 0 || len(*u.Memberships) != 0 {
+		t.Fatalf("Offboard error: unexpected user: %+v", u)
+	}
+
+	for n, e := range map[int]int64{0: aid, 1: jid, 2: 0} {
+
+		i, _, err := r.IssueSingleGet(is[n].ID, redmine.IssueSingleGetRequest{Includes: []redmine.IssueInclude{redmine.IssueIncludeWatchers}})
+		if err != nil {
+			t.Fatal("Issue get error:", err)
+		}
+
+		var to int64
+		if i.AssignedTo != nil {
+			to = i.AssignedTo.ID
+		}
+
+		if to != e {
+			t.Fatalf("Offboard error: issue %d is assigned to %d, expected %d", i.ID, to, e)
+		}
+
+		if i.Watchers != nil && len(*i.Watchers) != 0 {
+			t.Fatalf("Offboard error: issue %d watchers are not removed", i.ID)
+		}
+	}
+}
@@ -0,0 +1,100 @@
+package offboarding
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	redmine "github.com/nixys/nxs-go-redmine/v5"
+)
+
+// Report contains changes made (or to be made in dry run mode) by user offboarding
+type Report struct {
+	User        redmine.IDName // User ID and login
+	DryRun      bool
+	Locked      bool // Account is locked. False if it was locked before
+	Issues      []IssueReport
+	Watched     []int64 // IDs of issues user is removed from watchers
+	Groups      []redmine.IDName
+	Memberships []MembershipReport
+}
+
+// IssueReport describes reassigned issue
+type IssueReport struct {
+	ID           int64
+	Subject      string
+	Project      redmine.IDName
+	AssignedToID int64 // Zero if issue is unassigned
+}
+
+// MembershipReport describes removed project membership
+type MembershipReport struct {
+	ID           int64
+	Project      redmine.IDName
+	Roles        []string
+	Inherited    bool // All roles are inherited from groups, membership is removed with user groups
+	RolesCleared bool // Membership keeps roles inherited from parent projects, only own roles are removed
+}
+
+// Write writes human-readable report grouped by offboarding steps. In dry run
+// mode steps are written as planned ones, e.g. `Issues to reassign`
+func (rp Report) Write(w io.Writer) error {
+
+	done := func(planned, made string) string {
+		if rp.DryRun == true {
+			return planned
+		}
+		return made
+	}
+
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "User %s (%d)\n", rp.User.Name, rp.User.ID)
+
+	if rp.Locked == true {
+		fmt.Fprintf(&b, "%s\n", done("Account to lock", "Account locked"))
+	}
+
+	if len(rp.Issues) > 0 {
+		fmt.Fprintf(&b, "%s (%d):\n", done("Issues to reassign", "Issues reassigned"), len(rp.Issues))
+		for _, i := range rp.Issues {
+			to := "nobody"
+			if i.AssignedToID != 0 {
+				to = fmt.Sprintf("user %d", i.AssignedToID)
+			}
+			fmt.Fprintf(&b, "  #%d %s (%s) => %s\n", i.ID, i.Subject, i.Project.Name, to)
+		}
+	}
+
+	if len(rp.Watched) > 0 {
+		fmt.Fprintf(&b, "%s (%d):\n", done("Watched issues to leave", "Watched issues left"), len(rp.Watched))
+		for _, id := range rp.Watched {
+			fmt.Fprintf(&b, "  #%d\n", id)
+		}
+	}
+
+	if len(rp.Groups) > 0 {
+		fmt.Fprintf(&b, "%s (%d):\n", done("Groups to leave", "Groups left"), len(rp.Groups))
+		for _, g := range rp.Groups {
+			fmt.Fprintf(&b, "  %s\n", g.Name)
+		}
+	}
+
+	if len(rp.Memberships) > 0 {
+		fmt.Fprintf(&b, "%s (%d):\n", done("Projects to leave", "Projects left"), len(rp.Memberships))
+		for _, m := range rp.Memberships {
+			fmt.Fprintf(&b, "  %s: %s", m.Project.Name, strings.Join(m.Roles, ", "))
+			if m.Inherited == true {
+				b.WriteString(" (via groups)")
+			}
+			if m.RolesCleared == true {
+				fmt.Fprintf(&b, " (%s)", done("own roles to remove", "own roles removed"))
+			}
+			b.WriteString("\n")
+		}
+	}
+
+	_, err := io.WriteString(w, b.String())
+
+	return err
+}
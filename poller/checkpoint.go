@@ -0,0 +1,112 @@
+package poller
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// Checkpoint contains poller position. Issues and journals
+// with IDs less or equal to saved ones are never emitted again
+type Checkpoint struct {
+	UpdatedOn time.Time `json:"updated_on"` // Latest `updated_on` value of processed issues
+	IssueID   int64     `json:"issue_id"`   // Greatest ID of issues emitted as created
+	JournalID int64     `json:"journal_id"` // Greatest ID of processed journals
+}
+
+// CheckpointStore is an interface to persist poller checkpoints between restarts
+type CheckpointStore interface {
+
+	// Load returns saved checkpoint or zero checkpoint if nothing has been saved yet
+	Load() (Checkpoint, error)
+
+	// Save saves specified checkpoint
+	Save(Checkpoint) error
+}
+
+// MemoryCheckpointStore stores checkpoint in memory
+type MemoryCheckpointStore struct {
+	mu sync.Mutex
+	cp Checkpoint
+}
+
+// FileCheckpointStore stores checkpoint in JSON file
+type FileCheckpointStore struct {
+	path string
+}
+
+// MemoryCheckpointStoreInit creates new in-memory checkpoint store
+func MemoryCheckpointStoreInit() *MemoryCheckpointStore {
+	return &MemoryCheckpointStore{}
+}
+
+// Load returns saved checkpoint
+func (s *MemoryCheckpointStore) Load() (Checkpoint, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.cp, nil
+}
+
+// Save saves checkpoint
+func (s *MemoryCheckpointStore) Save(cp Checkpoint) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.cp = cp
+	return nil
+}
+
+// FileCheckpointStoreInit creates new checkpoint store within file with specified path
+func FileCheckpointStoreInit(path string) *FileCheckpointStore {
+	return &FileCheckpointStore{
+		path: path,
+	}
+}
+
+// Load reads checkpoint from file. Zero checkpoint is returned if file does not exist
+func (s *FileCheckpointStore) Load() (Checkpoint, error) {
+
+	var cp Checkpoint
+
+	b, err := os.ReadFile(s.path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return cp, nil
+		}
+		return cp, err
+	}
+
+	if err := json.Unmarshal(b, &cp); err != nil {
+		return cp, err
+	}
+
+	return cp, nil
+}
+
+// Save writes checkpoint into file. File is replaced atomically
+func (s *FileCheckpointStore) Save(cp Checkpoint) error {
+
+	b, err := json.Marshal(cp)
+	if err != nil {
+		return err
+	}
+
+	f, err := os.CreateTemp(filepath.Dir(s.path), filepath.Base(s.path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+
+	if _, err := f.Write(b); err != nil {
+		f.Close()
+		return err
+	}
+
+	if err := f.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(f.Name(), s.path)
+}
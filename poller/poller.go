@@ -0,0 +1,315 @@
+package poller
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	redmine "github.com/nixys/nxs-go-redmine/v5"
+)
+
+const (
+	intervalDefault = time.Minute
+)
+
+// EventType defines poller event type
+type EventType string
+
+// EventType const
+const (
+	EventIssueCreated       EventType = "issue_created"
+	EventStatusChanged      EventType = "status_changed"
+	EventAssigned           EventType = "assigned"
+	EventCommented          EventType = "commented"
+	EventCustomFieldChanged EventType = "custom_field_changed"
+)
+
+// Settings contains data to init poller
+type Settings struct {
+	Interval     time.Duration           // Interval between polls. One minute if not set
+	ProjectID    string                  // Restrict polling to the project with specified ID or identifier (including subprojects)
+	Since        time.Time               // Moment events are emitted from while store has no checkpoint yet. Poller init time if not set
+	Checkpoints  CheckpointStore         // Store for poller checkpoints. In-memory store is used if not set
+	Decoder      *redmine.JournalDecoder // Decoder for journal details. Decoder without lookups is used if not set
+	ErrorHandler func(error)             // Called by Run() on poll errors. If not set Run() stops on first error
+}
+
+// Poller struct used for detecting changes of Redmine issues
+type Poller struct {
+	r            *redmine.Context
+	interval     time.Duration
+	projectID    string
+	since        time.Time
+	store        CheckpointStore
+	decoder      *redmine.JournalDecoder
+	errorHandler func(error)
+	handlers     []handler
+}
+
+// Event contains data of a single issue change
+type Event struct {
+	Type      EventType
+	Issue     redmine.IssueObject               // Issue state at the moment of poll
+	Journal   *redmine.IssueJournalObject       // Journal the event based on (nil for `EventIssueCreated`)
+	Detail    *redmine.IssueJournalDetailObject // Journal detail the event based on (set for status, assignee and custom field changes)
+	Change    redmine.JournalChange             // Decoded journal detail (set for status, assignee and custom field changes)
+	User      redmine.IDName                    // Author of the change
+	CreatedOn time.Time
+}
+
+// Handler is a function to process poller events.
+// If handler returns an error current poll is interrupted
+// and checkpoint is not advanced
+type Handler func(Event) error
+
+type handler struct {
+	types map[EventType]bool
+	h     Handler
+}
+
+// Init creates new poller
+func Init(r *redmine.Context, s Settings) *Poller {
+
+	p := &Poller{
+		r:            r,
+		interval:     s.Interval,
+		projectID:    s.ProjectID,
+		since:        s.Since,
+		store:        s.Checkpoints,
+		decoder:      s.Decoder,
+		errorHandler: s.ErrorHandler,
+	}
+
+	if p.interval == 0 {
+		p.interval = intervalDefault
+	}
+
+	if p.since.IsZero() {
+		p.since = time.Now()
+	}
+
+	if p.store == nil {
+		p.store = MemoryCheckpointStoreInit()
+	}
+
+	if p.decoder == nil {
+		p.decoder = redmine.JournalDecoderInit()
+	}
+
+	return p
+}
+
+// HandlerAdd registers handler for events with specified types.
+// If no types specified handler receives all events
+func (p *Poller) HandlerAdd(h Handler, types ...EventType) *Poller {
+
+	t := make(map[EventType]bool)
+	for _, e := range types {
+		t[e] = true
+	}
+
+	p.handlers = append(p.handlers, handler{
+		types: t,
+		h:     h,
+	})
+
+	return p
+}
+
+// Run polls Redmine with configured interval until context is done
+func (p *Poller) Run(ctx context.Context) error {
+
+	t := time.NewTicker(p.interval)
+	defer t.Stop()
+
+	for {
+
+		if err := p.Poll(); err != nil {
+			if p.errorHandler == nil {
+				return err
+			}
+			p.errorHandler(err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-t.C:
+		}
+	}
+}
+
+// Poll checks issues updated since last checkpoint, emits events
+// to registered handlers and saves a new checkpoint
+func (p *Poller) Poll() error {
+
+	cp, err := p.store.Load()
+	if err != nil {
+		return fmt.Errorf("poller: checkpoint load error: %w", err)
+	}
+
+	if cp.UpdatedOn.IsZero() {
+		cp.UpdatedOn = p.since
+	}
+
+	f := redmine.IssueGetRequestFiltersInit().
+		FieldAdd("status_id", "*").
+		FieldAdd("updated_on", ">="+cp.UpdatedOn.UTC().Format(time.RFC3339))
+
+	if p.projectID != "" {
+		f.FieldAdd("project_id", p.projectID)
+	}
+
+	issues, _, err := p.r.IssuesAllGet(
+		redmine.IssueAllGetRequest{
+			Sort:    redmine.IssueGetRequestSortInit().Set("updated_on", false),
+			Filters: f,
+		},
+	)
+	if err != nil {
+		return fmt.Errorf("poller: issues get error: %w", err)
+	}
+
+	next := cp
+
+	for _, e := range issues.Issues {
+
+		i, _, err := p.r.IssueSingleGet(
+			e.ID,
+			redmine.IssueSingleGetRequest{
+				Includes: []redmine.IssueInclude{
+					redmine.IssueIncludeJournals,
+				},
+			},
+		)
+		if err != nil {
+			return fmt.Errorf("poller: issue %d get error: %w", e.ID, err)
+		}
+
+		events, err := p.issueEvents(cp, &next, i)
+		if err != nil {
+			return fmt.Errorf("poller: issue %d: %w", e.ID, err)
+		}
+
+		for _, ev := range events {
+			if err := p.emit(ev); err != nil {
+				return fmt.Errorf("poller: issue %d: handler error: %w", e.ID, err)
+			}
+		}
+	}
+
+	if err := p.store.Save(next); err != nil {
+		return fmt.Errorf("poller: checkpoint save error: %w", err)
+	}
+
+	return nil
+}
+
+func (p *Poller) emit(e Event) error {
+
+	for _, h := range p.handlers {
+
+		if len(h.types) > 0 && h.types[e.Type] == false {
+			continue
+		}
+
+		if err := h.h(e); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// issueEvents makes events for changes of issue `i` that have been done
+// after checkpoint `cp` and moves checkpoint `next` forward
+func (p *Poller) issueEvents(cp Checkpoint, next *Checkpoint, i redmine.IssueObject) ([]Event, error) {
+
+	var events []Event
+
+	if i.UpdatedOn.After(next.UpdatedOn) {
+		next.UpdatedOn = i.UpdatedOn.Time
+	}
+
+	if i.ID > cp.IssueID && i.CreatedOn.Before(cp.UpdatedOn) == false {
+
+		events = append(events, Event{
+			Type:      EventIssueCreated,
+			Issue:     i,
+			User:      i.Author,
+			CreatedOn: i.CreatedOn.Time,
+		})
+
+		if i.ID > next.IssueID {
+			next.IssueID = i.ID
+		}
+	}
+
+	if i.Journals == nil {
+		return events, nil
+	}
+
+	js := *i.Journals
+
+	for k := range js {
+
+		j := &js[k]
+
+		if j.ID <= cp.JournalID {
+			continue
+		}
+
+		if j.CreatedOn.Before(cp.UpdatedOn) == true {
+			continue
+		}
+
+		if j.ID > next.JournalID {
+			next.JournalID = j.ID
+		}
+
+		for n := range j.Details {
+
+			var t EventType
+
+			d := &j.Details[n]
+
+			c, err := p.decoder.Decode(*d)
+			if err != nil {
+				return nil, fmt.Errorf("journal %d: %w", j.ID, err)
+			}
+
+			switch c.(type) {
+			case redmine.JournalStatusChange:
+				t = EventStatusChanged
+			case redmine.JournalAssigneeChange:
+				t = EventAssigned
+			case redmine.JournalCustomFieldChange:
+				t = EventCustomFieldChanged
+			default:
+				continue
+			}
+
+			events = append(events, Event{
+				Type:      t,
+				Issue:     i,
+				Journal:   j,
+				Detail:    d,
+				Change:    c,
+				User:      j.User,
+				CreatedOn: j.CreatedOn.Time,
+			})
+		}
+
+		if j.Notes != "" {
+			events = append(events, Event{
+				Type:      EventCommented,
+				Issue:     i,
+				Journal:   j,
+				User:      j.User,
+				CreatedOn: j.CreatedOn.Time,
+			})
+		}
+	}
+
+	return events, nil
+}
@@ -0,0 +1,124 @@
+package poller
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	redmine "github.com/nixys/nxs-go-redmine/v5"
+)
+
+const (
+	testIssuesJSON = `{"issues":[{"id":7,"subject":"Test issue","created_on":"2024-01-01T10:00:00Z","updated_on":"2024-01-01T12:00:00Z"}],"total_count":1,"offset":0,"limit":100}`
+	testIssueJSON  = `{"issue":{"id":7,"subject":"Test issue","author":{"id":1,"name":"Admin"},"created_on":"2024-01-01T10:00:00Z","updated_on":"2024-01-01T12:00:00Z","journals":[
+		{"id":11,"user":{"id":2,"name":"John"},"notes":"","created_on":"2023-12-31T10:00:00Z","details":[{"property":"attr","name":"status_id","old_value":"1","new_value":"2"}]},
+		{"id":12,"user":{"id":2,"name":"John"},"notes":"Done","created_on":"2024-01-01T11:00:00Z","details":[
+			{"property":"attr","name":"status_id","old_value":"1","new_value":"2"},
+			{"property":"attr","name":"assigned_to_id","old_value":null,"new_value":"3"},
+			{"property":"cf","name":"5","old_value":"a","new_value":"b"},
+			{"property":"attr","name":"subject","old_value":"Old","new_value":"Test issue"}
+		]}
+	]}}`
+)
+
+func TestPoller(t *testing.T) {
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch req.URL.Path {
+		case "/issues.json":
+			w.Write([]byte(testIssuesJSON))
+		case "/issues/7.json":
+			w.Write([]byte(testIssueJSON))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"errors":["not found"]}`))
+		}
+	}))
+	defer srv.Close()
+
+	r := redmine.Init(
+		redmine.Settings{
+			Endpoint: srv.URL,
+		},
+	)
+
+	store := FileCheckpointStoreInit(filepath.Join(t.TempDir(), "checkpoint.json"))
+
+	var events []Event
+
+	p := Init(
+		r,
+		Settings{
+			Since:       time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+			Checkpoints: store,
+		},
+	).HandlerAdd(func(e Event) error {
+		events = append(events, e)
+		return nil
+	})
+
+	if err := p.Poll(); err != nil {
+		t.Fatal("Poller poll error:", err)
+	}
+
+	expected := []EventType{
+		EventIssueCreated,
+		EventStatusChanged,
+		EventAssigned,
+		EventCustomFieldChanged,
+		EventCommented,
+	}
+
+	if len(events) != len(expected) {
+		t.Fatal("Poller poll error: incorrect events count:", len(events))
+	}
+
+	for i, e := range events {
+		if e.Type != expected[i] {
+			t.Fatal("Poller poll error: incorrect event type:", e.Type)
+		}
+	}
+
+	if events[1].Journal == nil || events[1].Journal.ID != 12 {
+		t.Fatal("Poller poll error: incorrect event journal")
+	}
+
+	if c, b := events[2].Change.(redmine.JournalAssigneeChange); b == false || c.New == nil || c.New.ID != 3 {
+		t.Fatal("Poller poll error: incorrect event change")
+	}
+
+	cp, err := store.Load()
+	if err != nil {
+		t.Fatal("Poller checkpoint load error:", err)
+	}
+
+	if cp.IssueID != 7 || cp.JournalID != 12 || cp.UpdatedOn.Equal(time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)) == false {
+		t.Fatal("Poller checkpoint error: incorrect checkpoint:", cp)
+	}
+
+	// Restart poller with the same store, nothing must be replayed
+	events = nil
+
+	p = Init(
+		r,
+		Settings{
+			Checkpoints: store,
+		},
+	).HandlerAdd(func(e Event) error {
+		events = append(events, e)
+		return nil
+	}, EventCommented)
+
+	if err := p.Poll(); err != nil {
+		t.Fatal("Poller poll error:", err)
+	}
+
+	if len(events) != 0 {
+		t.Fatal("Poller poll error: events have been replayed")
+	}
+
+	t.Logf("Poller poll: success")
+}
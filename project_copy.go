@@ -0,0 +1,538 @@
+package redmine
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// ProjectCopyPart defines project part replicated by `ProjectCopy`
+type ProjectCopyPart string
+
+// ProjectCopyPart const
+const (
+	ProjectCopyPartTrackers        ProjectCopyPart = "trackers"
+	ProjectCopyPartModules         ProjectCopyPart = "modules"
+	ProjectCopyPartMemberships     ProjectCopyPart = "memberships"
+	ProjectCopyPartIssueCategories ProjectCopyPart = "issue_categories"
+	ProjectCopyPartVersions        ProjectCopyPart = "versions"
+	ProjectCopyPartWiki            ProjectCopyPart = "wiki"
+	ProjectCopyPartIssues          ProjectCopyPart = "issues"
+)
+
+// ProjectCopyRequest contains data for making project copy
+type ProjectCopyRequest struct {
+
+	// New project attributes. Name and identifier are required,
+	// other unset attributes are taken from the source project
+	Project ProjectCreateObject
+
+	// Parts of the source project to replicate
+	Parts []ProjectCopyPart
+}
+
+// ProjectCopyResult stores project copy result
+type ProjectCopyResult struct {
+	Project ProjectObject
+
+	// Maps of source objects IDs to IDs of created ones
+	IssueCategoryIDs map[int64]int64
+	VersionIDs       map[int64]int64
+	IssueIDs         map[int64]int64
+}
+
+type projectCopy struct {
+	r     *Context
+	src   ProjectObject
+	parts map[ProjectCopyPart]bool
+	res   ProjectCopyResult
+
+	// Statuses of versions created as open to assign issues to them
+	versionStatuses map[int64]VersionStatus
+}
+
+func (p ProjectCopyPart) String() string {
+	return string(p)
+}
+
+// ProjectCopy creates new project from the source one with specified ID and replicates
+// selected parts through the API. Memberships are required to keep categories, versions
+// and issues assignees. Issues are created with the current user as author, their
+// relations, watchers, journals, time entries and attachments are not copied.
+//
+// Copying is not atomic: on error result contains the objects created so far,
+// so the new project may be deleted by the caller
+func (r *Context) ProjectCopy(sourceID string, request ProjectCopyRequest) (ProjectCopyResult, StatusCode, error) {
+
+	c := projectCopy{
+		r:               r,
+		parts:           make(map[ProjectCopyPart]bool),
+		versionStatuses: make(map[int64]VersionStatus),
+		res: ProjectCopyResult{
+			IssueCategoryIDs: make(map[int64]int64),
+			VersionIDs:       make(map[int64]int64),
+			IssueIDs:         make(map[int64]int64),
+		},
+	}
+
+	for _, p := range request.Parts {
+		c.parts[p] = true
+	}
+
+	src, s, err := r.ProjectSingleGet(
+		sourceID,
+		ProjectSingleGetRequest{
+			Includes: []ProjectInclude{
+				ProjectIncludeTrackers,
+				ProjectIncludeEnabledModules,
+				ProjectIncludeIssueCustomFields,
+			},
+		},
+	)
+	if err != nil {
+		return c.res, s, err
+	}
+
+	c.src = src
+
+	p, s, err := r.ProjectCreate(ProjectCreate{Project: c.projectCreateObject(request.Project)})
+	if err != nil {
+		return c.res, s, err
+	}
+
+	c.res.Project = p
+
+	steps := []struct {
+		part ProjectCopyPart
+		fn   func() (StatusCode, error)
+	}{
+		{ProjectCopyPartMemberships, c.memberships},
+		{ProjectCopyPartIssueCategories, c.issueCategories},
+		{ProjectCopyPartVersions, c.versions},
+		{"", c.defaults},
+		{ProjectCopyPartWiki, c.wiki},
+		{ProjectCopyPartIssues, c.issues},
+		{ProjectCopyPartVersions, c.versionsStatus},
+	}
+
+	for _, e := range steps {
+
+		if e.part != "" && c.parts[e.part] == false {
+			continue
+		}
+
+		if s, err := e.fn(); err != nil {
+			if e.part == "" {
+				return c.res, s, fmt.Errorf("project copy: %w", err)
+			}
+			return c.res, s, fmt.Errorf("project copy %s: %w", e.part, err)
+		}
+	}
+
+	return c.res, s, nil
+}
+
+// projectCreateObject fills unset attributes of new project from the source one
+func (c *projectCopy) projectCreateObject(o ProjectCreateObject) ProjectCreateObject {
+
+	if o.Description == nil {
+		o.Description = StringPtr(c.src.Description)
+	}
+
+	if o.Homepage == nil {
+		o.Homepage = c.src.Homepage
+	}
+
+	if o.IsPublic == nil {
+		o.IsPublic = BoolPtr(c.src.IsPublic)
+	}
+
+	if o.ParentID == nil && c.src.Parent.ID != 0 {
+		o.ParentID = Int64Ptr(c.src.Parent.ID)
+	}
+
+	if o.InheritMembers == nil {
+		o.InheritMembers = BoolPtr(c.src.InheritMembers)
+	}
+
+	if o.TrackerIDs == nil && c.parts[ProjectCopyPartTrackers] == true && c.src.Trackers != nil {
+		ids := []int64{}
+		for _, t := range *c.src.Trackers {
+			ids = append(ids, t.ID)
+		}
+		o.TrackerIDs = &ids
+	}
+
+	if o.EnabledModuleNames == nil && c.parts[ProjectCopyPartModules] == true && c.src.EnabledModules != nil {
+		names := []string{}
+		for _, m := range *c.src.EnabledModules {
+			names = append(names, m.Name)
+		}
+		o.EnabledModuleNames = &names
+	}
+
+	if o.IssueCustomFieldIDs == nil && c.src.IssueCustomFields != nil {
+		ids := []int64{}
+		for _, f := range *c.src.IssueCustomFields {
+			ids = append(ids, f.ID)
+		}
+		o.IssueCustomFieldIDs = &ids
+	}
+
+	if o.CustomFields == nil && len(c.src.CustomFields) > 0 {
+		o.CustomFields = customFieldsCopy(c.src.CustomFields)
+	}
+
+	return o
+}
+
+func (c *projectCopy) memberships() (StatusCode, error) {
+
+	ms, s, err := c.r.MembershipAllGet(c.srcID())
+	if err != nil {
+		return s, err
+	}
+
+	for _, m := range ms.Memberships {
+
+		// Inherited roles are granted by parent project
+		var roles []int64
+		for _, r := range m.Roles {
+			if r.Inherited == false {
+				roles = append(roles, r.ID)
+			}
+		}
+
+		if len(roles) == 0 {
+			continue
+		}
+
+		var id int64
+
+		switch {
+		case m.User != nil:
+			id = m.User.ID
+		case m.Group != nil:
+			id = m.Group.ID
+		default:
+			continue
+		}
+
+		if _, s, err := c.r.MembershipAdd(
+			c.dstID(),
+			MembershipAdd{
+				Membership: MembershipAddObject{
+					UserID:  id,
+					RoleIDs: roles,
+				},
+			},
+		); err != nil {
+			return s, err
+		}
+	}
+
+	return s, nil
+}
+
+func (c *projectCopy) issueCategories() (StatusCode, error) {
+
+	cs, s, err := c.r.IssueCategoryAllGet(c.srcID())
+	if err != nil {
+		return s, err
+	}
+
+	for _, e := range cs.IssueCategories {
+
+		o := IssueCategoryCreateObject{
+			Name: e.Name,
+		}
+
+		if e.AssignedTo != nil && c.parts[ProjectCopyPartMemberships] == true {
+			o.AssignedToID = Int64Ptr(e.AssignedTo.ID)
+		}
+
+		n, s, err := c.r.IssueCategoryCreate(c.dstID(), IssueCategoryCreate{IssueCategory: o})
+		if err != nil {
+			return s, err
+		}
+
+		c.res.IssueCategoryIDs[e.ID] = n.ID
+	}
+
+	return s, nil
+}
+
+func (c *projectCopy) versions() (StatusCode, error) {
+
+	vs, s, err := c.r.VersionAllGet(c.srcID())
+	if err != nil {
+		return s, err
+	}
+
+	for _, v := range vs.Versions {
+
+		// Skip versions shared by other projects
+		if v.Project.ID != c.src.ID {
+			continue
+		}
+
+		o := VersionCreateObject{
+			Name:         v.Name,
+			Description:  StringPtr(v.Description),
+			DueDate:      v.DueDate,
+			CustomFields: customFieldsCopy(v.CustomFields),
+		}
+
+		// Issues can be assigned only to open versions, so versions
+		// statuses are set after issues are copied
+		if v.Status != "" && v.Status != VersionStatusOpen {
+			c.versionStatuses[v.ID] = v.Status
+		}
+
+		if v.Sharing != "" {
+			sh := v.Sharing
+			o.Sharing = &sh
+		}
+
+		if v.WikiPageTitle != "" {
+			o.WikiPageTitle = StringPtr(v.WikiPageTitle)
+		}
+
+		n, s, err := c.r.VersionCreate(c.dstID(), VersionCreate{Version: o})
+		if err != nil {
+			return s, err
+		}
+
+		c.res.VersionIDs[v.ID] = n.ID
+	}
+
+	return s, nil
+}
+
+// versionsStatus sets statuses of copied versions
+func (c *projectCopy) versionsStatus() (StatusCode, error) {
+
+	var (
+		s   StatusCode
+		err error
+	)
+
+	for id, st := range c.versionStatuses {
+
+		st := st
+
+		s, err = c.r.VersionUpdate(
+			c.res.VersionIDs[id],
+			VersionUpdate{
+				Version: VersionUpdateObject{
+					Status: &st,
+				},
+			},
+		)
+		if err != nil {
+			return s, err
+		}
+	}
+
+	return s, nil
+}
+
+// defaults sets new project default version and assignee
+// if corresponding parts are copied
+func (c *projectCopy) defaults() (StatusCode, error) {
+
+	var (
+		o   ProjectUpdateObject
+		upd bool
+	)
+
+	if c.src.DefaultVersion != nil {
+		if id, b := c.res.VersionIDs[c.src.DefaultVersion.ID]; b == true {
+			o.DefaultVersionID = Int64Ptr(id)
+			upd = true
+		}
+	}
+
+	if c.src.DefaultAssignee != nil && c.parts[ProjectCopyPartMemberships] == true {
+		o.DefaultAssignedToID = Int64Ptr(c.src.DefaultAssignee.ID)
+		upd = true
+	}
+
+	if upd == false {
+		return 0, nil
+	}
+
+	return c.r.ProjectUpdate(c.dstID(), ProjectUpdate{Project: o})
+}
+
+func (c *projectCopy) wiki() (StatusCode, error) {
+
+	ws, s, err := c.r.WikiAllGet(c.srcID())
+	if err != nil {
+		return s, err
+	}
+
+	created := make(map[string]bool)
+
+	// Parent pages must be created before their children
+	for len(created) < len(ws) {
+
+		n := len(created)
+
+		for _, w := range ws {
+
+			if created[w.Title] == true {
+				continue
+			}
+
+			if w.Parent != nil && created[w.Parent.Title] == false {
+				continue
+			}
+
+			p, s, err := c.r.WikiSingleGet(c.srcID(), w.Title, WikiSingleGetRequest{})
+			if err != nil {
+				return s, err
+			}
+
+			o := WikiCreateObject{
+				Text: p.Text,
+			}
+
+			if p.Comments != "" {
+				o.Comments = StringPtr(p.Comments)
+			}
+
+			if w.Parent != nil {
+				o.ParentTitle = StringPtr(w.Parent.Title)
+			}
+
+			if _, s, err := c.r.WikiCreate(c.dstID(), w.Title, WikiCreate{WikiPage: o}); err != nil {
+				return s, err
+			}
+
+			created[w.Title] = true
+		}
+
+		// Remaining pages have parents absent in the list
+		if len(created) == n {
+			return s, fmt.Errorf("wiki pages parents are not found")
+		}
+	}
+
+	return s, nil
+}
+
+func (c *projectCopy) issues() (StatusCode, error) {
+
+	is, s, err := c.r.IssuesAllGet(
+		IssueAllGetRequest{
+			Sort: IssueGetRequestSortInit().Set("id", false),
+			Filters: IssueGetRequestFiltersInit().
+				Add(IssueFilterFieldProjectID, FilterOperatorEqual, strconv.FormatInt(c.src.ID, 10)).
+				Add(IssueFilterFieldSubprojectID, FilterOperatorNone).
+				Add(IssueFilterFieldStatusID, FilterOperatorAny),
+		},
+	)
+	if err != nil {
+		return s, err
+	}
+
+	var parents []IssueObject
+
+	for _, i := range is.Issues {
+
+		o := IssueCreateObject{
+			ProjectID:      c.res.Project.ID,
+			TrackerID:      Int64Ptr(i.Tracker.ID),
+			StatusID:       Int64Ptr(i.Status.ID),
+			PriorityID:     Int64Ptr(i.Priority.ID),
+			Subject:        i.Subject,
+			Description:    StringPtr(i.Description),
+			StartDate:      i.StartDate,
+			DueDate:        i.DueDate,
+			IsPrivate:      BoolPtr(i.IsPrivate),
+			EstimatedHours: i.EstimatedHours,
+			CustomFields:   customFieldsCopy(i.CustomFields),
+		}
+
+		if i.Category != nil {
+			if id, b := c.res.IssueCategoryIDs[i.Category.ID]; b == true {
+				o.CategoryID = Int64Ptr(id)
+			}
+		}
+
+		if i.FixedVersion != nil {
+			if id, b := c.res.VersionIDs[i.FixedVersion.ID]; b == true {
+				o.FixedVersionID = Int64Ptr(id)
+			}
+		}
+
+		if i.AssignedTo != nil && c.parts[ProjectCopyPartMemberships] == true {
+			o.AssignedToID = Int64Ptr(i.AssignedTo.ID)
+		}
+
+		if i.Parent != nil {
+			if id, b := c.res.IssueIDs[i.Parent.ID]; b == true {
+				o.ParentIssueID = Int64Ptr(id)
+			} else {
+				parents = append(parents, i)
+			}
+		}
+
+		n, s, err := c.r.IssueCreate(IssueCreate{Issue: o})
+		if err != nil {
+			return s, fmt.Errorf("issue %d: %w", i.ID, err)
+		}
+
+		c.res.IssueIDs[i.ID] = n.ID
+	}
+
+	// Set parents created after their children.
+	// Parents from other projects are not kept
+	for _, i := range parents {
+
+		id, b := c.res.IssueIDs[i.Parent.ID]
+		if b == false {
+			continue
+		}
+
+		if s, err := c.r.IssueUpdate(
+			c.res.IssueIDs[i.ID],
+			IssueUpdate{
+				Issue: IssueUpdateObject{
+					ParentIssueID: Int64Ptr(id),
+				},
+			},
+		); err != nil {
+			return s, fmt.Errorf("issue %d: %w", i.ID, err)
+		}
+	}
+
+	return s, nil
+}
+
+func (c *projectCopy) srcID() string {
+	return strconv.FormatInt(c.src.ID, 10)
+}
+
+func (c *projectCopy) dstID() string {
+	return strconv.FormatInt(c.res.Project.ID, 10)
+}
+
+// customFieldsCopy converts got custom fields into update objects
+func customFieldsCopy(cfs []CustomFieldGetObject) *[]CustomFieldUpdateObject {
+
+	if len(cfs) == 0 {
+		return nil
+	}
+
+	var us []CustomFieldUpdateObject
+
+	for _, f := range cfs {
+		us = append(us, CustomFieldUpdateObject{
+			ID:    f.ID,
+			Value: f.Value,
+		})
+	}
+
+	return &us
+}
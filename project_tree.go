@@ -0,0 +1,138 @@
+package redmine
+
+// ProjectTree contains projects hierarchy built from projects list
+type ProjectTree struct {
+	roots []*ProjectTreeNode
+	nodes map[int64]*ProjectTreeNode
+}
+
+// ProjectTreeNode contains project and its place within hierarchy
+type ProjectTreeNode struct {
+	Project  ProjectObject
+	Parent   *ProjectTreeNode
+	Children []*ProjectTreeNode
+}
+
+// ProjectTreeBuild builds projects hierarchy using `ProjectObject.Parent`.
+// Projects with parents absent in the list (e.g. not visible for the user)
+// become roots. Children order is the same as in the list
+func ProjectTreeBuild(projects []ProjectObject) ProjectTree {
+
+	t := ProjectTree{
+		nodes: make(map[int64]*ProjectTreeNode),
+	}
+
+	for _, p := range projects {
+		t.nodes[p.ID] = &ProjectTreeNode{
+			Project: p,
+		}
+	}
+
+	for _, p := range projects {
+
+		n := t.nodes[p.ID]
+
+		pn, b := t.nodes[p.Parent.ID]
+		if p.Parent.ID == 0 || b == false || t.cycle(pn, n) == true {
+			t.roots = append(t.roots, n)
+			continue
+		}
+
+		n.Parent = pn
+		pn.Children = append(pn.Children, n)
+	}
+
+	return t
+}
+
+// Roots returns top level projects nodes
+func (t ProjectTree) Roots() []*ProjectTreeNode {
+	return t.roots
+}
+
+// Node returns project node by project ID
+func (t ProjectTree) Node(id int64) (*ProjectTreeNode, bool) {
+	n, b := t.nodes[id]
+	return n, b
+}
+
+// Subtree returns project with specified ID and all its descendants in depth-first order
+func (t ProjectTree) Subtree(id int64) []ProjectObject {
+
+	n, b := t.nodes[id]
+	if b == false {
+		return nil
+	}
+
+	return append([]ProjectObject{n.Project}, t.Descendants(id)...)
+}
+
+// Descendants returns all descendants of project with specified ID in depth-first order
+func (t ProjectTree) Descendants(id int64) []ProjectObject {
+
+	n, b := t.nodes[id]
+	if b == false {
+		return nil
+	}
+
+	var ps []ProjectObject
+
+	for _, c := range n.Children {
+		ps = append(ps, c.Project)
+		ps = append(ps, t.Descendants(c.Project.ID)...)
+	}
+
+	return ps
+}
+
+// Ancestors returns ancestors of project with specified ID starting from the root
+func (t ProjectTree) Ancestors(id int64) []ProjectObject {
+
+	n, b := t.nodes[id]
+	if b == false {
+		return nil
+	}
+
+	var ps []ProjectObject
+
+	for p := n.Parent; p != nil; p = p.Parent {
+		ps = append([]ProjectObject{p.Project}, ps...)
+	}
+
+	return ps
+}
+
+// Walk calls `fn` for every project in depth-first order with project depth
+// (0 for roots). Walk stops if `fn` returns false
+func (t ProjectTree) Walk(fn func(p ProjectObject, depth int) bool) {
+	for _, r := range t.roots {
+		if t.walk(r, 0, fn) == false {
+			return
+		}
+	}
+}
+
+func (t ProjectTree) walk(n *ProjectTreeNode, depth int, fn func(p ProjectObject, depth int) bool) bool {
+
+	if fn(n.Project, depth) == false {
+		return false
+	}
+
+	for _, c := range n.Children {
+		if t.walk(c, depth+1, fn) == false {
+			return false
+		}
+	}
+
+	return true
+}
+
+// cycle checks linking node `n` to parent `p` creates a cycle
+func (t ProjectTree) cycle(p, n *ProjectTreeNode) bool {
+	for ; p != nil; p = p.Parent {
+		if p == n {
+			return true
+		}
+	}
+	return false
+}
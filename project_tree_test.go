@@ -0,0 +1,74 @@
+package redmine
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestProjectTree(t *testing.T) {
+
+	project := func(id, parentID int64) ProjectObject {
+		return ProjectObject{
+			ID:     id,
+			Parent: IDName{ID: parentID},
+		}
+	}
+
+	ids := func(ps []ProjectObject) []int64 {
+		var r []int64
+		for _, p := range ps {
+			r = append(r, p.ID)
+		}
+		return r
+	}
+
+	// Project 6 has invisible parent, projects 7 and 8 are linked into a cycle
+	tree := ProjectTreeBuild([]ProjectObject{
+		project(4, 2),
+		project(1, 0),
+		project(2, 1),
+		project(3, 1),
+		project(5, 4),
+		project(6, 100),
+		project(7, 8),
+		project(8, 7),
+	})
+
+	var roots []int64
+	for _, n := range tree.Roots() {
+		roots = append(roots, n.Project.ID)
+	}
+
+	if slices.Equal(roots, []int64{1, 6, 8}) == false {
+		t.Fatal("Project tree error: unexpected roots:", roots)
+	}
+
+	for _, e := range []struct {
+		name     string
+		got, exp []int64
+	}{
+		{"subtree", ids(tree.Subtree(2)), []int64{2, 4, 5}},
+		{"descendants", ids(tree.Descendants(1)), []int64{2, 4, 5, 3}},
+		{"ancestors", ids(tree.Ancestors(5)), []int64{1, 2, 4}},
+		{"ancestors of root", ids(tree.Ancestors(1)), nil},
+		{"unknown project", ids(tree.Subtree(100)), nil},
+	} {
+		if slices.Equal(e.got, e.exp) == false {
+			t.Fatalf("Project tree error: %s: expected %v, got %v", e.name, e.exp, e.got)
+		}
+	}
+
+	if n, b := tree.Node(5); b == false || n.Parent.Project.ID != 4 {
+		t.Fatal("Project tree error: unexpected node parent")
+	}
+
+	var depths []int
+	tree.Walk(func(p ProjectObject, depth int) bool {
+		depths = append(depths, depth)
+		return p.ID != 3
+	})
+
+	if slices.Equal(depths, []int{0, 1, 2, 3, 1}) == false {
+		t.Fatal("Project tree error: unexpected walk depths:", depths)
+	}
+}
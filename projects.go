@@ -1,6 +1,8 @@
 package redmine
 
 import (
+	"encoding/json"
+	"fmt"
 	"net/http"
 	"net/url"
 	"strconv"
@@ -48,8 +50,8 @@ type ProjectObject struct {
 	TimeEntryActivities *[]IDName              `json:"time_entry_activities"` // used only: include specified
 	EnabledModules      *[]IDName              `json:"enabled_modules"`       // used only: include specified
 	IssueCustomFields   *[]IDName              `json:"issue_custom_fields"`   // used only: include specified
-	CreatedOn           string                 `json:"created_on"`
-	UpdatedOn           string                 `json:"updated_on"`
+	CreatedOn           DateTime               `json:"created_on"`
+	UpdatedOn           DateTime               `json:"updated_on"`
 }
 
 /* Create */
@@ -88,6 +90,7 @@ type ProjectUpdateObject struct {
 	Homepage            *string                    `json:"homepage,omitempty"`
 	IsPublic            *bool                      `json:"is_public,omitempty"`
 	ParentID            *int64                     `json:"parent_id,omitempty"`
+	Unparent            bool                       `json:"-"` // Move project to the top level, `ParentID` is ignored
 	InheritMembers      *bool                      `json:"inherit_members,omitempty"`
 	DefaultAssignedToID *int64                     `json:"default_assigned_to_id,omitempty"`
 	DefaultVersionID    *int64                     `json:"default_version_id,omitempty"`
@@ -101,12 +104,14 @@ type ProjectUpdateObject struct {
 
 // ProjectAllGetRequest contains data for making request to get all projects satisfying specified filters
 type ProjectAllGetRequest struct {
+	Sort     *ProjectGetRequestSort
 	Includes []ProjectInclude
 	Filters  *ProjectGetRequestFilters
 }
 
 // ProjectMultiGetRequest contains data for making request to get limited projects count satisfying specified filters
 type ProjectMultiGetRequest struct {
+	Sort     *ProjectGetRequestSort
 	Includes []ProjectInclude
 	Filters  *ProjectGetRequestFilters
 	Offset   int64
@@ -120,7 +125,9 @@ type ProjectSingleGetRequest struct {
 
 // ProjectGetRequestFilters contains data for making projects get request
 type ProjectGetRequestFilters struct {
-	status *ProjectStatus
+	status  *ProjectStatus
+	filters map[string]filter
+	err     error
 }
 
 /* Results */
@@ -170,6 +177,10 @@ func (r *Context) ProjectAllGet(request ProjectAllGetRequest) (ProjectResult, St
 		status   StatusCode
 	)
 
+	if request.Filters != nil && request.Filters.err != nil {
+		return projects, 0, request.Filters.err
+	}
+
 	up := request.url()
 	up.Set("limit", strconv.FormatInt(limitDefault, 10))
 
@@ -215,6 +226,10 @@ func (r *Context) ProjectMultiGet(request ProjectMultiGetRequest) (ProjectResult
 
 	var p ProjectResult
 
+	if request.Filters != nil && request.Filters.err != nil {
+		return p, 0, request.Filters.err
+	}
+
 	s, err := r.Get(
 		&p,
 		url.URL{
@@ -316,6 +331,40 @@ func (r *Context) ProjectUnarchive(id string) (StatusCode, error) {
 	return status, err
 }
 
+// ProjectClose closes a project (available since Redmine 5.1)
+//
+// see: https://www.redmine.org/projects/redmine/wiki/Rest_Projects#Closing-a-project
+func (r *Context) ProjectClose(id string) (StatusCode, error) {
+
+	status, err := r.Put(
+		nil,
+		nil,
+		url.URL{
+			Path: "/projects/" + id + "/close.json",
+		},
+		http.StatusNoContent,
+	)
+
+	return status, err
+}
+
+// ProjectReopen reopens a closed project (available since Redmine 5.1)
+//
+// see: https://www.redmine.org/projects/redmine/wiki/Rest_Projects#Reopening-a-project
+func (r *Context) ProjectReopen(id string) (StatusCode, error) {
+
+	status, err := r.Put(
+		nil,
+		nil,
+		url.URL{
+			Path: "/projects/" + id + "/reopen.json",
+		},
+		http.StatusNoContent,
+	)
+
+	return status, err
+}
+
 // ProjectDelete deletes project with specified ID
 //
 // see: https://www.redmine.org/projects/redmine/wiki/Rest_Projects#Deleting-a-project
@@ -337,6 +386,10 @@ func (pr ProjectAllGetRequest) url() url.Values {
 
 	v := url.Values{}
 
+	if pr.Sort != nil {
+		pr.Sort.url(&v)
+	}
+
 	if len(pr.Includes) > 0 {
 		v.Set(
 			"include",
@@ -364,6 +417,10 @@ func (pr ProjectMultiGetRequest) url() url.Values {
 
 	v := url.Values{}
 
+	if pr.Sort != nil {
+		pr.Sort.url(&v)
+	}
+
 	if len(pr.Includes) > 0 {
 		v.Set(
 			"include",
@@ -413,8 +470,28 @@ func (pr ProjectSingleGetRequest) url() url.Values {
 	return v
 }
 
+// MarshalJSON encodes project update. Redmine rejects zero parent ID,
+// so empty `parent_id` is sent to move project to the top level if `Unparent` is set
+func (o ProjectUpdateObject) MarshalJSON() ([]byte, error) {
+
+	type object ProjectUpdateObject
+
+	if o.Unparent == false {
+		return json.Marshal(object(o))
+	}
+
+	return json.Marshal(struct {
+		object
+		ParentID string `json:"parent_id"`
+	}{
+		object: object(o),
+	})
+}
+
 func ProjectGetRequestFiltersInit() *ProjectGetRequestFilters {
-	return &ProjectGetRequestFilters{}
+	return &ProjectGetRequestFilters{
+		filters: make(map[string]filter),
+	}
 }
 
 func (f *ProjectGetRequestFilters) StatusSet(status ProjectStatus) *ProjectGetRequestFilters {
@@ -422,8 +499,69 @@ func (f *ProjectGetRequestFilters) StatusSet(status ProjectStatus) *ProjectGetRe
 	return f
 }
 
+// ParentIDSet sets filter for subprojects of specified projects
+func (f *ProjectGetRequestFilters) ParentIDSet(ids ...int64) *ProjectGetRequestFilters {
+
+	var vs []string
+
+	for _, id := range ids {
+		vs = append(vs, strconv.FormatInt(id, 10))
+	}
+
+	return f.Add(ProjectFilterFieldParentID, FilterOperatorEqual, vs...)
+}
+
+// IsPublicSet sets filter for public or private projects
+func (f *ProjectGetRequestFilters) IsPublicSet(public bool) *ProjectGetRequestFilters {
+	if public == true {
+		return f.Add(ProjectFilterFieldIsPublic, FilterOperatorEqual, "1")
+	}
+	return f.Add(ProjectFilterFieldIsPublic, FilterOperatorEqual, "0")
+}
+
+// Add adds filter for standard field with specified operator, e.g.:
+// `Add(ProjectFilterFieldName, FilterOperatorContains, "web")`.
+// See `IssueGetRequestFilters.Add` for details
+func (f *ProjectGetRequestFilters) Add(field ProjectFilterField, op FilterOperator, values ...string) *ProjectGetRequestFilters {
+	return f.filterAdd(field.String(), projectFilterTypes[field], op, values)
+}
+
+// CustomFieldFilterAdd adds filter for project custom field with specified operator
+func (f *ProjectGetRequestFilters) CustomFieldFilterAdd(id int64, op FilterOperator, values ...string) *ProjectGetRequestFilters {
+	return f.filterAdd("cf_"+strconv.FormatInt(id, 10), filterTypeAny, op, values)
+}
+
+// Err returns the first error occurred while filters adding
+func (f *ProjectGetRequestFilters) Err() error {
+	return f.err
+}
+
+func (f *ProjectGetRequestFilters) filterAdd(field string, t filterType, op FilterOperator, values []string) *ProjectGetRequestFilters {
+
+	e, err := filterInit(t, op, values)
+	if err != nil {
+		if f.err == nil {
+			f.err = fmt.Errorf("project filter `%s`: %w", field, err)
+		}
+		return f
+	}
+
+	if f.filters == nil {
+		f.filters = make(map[string]filter)
+	}
+
+	f.filters[field] = e
+
+	return f
+}
+
 func (f *ProjectGetRequestFilters) url(v *url.Values) {
+
 	if f.status != nil {
 		v.Set("status", strconv.FormatInt(int64(*f.status), 10))
 	}
+
+	for n, e := range f.filters {
+		v.Set(n, e.expression())
+	}
 }
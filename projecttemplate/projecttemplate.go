@@ -0,0 +1,443 @@
+package projecttemplate
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	redmine "github.com/nixys/nxs-go-redmine/v5"
+)
+
+// Engine instantiates projects from specs
+type Engine struct {
+	r  *redmine.Context
+	rs *redmine.Resolver
+}
+
+// Result contains objects created by spec instantiation
+type Result struct {
+	Project       redmine.ProjectObject
+	MembershipIDs []int64
+	CategoryIDs   map[string]int64 // Issue categories IDs by names from the spec
+	VersionIDs    map[string]int64 // Versions IDs by names from the spec
+	WikiPages     []string         // Created wiki pages titles
+}
+
+// instance contains state of single spec instantiation
+type instance struct {
+	e    *Engine
+	spec Spec
+	res  Result
+
+	// Resolved IDs
+	parentID          *int64
+	trackerIDs        *[]int64
+	defaultAssigneeID *int64
+	principalIDs      []int64  // Aligned with spec memberships
+	categoryAssignees []*int64 // Aligned with spec categories
+
+	undo []undoStep
+}
+
+type undoStep struct {
+	name string
+	fn   func() error
+}
+
+// Init creates new engine
+func Init(r *redmine.Context) *Engine {
+	return &Engine{
+		r:  r,
+		rs: redmine.ResolverInit(r),
+	}
+}
+
+// Instantiate creates project with all objects described in spec. All names
+// are resolved before any object is created. If any step fails, objects
+// created so far are deleted in reverse order. Rollback errors are joined
+// with the step error
+func (e *Engine) Instantiate(s Spec) (Result, error) {
+
+	if err := s.Validate(); err != nil {
+		return Result{}, err
+	}
+
+	in := &instance{
+		e:    e,
+		spec: s,
+		res: Result{
+			CategoryIDs: make(map[string]int64),
+			VersionIDs:  make(map[string]int64),
+		},
+	}
+
+	if err := in.resolve(); err != nil {
+		return Result{}, fmt.Errorf("projecttemplate: %w", err)
+	}
+
+	steps := []struct {
+		name string
+		fn   func() error
+	}{
+		{"project", in.project},
+		{"memberships", in.memberships},
+		{"categories", in.categories},
+		{"versions", in.versions},
+		{"project defaults", in.defaults},
+		{"wiki pages", in.wikiPages},
+	}
+
+	for _, st := range steps {
+		if err := st.fn(); err != nil {
+
+			err = fmt.Errorf("projecttemplate: %s: %w", st.name, err)
+
+			if rerr := in.rollback(); rerr != nil {
+				return Result{}, errors.Join(err, rerr)
+			}
+
+			return Result{}, err
+		}
+	}
+
+	return in.res, nil
+}
+
+// resolve converts names from the spec into IDs
+func (in *instance) resolve() error {
+
+	var err error
+
+	p := in.spec.Project
+
+	if p.Parent != "" {
+		id, err := in.e.rs.ProjectID(p.Parent)
+		if err != nil {
+			return err
+		}
+		in.parentID = &id
+	}
+
+	if len(p.Trackers) > 0 {
+		ids := []int64{}
+		for _, t := range p.Trackers {
+			id, err := in.e.rs.TrackerID(t)
+			if err != nil {
+				return err
+			}
+			ids = append(ids, id)
+		}
+		in.trackerIDs = &ids
+	}
+
+	if p.DefaultAssignee != "" {
+		id, err := in.e.rs.UserID(p.DefaultAssignee)
+		if err != nil {
+			return err
+		}
+		in.defaultAssigneeID = &id
+	}
+
+	var groups []redmine.GroupObject
+
+	for _, m := range in.spec.Memberships {
+
+		var id int64
+
+		if m.User != "" {
+			id, err = in.e.rs.UserID(m.User)
+		} else {
+			if groups == nil {
+				g, _, err := in.e.r.GroupAllGet()
+				if err != nil {
+					return err
+				}
+				groups = g.Groups
+			}
+			id, err = groupID(groups, m.Group)
+		}
+		if err != nil {
+			return err
+		}
+
+		in.principalIDs = append(in.principalIDs, id)
+	}
+
+	for _, c := range in.spec.Categories {
+
+		if c.AssignedTo == "" {
+			in.categoryAssignees = append(in.categoryAssignees, nil)
+			continue
+		}
+
+		id, err := in.e.rs.UserID(c.AssignedTo)
+		if err != nil {
+			return err
+		}
+
+		in.categoryAssignees = append(in.categoryAssignees, &id)
+	}
+
+	return nil
+}
+
+func (in *instance) project() error {
+
+	p := in.spec.Project
+
+	o := redmine.ProjectCreateObject{
+		Name:           p.Name,
+		Identifier:     p.Identifier,
+		IsPublic:       p.IsPublic,
+		InheritMembers: p.InheritMembers,
+		ParentID:       in.parentID,
+		TrackerIDs:     in.trackerIDs,
+	}
+
+	if p.Description != "" {
+		o.Description = redmine.StringPtr(p.Description)
+	}
+
+	if p.Homepage != "" {
+		o.Homepage = redmine.StringPtr(p.Homepage)
+	}
+
+	if len(p.Modules) > 0 {
+		ms := append([]string{}, p.Modules...)
+		o.EnabledModuleNames = &ms
+	}
+
+	if len(p.CustomFields) > 0 {
+		cfs := []redmine.CustomFieldUpdateObject{}
+		for _, c := range p.CustomFields {
+			v := redmine.CustomFieldValueInit(c.Value)
+			if len(c.Values) > 0 {
+				v = redmine.CustomFieldValuesInit(c.Values)
+			}
+			cfs = append(cfs, redmine.CustomFieldUpdateObject{
+				ID:    c.ID,
+				Value: v,
+			})
+		}
+		o.CustomFields = &cfs
+	}
+
+	pr, _, err := in.e.r.ProjectCreate(redmine.ProjectCreate{Project: o})
+	if err != nil {
+		return err
+	}
+
+	in.res.Project = pr
+
+	in.undoAdd("project "+pr.Identifier, func() error {
+		_, err := in.e.r.ProjectDelete(in.projectID())
+		return err
+	})
+
+	return nil
+}
+
+func (in *instance) memberships() error {
+
+	for i, m := range in.spec.Memberships {
+
+		ms, _, err := in.e.r.MembershipAdd(
+			in.projectID(),
+			redmine.MembershipAdd{
+				Membership: redmine.MembershipAddObject{
+					UserID:  in.principalIDs[i],
+					RoleIDs: m.Roles,
+				},
+			},
+		)
+		if err != nil {
+			return fmt.Errorf("principal %d: %w", in.principalIDs[i], err)
+		}
+
+		in.res.MembershipIDs = append(in.res.MembershipIDs, ms.ID)
+
+		in.undoAdd("membership "+strconv.FormatInt(ms.ID, 10), func() error {
+			_, err := in.e.r.MembershipDelete(ms.ID)
+			return err
+		})
+	}
+
+	return nil
+}
+
+func (in *instance) categories() error {
+
+	for i, c := range in.spec.Categories {
+
+		ct, _, err := in.e.r.IssueCategoryCreate(
+			in.projectID(),
+			redmine.IssueCategoryCreate{
+				IssueCategory: redmine.IssueCategoryCreateObject{
+					Name:         c.Name,
+					AssignedToID: in.categoryAssignees[i],
+				},
+			},
+		)
+		if err != nil {
+			return fmt.Errorf("category %q: %w", c.Name, err)
+		}
+
+		in.res.CategoryIDs[c.Name] = ct.ID
+
+		in.undoAdd("category "+c.Name, func() error {
+			_, err := in.e.r.IssueCategoryDelete(ct.ID, redmine.IssueCategoryDeleteRequest{})
+			return err
+		})
+	}
+
+	return nil
+}
+
+func (in *instance) versions() error {
+
+	for _, v := range in.spec.Versions {
+
+		o := redmine.VersionCreateObject{
+			Name: v.Name,
+		}
+
+		if v.Description != "" {
+			o.Description = redmine.StringPtr(v.Description)
+		}
+
+		if v.Status != "" {
+			st := redmine.VersionStatus(v.Status)
+			o.Status = &st
+		}
+
+		if v.DueDate != "" {
+			d, _ := redmine.DateParse(v.DueDate)
+			o.DueDate = &d
+		}
+
+		if v.Sharing != "" {
+			sh := redmine.VersionSharing(v.Sharing)
+			o.Sharing = &sh
+		}
+
+		if v.WikiPageTitle != "" {
+			o.WikiPageTitle = redmine.StringPtr(v.WikiPageTitle)
+		}
+
+		vr, _, err := in.e.r.VersionCreate(in.projectID(), redmine.VersionCreate{Version: o})
+		if err != nil {
+			return fmt.Errorf("version %q: %w", v.Name, err)
+		}
+
+		in.res.VersionIDs[v.Name] = vr.ID
+
+		in.undoAdd("version "+v.Name, func() error {
+			_, err := in.e.r.VersionDelete(vr.ID)
+			return err
+		})
+	}
+
+	return nil
+}
+
+// defaults sets project default version and assignee. Both must exist
+// before project update, so they are not set on project create
+func (in *instance) defaults() error {
+
+	var o redmine.ProjectUpdateObject
+
+	if v := in.spec.Project.DefaultVersion; v != "" {
+		for n, id := range in.res.VersionIDs {
+			if strings.EqualFold(n, v) == true {
+				o.DefaultVersionID = redmine.Int64Ptr(id)
+			}
+		}
+	}
+
+	o.DefaultAssignedToID = in.defaultAssigneeID
+
+	if o.DefaultVersionID == nil && o.DefaultAssignedToID == nil {
+		return nil
+	}
+
+	_, err := in.e.r.ProjectUpdate(in.projectID(), redmine.ProjectUpdate{Project: o})
+
+	return err
+}
+
+func (in *instance) wikiPages() error {
+
+	for _, w := range in.spec.WikiPages {
+
+		o := redmine.WikiCreateObject{
+			Text: w.Text,
+		}
+
+		if w.Comments != "" {
+			o.Comments = redmine.StringPtr(w.Comments)
+		}
+
+		if w.Parent != "" {
+			o.ParentTitle = redmine.StringPtr(w.Parent)
+		}
+
+		wp, _, err := in.e.r.WikiCreate(in.projectID(), w.Title, redmine.WikiCreate{WikiPage: o})
+		if err != nil {
+			return fmt.Errorf("wiki page %q: %w", w.Title, err)
+		}
+
+		in.res.WikiPages = append(in.res.WikiPages, wp.Title)
+
+		in.undoAdd("wiki page "+wp.Title, func() error {
+			_, err := in.e.r.WikiDelete(in.projectID(), wp.Title)
+			return err
+		})
+	}
+
+	return nil
+}
+
+func (in *instance) undoAdd(name string, fn func() error) {
+	in.undo = append(in.undo, undoStep{name: name, fn: fn})
+}
+
+// rollback deletes created objects in reverse order
+func (in *instance) rollback() error {
+
+	var errs []error
+
+	for i := len(in.undo) - 1; i >= 0; i-- {
+		if err := in.undo[i].fn(); err != nil {
+			errs = append(errs, fmt.Errorf("projecttemplate: rollback %s: %w", in.undo[i].name, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+func (in *instance) projectID() string {
+	return strconv.FormatInt(in.res.Project.ID, 10)
+}
+
+// groupID resolves group by ID or name (case insensitive)
+func groupID(groups []redmine.GroupObject, v string) (int64, error) {
+
+	if id, err := strconv.ParseInt(v, 10, 64); err == nil {
+		return id, nil
+	}
+
+	var ms []redmine.IDName
+
+	for _, g := range groups {
+		if strings.EqualFold(g.Name, v) == true {
+			ms = append(ms, redmine.IDName{ID: g.ID, Name: g.Name})
+		}
+	}
+
+	if len(ms) == 1 {
+		return ms[0].ID, nil
+	}
+
+	return 0, redmine.ResolveError{Kind: "group", Value: v, Matches: ms}
+}
@@ -0,0 +1,177 @@
+package projecttemplate
+
+import (
+	"strings"
+	"testing"
+
+	redmine "github.com/nixys/nxs-go-redmine/v5"
+	"github.com/nixys/nxs-go-redmine/v5/redminetest"
+)
+
+const testSpec = `
+project:
+  name: {{ printf "%s support" .client | quote }}
+  identifier: {{ printf "%s-support" .id | quote }}
+  description: {{ printf "Support project for %s" .client | quote }}
+  is_public: false
+  trackers: [Bug, Support]
+  modules: [issue_tracking, wiki]
+  default_version: "1.0"
+  default_assignee: jdoe
+memberships:
+  - user: jdoe
+    roles: [3]
+  - group: Developers
+    roles: [4]
+categories:
+  - name: Backend
+    assigned_to: jdoe
+  - name: Frontend
+versions:
+  - name: "1.0"
+    due_date: "2024-12-31"
+  - name: "2.0"
+    sharing: descendants
+wiki_pages:
+  - title: Wiki
+    text: {{ printf "h1. %s" .client | quote }}
+  - title: Contacts
+    parent: Wiki
+    text: Contacts list
+`
+
+func TestParse(t *testing.T) {
+
+	if _, err := Render([]byte(testSpec), map[string]string{}); err == nil {
+		t.Fatal("Render error: missing variable is accepted")
+	}
+
+	// Variables with YAML special characters are inserted intact
+	spec, err := Render([]byte(testSpec), map[string]string{"client": "Acme: Inc #1 \"new\"", "id": "acme"})
+	if err != nil {
+		t.Fatal("Render error:", err)
+	}
+
+	if spec.Project.Name != "Acme: Inc #1 \"new\" support" || spec.WikiPages[0].Text != "h1. Acme: Inc #1 \"new\"" {
+		t.Fatal("Render error: unexpected variables rendering:", spec.Project.Name, spec.WikiPages[0].Text)
+	}
+
+	if _, err := Parse([]byte(`{"project": {"name": "Test", "identifier": "test", "unknown": 1}}`)); err == nil {
+		t.Fatal("Parse error: unknown field is accepted")
+	}
+
+	for _, e := range []string{
+		`project: {name: Test}`,
+		`{project: {name: Test, identifier: test}, memberships: [{user: jdoe, group: Developers, roles: [3]}]}`,
+		`{project: {name: Test, identifier: test}, versions: [{name: "1.0", status: done}]}`,
+		`{project: {name: Test, identifier: test, default_version: "2.0"}, versions: [{name: "1.0"}]}`,
+		`{project: {name: Test, identifier: test}, wiki_pages: [{title: Child, parent: Wiki, text: a}, {title: Wiki, text: b}]}`,
+	} {
+		if _, err := Parse([]byte(e)); err == nil {
+			t.Fatal("Parse error: invalid spec is accepted:", e)
+		}
+	}
+}
+
+func TestInstantiate(t *testing.T) {
+
+	s := redminetest.Init(redminetest.Settings{})
+	defer s.Close()
+
+	r := s.Context()
+
+	uid, _ := s.UserAdd("jdoe", "John", "Doe", "jdoe@example.net")
+
+	g, _, err := r.GroupCreate(redmine.GroupCreate{Group: redmine.GroupCreateObject{Name: "Developers"}})
+	if err != nil {
+		t.Fatal("Group create error:", err)
+	}
+
+	spec, err := Render([]byte(testSpec), map[string]string{"client": "acme", "id": "acme"})
+	if err != nil {
+		t.Fatal("Render error:", err)
+	}
+
+	e := Init(r)
+
+	res, err := e.Instantiate(spec)
+	if err != nil {
+		t.Fatal("Instantiate error:", err)
+	}
+
+	p, _, err := r.ProjectSingleGet(
+		"acme-support",
+		redmine.ProjectSingleGetRequest{
+			Includes: []redmine.ProjectInclude{
+				redmine.ProjectIncludeTrackers,
+				redmine.ProjectIncludeEnabledModules,
+				redmine.ProjectIncludeIssueCategories,
+			},
+		},
+	)
+	if err != nil {
+		t.Fatal("Project get error:", err)
+	}
+
+	if p.ID != res.Project.ID || p.Name != "acme support" || p.IsPublic == true ||
+		len(*p.Trackers) != 2 || len(*p.EnabledModules) != 2 || len(*p.IssueCategories) != 2 {
+		t.Fatal("Instantiate error: unexpected project:", p)
+	}
+
+	if p.DefaultVersion == nil || p.DefaultVersion.ID != res.VersionIDs["1.0"] ||
+		p.DefaultAssignee == nil || p.DefaultAssignee.ID != uid {
+		t.Fatal("Instantiate error: project defaults are not set")
+	}
+
+	ms, _, err := r.MembershipAllGet(p.Identifier)
+	if err != nil {
+		t.Fatal("Memberships get error:", err)
+	}
+
+	principals := make(map[int64]bool)
+	for _, m := range ms.Memberships {
+		if m.Group != nil {
+			principals[m.Group.ID] = true
+		}
+		if m.User != nil {
+			principals[m.User.ID] = true
+		}
+	}
+
+	if len(res.MembershipIDs) != 2 || principals[uid] == false || principals[g.ID] == false {
+		t.Fatal("Instantiate error: memberships are not created")
+	}
+
+	wp, _, err := r.WikiSingleGet(p.Identifier, "Contacts", redmine.WikiSingleGetRequest{})
+	if err != nil || wp.Parent == nil || wp.Parent.Title != "Wiki" {
+		t.Fatal("Instantiate error: wiki pages are not created:", err)
+	}
+
+	// Second instantiation fails on duplicated identifier and creates nothing
+	if _, err := e.Instantiate(spec); err == nil || strings.Contains(err.Error(), "Identifier has already been taken") == false {
+		t.Fatal("Instantiate error: expected validation error, got:", err)
+	}
+
+	// Unknown names fail before any object is created
+	spec.Project.Identifier = "acme-other"
+	spec.Memberships[0].User = "unknown"
+
+	if _, err := e.Instantiate(spec); err == nil {
+		t.Fatal("Instantiate error: unknown user is resolved")
+	}
+
+	if _, _, err := r.ProjectSingleGet("acme-other", redmine.ProjectSingleGetRequest{}); err == nil {
+		t.Fatal("Instantiate error: project is created with unresolved names")
+	}
+
+	// Category assignee is not a project member, created objects are rolled back
+	spec.Memberships = spec.Memberships[1:]
+
+	if _, err := e.Instantiate(spec); err == nil || strings.Contains(err.Error(), "Assignee is invalid") == false {
+		t.Fatal("Instantiate error: expected validation error, got:", err)
+	}
+
+	if _, _, err := r.ProjectSingleGet("acme-other", redmine.ProjectSingleGetRequest{}); err == nil {
+		t.Fatal("Instantiate error: project is not rolled back")
+	}
+}
@@ -0,0 +1,251 @@
+package projecttemplate
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+	"text/template"
+
+	redmine "github.com/nixys/nxs-go-redmine/v5"
+	"github.com/nixys/nxs-go-redmine/v5/internal/yamlstrict"
+)
+
+// Spec describes project to instantiate together with its memberships, issue
+// categories, versions and wiki pages. Objects are referred by names, which are
+// resolved into IDs on instantiation
+type Spec struct {
+	Project     ProjectSpec      `json:"project" yaml:"project"`
+	Memberships []MembershipSpec `json:"memberships" yaml:"memberships"`
+	Categories  []CategorySpec   `json:"categories" yaml:"categories"`
+	Versions    []VersionSpec    `json:"versions" yaml:"versions"`
+	WikiPages   []WikiPageSpec   `json:"wiki_pages" yaml:"wiki_pages"`
+}
+
+// ProjectSpec describes project attributes
+type ProjectSpec struct {
+	Name            string            `json:"name" yaml:"name"`
+	Identifier      string            `json:"identifier" yaml:"identifier"`
+	Description     string            `json:"description" yaml:"description"`
+	Homepage        string            `json:"homepage" yaml:"homepage"`
+	IsPublic        *bool             `json:"is_public" yaml:"is_public"`
+	InheritMembers  *bool             `json:"inherit_members" yaml:"inherit_members"`
+	Parent          string            `json:"parent" yaml:"parent"`                     // Parent project ID, identifier or name
+	Trackers        []string          `json:"trackers" yaml:"trackers"`                 // Tracker IDs or names. Redmine defaults are used if empty
+	Modules         []string          `json:"modules" yaml:"modules"`                   // Enabled modules names. Redmine defaults are used if empty
+	CustomFields    []CustomFieldSpec `json:"custom_fields" yaml:"custom_fields"`       // Project custom fields values
+	DefaultVersion  string            `json:"default_version" yaml:"default_version"`   // Name of a version from the spec
+	DefaultAssignee string            `json:"default_assignee" yaml:"default_assignee"` // User ID, login, email or full name
+}
+
+// CustomFieldSpec describes custom field value. Values are used for multiple custom fields
+type CustomFieldSpec struct {
+	ID     int64    `json:"id" yaml:"id"`
+	Value  string   `json:"value" yaml:"value"`
+	Values []string `json:"values" yaml:"values"`
+}
+
+// MembershipSpec describes project membership of either a user or a group
+type MembershipSpec struct {
+	User  string  `json:"user" yaml:"user"`   // User ID, login, email or full name
+	Group string  `json:"group" yaml:"group"` // Group ID or name
+	Roles []int64 `json:"roles" yaml:"roles"`
+}
+
+// CategorySpec describes issue category
+type CategorySpec struct {
+	Name       string `json:"name" yaml:"name"`
+	AssignedTo string `json:"assigned_to" yaml:"assigned_to"` // User ID, login, email or full name. User must be a project member
+}
+
+// VersionSpec describes version
+type VersionSpec struct {
+	Name          string `json:"name" yaml:"name"`
+	Description   string `json:"description" yaml:"description"`
+	Status        string `json:"status" yaml:"status"`     // `open` (default), `locked` or `closed`
+	DueDate       string `json:"due_date" yaml:"due_date"` // Date in `YYYY-MM-DD` format
+	Sharing       string `json:"sharing" yaml:"sharing"`   // `none` (default), `descendants`, `hierarchy`, `tree` or `system`
+	WikiPageTitle string `json:"wiki_page_title" yaml:"wiki_page_title"`
+}
+
+// WikiPageSpec describes wiki page. Parent page must be specified in the spec before its children
+type WikiPageSpec struct {
+	Title    string `json:"title" yaml:"title"`
+	Parent   string `json:"parent" yaml:"parent"`
+	Text     string `json:"text" yaml:"text"`
+	Comments string `json:"comments" yaml:"comments"`
+}
+
+var (
+	versionStatuses = []redmine.VersionStatus{
+		redmine.VersionStatusOpen,
+		redmine.VersionStatusLocked,
+		redmine.VersionStatusClosed,
+	}
+	versionSharings = []redmine.VersionSharing{
+		redmine.VersionSharingNone,
+		redmine.VersionSharingDescendants,
+		redmine.VersionSharingHierarchy,
+		redmine.VersionSharingTree,
+		redmine.VersionSharingSystem,
+	}
+)
+
+// Parse decodes spec from YAML or JSON and validates it. Unknown fields are errors
+func Parse(data []byte) (Spec, error) {
+
+	var s Spec
+
+	if err := yamlstrict.Decode(data, &s); err != nil {
+		return Spec{}, fmt.Errorf("projecttemplate: spec decode error: %w", err)
+	}
+
+	if err := s.Validate(); err != nil {
+		return Spec{}, err
+	}
+
+	return s, nil
+}
+
+// Render executes data as a `text/template` with specified variables and parses
+// the result. Missing variables are errors. Variables are inserted as is, so use
+// `quote` function to insert them as quoted YAML scalars, e.g.:
+//
+//	name: {{ quote .client }}
+//	identifier: {{ printf "%s-support" .client | quote }}
+func Render(data []byte, vars map[string]string) (Spec, error) {
+
+	t, err := template.New("spec").
+		Option("missingkey=error").
+		Funcs(template.FuncMap{"quote": quote}).
+		Parse(string(data))
+	if err != nil {
+		return Spec{}, fmt.Errorf("projecttemplate: spec template error: %w", err)
+	}
+
+	var b bytes.Buffer
+
+	if err := t.Execute(&b, vars); err != nil {
+		return Spec{}, fmt.Errorf("projecttemplate: spec template error: %w", err)
+	}
+
+	return Parse(b.Bytes())
+}
+
+// quote returns string as a double-quoted YAML scalar. JSON string is a valid one
+func quote(s string) (string, error) {
+
+	var b bytes.Buffer
+
+	e := json.NewEncoder(&b)
+	e.SetEscapeHTML(false)
+
+	if err := e.Encode(s); err != nil {
+		return "", err
+	}
+
+	return strings.TrimSuffix(b.String(), "\n"), nil
+}
+
+// Validate checks required names, duplicated categories, versions and wiki pages,
+// version statuses, sharings and dates. Names of trackers, users and other
+// objects existing in Redmine are checked by `Engine.Instantiate()`
+func (s Spec) Validate() error {
+
+	var errs []string
+
+	if s.Project.Name == "" {
+		errs = append(errs, "project name is empty")
+	}
+
+	if s.Project.Identifier == "" {
+		errs = append(errs, "project identifier is empty")
+	}
+
+	for _, c := range s.Project.CustomFields {
+		if c.Value != "" && len(c.Values) > 0 {
+			errs = append(errs, fmt.Sprintf("custom field %d has both value and values", c.ID))
+		}
+	}
+
+	for i, m := range s.Memberships {
+		if (m.User == "") == (m.Group == "") {
+			errs = append(errs, fmt.Sprintf("membership %d must have either user or group", i+1))
+		}
+		if len(m.Roles) == 0 {
+			errs = append(errs, fmt.Sprintf("membership %d has no roles", i+1))
+		}
+	}
+
+	categories := make(map[string]bool)
+
+	for _, c := range s.Categories {
+		switch {
+		case c.Name == "":
+			errs = append(errs, "category name is empty")
+		case categories[strings.ToLower(c.Name)] == true:
+			errs = append(errs, fmt.Sprintf("category %q is duplicated", c.Name))
+		}
+		categories[strings.ToLower(c.Name)] = true
+	}
+
+	versions := make(map[string]bool)
+
+	for _, v := range s.Versions {
+
+		switch {
+		case v.Name == "":
+			errs = append(errs, "version name is empty")
+		case versions[strings.ToLower(v.Name)] == true:
+			errs = append(errs, fmt.Sprintf("version %q is duplicated", v.Name))
+		}
+		versions[strings.ToLower(v.Name)] = true
+
+		if v.Status != "" && slices.Contains(versionStatuses, redmine.VersionStatus(v.Status)) == false {
+			errs = append(errs, fmt.Sprintf("version %q has invalid status %q", v.Name, v.Status))
+		}
+
+		if v.Sharing != "" && slices.Contains(versionSharings, redmine.VersionSharing(v.Sharing)) == false {
+			errs = append(errs, fmt.Sprintf("version %q has invalid sharing %q", v.Name, v.Sharing))
+		}
+
+		if v.DueDate != "" {
+			if _, err := redmine.DateParse(v.DueDate); err != nil {
+				errs = append(errs, fmt.Sprintf("version %q has invalid due date %q", v.Name, v.DueDate))
+			}
+		}
+	}
+
+	if s.Project.DefaultVersion != "" && versions[strings.ToLower(s.Project.DefaultVersion)] == false {
+		errs = append(errs, fmt.Sprintf("default version %q is not in versions", s.Project.DefaultVersion))
+	}
+
+	pages := make(map[string]bool)
+
+	for _, w := range s.WikiPages {
+
+		switch {
+		case w.Title == "":
+			errs = append(errs, "wiki page title is empty")
+		case pages[w.Title] == true:
+			errs = append(errs, fmt.Sprintf("wiki page %q is duplicated", w.Title))
+		}
+
+		if w.Text == "" {
+			errs = append(errs, fmt.Sprintf("wiki page %q text is empty", w.Title))
+		}
+
+		if w.Parent != "" && pages[w.Parent] == false {
+			errs = append(errs, fmt.Sprintf("wiki page %q parent %q must be specified before it", w.Title, w.Parent))
+		}
+
+		pages[w.Title] = true
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("projecttemplate: invalid spec: %s", strings.Join(errs, "; "))
+	}
+
+	return nil
+}
@@ -0,0 +1,116 @@
+package reconciler
+
+import (
+	"fmt"
+	"io"
+)
+
+// Action defines change action
+type Action string
+
+// Action const
+const (
+	ActionCreate Action = "create"
+	ActionUpdate Action = "update"
+	ActionDelete Action = "delete"
+)
+
+// Kind defines kind of changed object
+type Kind string
+
+// Kind const
+const (
+	KindGroup      Kind = "group"
+	KindGroupUser  Kind = "group user"
+	KindProject    Kind = "project"
+	KindMembership Kind = "membership"
+)
+
+// Plan contains changes required to bring Redmine into desired state.
+// Changes are ordered the way they are applied
+type Plan struct {
+	Changes []Change
+
+	// IDs of existing objects. Created objects IDs are added on apply
+	projects map[string]int64 // by identifier
+	groups   map[string]int64 // by lower-cased name
+}
+
+// Change describes single object change
+type Change struct {
+	Action Action
+	Kind   Kind
+	Name   string // Object name, e.g. `identifier` for projects or `identifier/principal` for memberships
+	Fields []FieldChange
+
+	apply func(a *applier) error
+}
+
+// FieldChange describes change of object field
+type FieldChange struct {
+	Name string
+	Old  string
+	New  string
+}
+
+var actionSigns = map[Action]string{
+	ActionCreate: "+",
+	ActionUpdate: "~",
+	ActionDelete: "-",
+}
+
+func (a Action) String() string {
+	return string(a)
+}
+
+func (k Kind) String() string {
+	return string(k)
+}
+
+// IsEmpty checks plan has no changes
+func (p Plan) IsEmpty() bool {
+	return len(p.Changes) == 0
+}
+
+// Count returns changes count with specified action
+func (p Plan) Count(a Action) int {
+
+	var n int
+
+	for _, c := range p.Changes {
+		if c.Action == a {
+			n++
+		}
+	}
+
+	return n
+}
+
+// Write writes human-readable plan: one line per change prefixed with
+// `+` (create), `~` (update) or `-` (delete), followed by changed fields
+// and changes summary
+func (p Plan) Write(w io.Writer) error {
+
+	if p.IsEmpty() == true {
+		_, err := fmt.Fprintln(w, "No changes")
+		return err
+	}
+
+	for _, c := range p.Changes {
+
+		if _, err := fmt.Fprintf(w, "%s %s %s %q\n", actionSigns[c.Action], c.Action, c.Kind, c.Name); err != nil {
+			return err
+		}
+
+		for _, f := range c.Fields {
+			if _, err := fmt.Fprintf(w, "    %s: %q => %q\n", f.Name, f.Old, f.New); err != nil {
+				return err
+			}
+		}
+	}
+
+	_, err := fmt.Fprintf(w, "\nPlan: %d to create, %d to update, %d to delete\n",
+		p.Count(ActionCreate), p.Count(ActionUpdate), p.Count(ActionDelete))
+
+	return err
+}
@@ -0,0 +1,723 @@
+package reconciler
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+
+	redmine "github.com/nixys/nxs-go-redmine/v5"
+)
+
+// Settings contains reconciler settings
+type Settings struct {
+	DryRun bool // Run only computes and writes the plan
+	Prune  bool // Delete groups and projects absent in the state. Otherwise they are left unmanaged
+}
+
+// Reconciler brings Redmine configuration into desired state
+type Reconciler struct {
+	r  *redmine.Context
+	rs *redmine.Resolver
+	s  Settings
+}
+
+// current contains Redmine configuration read on plan
+type current struct {
+	projects    map[string]redmine.ProjectObject      // by identifier
+	identifiers map[int64]string                      // projects identifiers by IDs
+	groups      map[string]redmine.GroupObject        // by lower-cased name, users are included for groups from the state
+	memberships map[string][]redmine.MembershipObject // by project identifier
+}
+
+// applier contains IDs of existing and created objects during plan apply
+type applier struct {
+	r        *redmine.Context
+	projects map[string]int64
+	groups   map[string]int64
+}
+
+// Init creates new reconciler
+func Init(r *redmine.Context, s Settings) *Reconciler {
+	return &Reconciler{
+		r:  r,
+		rs: redmine.ResolverInit(r),
+		s:  s,
+	}
+}
+
+// Run computes the plan, writes it to w and applies it unless dry run is set
+func (rc *Reconciler) Run(s State, w io.Writer) (Plan, error) {
+
+	p, err := rc.Plan(s)
+	if err != nil {
+		return Plan{}, err
+	}
+
+	if err := p.Write(w); err != nil {
+		return p, fmt.Errorf("reconciler: plan write error: %w", err)
+	}
+
+	if rc.s.DryRun == true {
+		return p, nil
+	}
+
+	return p, rc.Apply(p)
+}
+
+// Plan reads current Redmine configuration and computes changes required
+// to bring it into the state. Changes are ordered as follows: groups and
+// their users, projects (in the state order), memberships and deletions.
+// Memberships of the principals having inherited roles are never deleted,
+// own roles are removed from them instead
+func (rc *Reconciler) Plan(s State) (Plan, error) {
+
+	if err := s.Validate(); err != nil {
+		return Plan{}, err
+	}
+
+	// Users are resolved before any changes are computed
+	users := make(map[string]int64)
+
+	for _, g := range s.Groups {
+		for _, u := range g.Users {
+			if err := rc.userResolve(users, u); err != nil {
+				return Plan{}, err
+			}
+		}
+	}
+
+	for _, p := range s.Projects {
+		for _, m := range p.Memberships {
+			if m.User == "" {
+				continue
+			}
+			if err := rc.userResolve(users, m.User); err != nil {
+				return Plan{}, err
+			}
+		}
+	}
+
+	cur, err := rc.current(s)
+	if err != nil {
+		return Plan{}, err
+	}
+
+	pl := Plan{
+		projects: make(map[string]int64),
+		groups:   make(map[string]int64),
+	}
+
+	for i, p := range cur.projects {
+		pl.projects[i] = p.ID
+	}
+
+	for n, g := range cur.groups {
+		pl.groups[n] = g.ID
+	}
+
+	var deletes []Change
+
+	// Groups
+	for _, g := range s.Groups {
+		c, d := groupChanges(g, cur, users)
+		pl.Changes = append(pl.Changes, c...)
+		deletes = append(deletes, d...)
+	}
+
+	// Projects
+	projects := make(map[string]bool)
+
+	for _, p := range s.Projects {
+
+		if p.Parent != nil && *p.Parent != "" && projects[*p.Parent] == false {
+			if _, b := cur.projects[*p.Parent]; b == false {
+				return Plan{}, fmt.Errorf("reconciler: project %q parent %q not found", p.Identifier, *p.Parent)
+			}
+		}
+
+		if c, b := projectChange(p, cur); b == true {
+			pl.Changes = append(pl.Changes, c)
+		}
+
+		projects[p.Identifier] = true
+	}
+
+	// Memberships
+	groups := make(map[string]bool)
+	for _, g := range s.Groups {
+		groups[strings.ToLower(g.Name)] = true
+	}
+
+	for _, p := range s.Projects {
+
+		for _, m := range p.Memberships {
+			if m.Group == "" {
+				continue
+			}
+			if _, b := cur.groups[strings.ToLower(m.Group)]; b == false && groups[strings.ToLower(m.Group)] == false {
+				return Plan{}, fmt.Errorf("reconciler: project %q membership group %q not found", p.Identifier, m.Group)
+			}
+		}
+
+		c, d, err := membershipChanges(p, cur, users)
+		if err != nil {
+			return Plan{}, err
+		}
+
+		pl.Changes = append(pl.Changes, c...)
+		deletes = append(deletes, d...)
+	}
+
+	pl.Changes = append(pl.Changes, deletes...)
+
+	if rc.s.Prune == true {
+		pl.Changes = append(pl.Changes, pruneChanges(s, cur)...)
+	}
+
+	return pl, nil
+}
+
+// Apply applies plan changes in order. Apply stops on the first error,
+// changes applied so far are not reverted
+func (rc *Reconciler) Apply(p Plan) error {
+
+	a := &applier{
+		r:        rc.r,
+		projects: make(map[string]int64),
+		groups:   make(map[string]int64),
+	}
+
+	for k, v := range p.projects {
+		a.projects[k] = v
+	}
+
+	for k, v := range p.groups {
+		a.groups[k] = v
+	}
+
+	for _, c := range p.Changes {
+		if err := c.apply(a); err != nil {
+			return fmt.Errorf("reconciler: %s %s %q: %w", c.Action, c.Kind, c.Name, err)
+		}
+	}
+
+	// Cached users and projects are outdated after apply
+	rc.rs.Invalidate()
+
+	return nil
+}
+
+func (rc *Reconciler) userResolve(users map[string]int64, v string) error {
+
+	if _, b := users[v]; b == true {
+		return nil
+	}
+
+	id, err := rc.rs.UserID(v)
+	if err != nil {
+		return fmt.Errorf("reconciler: %w", err)
+	}
+
+	users[v] = id
+
+	return nil
+}
+
+// current reads Redmine configuration related to the state
+func (rc *Reconciler) current(s State) (current, error) {
+
+	cur := current{
+		projects:    make(map[string]redmine.ProjectObject),
+		identifiers: make(map[int64]string),
+		groups:      make(map[string]redmine.GroupObject),
+		memberships: make(map[string][]redmine.MembershipObject),
+	}
+
+	ps, _, err := rc.r.ProjectAllGet(redmine.ProjectAllGetRequest{})
+	if err != nil {
+		return cur, fmt.Errorf("reconciler: projects get error: %w", err)
+	}
+
+	for _, p := range ps.Projects {
+		cur.projects[p.Identifier] = p
+		cur.identifiers[p.ID] = p.Identifier
+	}
+
+	gs, _, err := rc.r.GroupAllGet()
+	if err != nil {
+		return cur, fmt.Errorf("reconciler: groups get error: %w", err)
+	}
+
+	for _, g := range gs.Groups {
+		cur.groups[strings.ToLower(g.Name)] = g
+	}
+
+	for _, g := range s.Groups {
+
+		o, b := cur.groups[strings.ToLower(g.Name)]
+		if b == false {
+			continue
+		}
+
+		o, _, err = rc.r.GroupSingleGet(o.ID, redmine.GroupSingleGetRequest{Includes: []redmine.GroupInclude{redmine.GroupIncludeUsers}})
+		if err != nil {
+			return cur, fmt.Errorf("reconciler: group %q get error: %w", g.Name, err)
+		}
+
+		cur.groups[strings.ToLower(g.Name)] = o
+	}
+
+	for _, p := range s.Projects {
+
+		if _, b := cur.projects[p.Identifier]; b == false {
+			continue
+		}
+
+		ms, _, err := rc.r.MembershipAllGet(p.Identifier)
+		if err != nil {
+			return cur, fmt.Errorf("reconciler: project %q memberships get error: %w", p.Identifier, err)
+		}
+
+		cur.memberships[p.Identifier] = ms.Memberships
+	}
+
+	return cur, nil
+}
+
+func groupChanges(g GroupState, cur current, users map[string]int64) ([]Change, []Change) {
+
+	var (
+		changes []Change
+		deletes []Change
+	)
+
+	key := strings.ToLower(g.Name)
+
+	o, b := cur.groups[key]
+	if b == false {
+
+		ids := []int64{}
+		for _, u := range g.Users {
+			if slices.Contains(ids, users[u]) == false {
+				ids = append(ids, users[u])
+			}
+		}
+
+		c := Change{
+			Action: ActionCreate,
+			Kind:   KindGroup,
+			Name:   g.Name,
+			apply: func(a *applier) error {
+				o, _, err := a.r.GroupCreate(redmine.GroupCreate{
+					Group: redmine.GroupCreateObject{
+						Name:    g.Name,
+						UserIDs: &ids,
+					},
+				})
+				if err != nil {
+					return err
+				}
+				a.groups[key] = o.ID
+				return nil
+			},
+		}
+
+		if len(g.Users) > 0 {
+			c.Fields = append(c.Fields, FieldChange{Name: "users", New: strings.Join(g.Users, ", ")})
+		}
+
+		return []Change{c}, nil
+	}
+
+	existing := make(map[int64]bool)
+	if o.Users != nil {
+		for _, u := range *o.Users {
+			existing[u.ID] = true
+		}
+	}
+
+	desired := make(map[int64]bool)
+
+	for _, u := range g.Users {
+
+		id := users[u]
+
+		if existing[id] == true || desired[id] == true {
+			desired[id] = true
+			continue
+		}
+		desired[id] = true
+
+		changes = append(changes, Change{
+			Action: ActionCreate,
+			Kind:   KindGroupUser,
+			Name:   g.Name + "/" + u,
+			apply: func(a *applier) error {
+				_, err := a.r.GroupAddUser(o.ID, redmine.GroupAddUserObject{UserID: id})
+				return err
+			},
+		})
+	}
+
+	if o.Users != nil {
+		for _, u := range *o.Users {
+
+			if desired[u.ID] == true {
+				continue
+			}
+
+			id := u.ID
+
+			deletes = append(deletes, Change{
+				Action: ActionDelete,
+				Kind:   KindGroupUser,
+				Name:   g.Name + "/" + u.Name,
+				apply: func(a *applier) error {
+					_, err := a.r.GroupDeleteUser(o.ID, id)
+					return err
+				},
+			})
+		}
+	}
+
+	return changes, deletes
+}
+
+func projectChange(p ProjectState, cur current) (Change, bool) {
+
+	o, b := cur.projects[p.Identifier]
+	if b == false {
+
+		c := Change{
+			Action: ActionCreate,
+			Kind:   KindProject,
+			Name:   p.Identifier,
+			Fields: []FieldChange{{Name: "name", New: p.Name}},
+			apply: func(a *applier) error {
+
+				po := redmine.ProjectCreateObject{
+					Name:        p.Name,
+					Identifier:  p.Identifier,
+					Description: p.Description,
+					IsPublic:    p.IsPublic,
+				}
+
+				if p.Parent != nil && *p.Parent != "" {
+					po.ParentID = redmine.Int64Ptr(a.projects[*p.Parent])
+				}
+
+				pr, _, err := a.r.ProjectCreate(redmine.ProjectCreate{Project: po})
+				if err != nil {
+					return err
+				}
+
+				a.projects[p.Identifier] = pr.ID
+
+				return nil
+			},
+		}
+
+		if p.Description != nil {
+			c.Fields = append(c.Fields, FieldChange{Name: "description", New: *p.Description})
+		}
+
+		if p.IsPublic != nil {
+			c.Fields = append(c.Fields, FieldChange{Name: "is_public", New: strconv.FormatBool(*p.IsPublic)})
+		}
+
+		if p.Parent != nil && *p.Parent != "" {
+			c.Fields = append(c.Fields, FieldChange{Name: "parent", New: *p.Parent})
+		}
+
+		return c, true
+	}
+
+	var (
+		fields []FieldChange
+		uo     redmine.ProjectUpdateObject
+	)
+
+	if o.Name != p.Name {
+		fields = append(fields, FieldChange{Name: "name", Old: o.Name, New: p.Name})
+		uo.Name = redmine.StringPtr(p.Name)
+	}
+
+	if p.Description != nil && o.Description != *p.Description {
+		fields = append(fields, FieldChange{Name: "description", Old: o.Description, New: *p.Description})
+		uo.Description = p.Description
+	}
+
+	if p.IsPublic != nil && o.IsPublic != *p.IsPublic {
+		fields = append(fields, FieldChange{Name: "is_public", Old: strconv.FormatBool(o.IsPublic), New: strconv.FormatBool(*p.IsPublic)})
+		uo.IsPublic = p.IsPublic
+	}
+
+	parent := ""
+	if p.Parent != nil && cur.identifiers[o.Parent.ID] != *p.Parent {
+		parent = *p.Parent
+		fields = append(fields, FieldChange{Name: "parent", Old: cur.identifiers[o.Parent.ID], New: parent})
+	}
+
+	if len(fields) == 0 {
+		return Change{}, false
+	}
+
+	return Change{
+		Action: ActionUpdate,
+		Kind:   KindProject,
+		Name:   p.Identifier,
+		Fields: fields,
+		apply: func(a *applier) error {
+
+			if p.Parent != nil && cur.identifiers[o.Parent.ID] != *p.Parent {
+				if parent != "" {
+					uo.ParentID = redmine.Int64Ptr(a.projects[parent])
+				} else {
+					uo.Unparent = true
+				}
+			}
+
+			_, err := a.r.ProjectUpdate(p.Identifier, redmine.ProjectUpdate{Project: uo})
+			return err
+		},
+	}, true
+}
+
+func membershipChanges(p ProjectState, cur current, users map[string]int64) ([]Change, []Change, error) {
+
+	var (
+		changes []Change
+		deletes []Change
+		desired []redmine.MembershipSyncObject
+	)
+
+	// Desired memberships by users and groups IDs
+	us := make(map[int64]MembershipState)
+	gs := make(map[int64]MembershipState)
+
+	for _, m := range p.Memberships {
+
+		if m.User != "" {
+			us[users[m.User]] = m
+			desired = append(desired, redmine.MembershipSyncObject{UserID: users[m.User], RoleIDs: m.Roles})
+			continue
+		}
+
+		g, b := cur.groups[strings.ToLower(m.Group)]
+		if b == false {
+			// Groups to be created have no memberships yet
+			roles := slices.Clone(m.Roles)
+			slices.Sort(roles)
+			changes = append(changes, membershipChange(ActionCreate, p.Identifier, p.Identifier+"/"+m.principal(), 0, m, users, nil, slices.Compact(roles)))
+			continue
+		}
+
+		gs[g.ID] = m
+		desired = append(desired, redmine.MembershipSyncObject{GroupID: g.ID, RoleIDs: m.Roles})
+	}
+
+	// Memberships are compared by the same rules `MembershipSync` uses
+	d, err := redmine.MembershipSyncDiff(cur.memberships[p.Identifier], desired)
+	if err != nil {
+		return nil, nil, fmt.Errorf("reconciler: project %q: %w", p.Identifier, err)
+	}
+
+	for _, a := range d.Add {
+		m := us[a.UserID]
+		if a.GroupID != 0 {
+			m = gs[a.GroupID]
+		}
+		changes = append(changes, membershipChange(ActionCreate, p.Identifier, p.Identifier+"/"+m.principal(), 0, m, users, nil, a.RoleIDs))
+	}
+
+	for _, u := range d.Update {
+
+		var (
+			m MembershipState
+			b bool
+		)
+
+		switch {
+		case u.Membership.User != nil:
+			m, b = us[u.Membership.User.ID]
+		case u.Membership.Group != nil:
+			m, b = gs[u.Membership.Group.ID]
+		}
+
+		if b == true {
+			changes = append(changes, membershipChange(ActionUpdate, p.Identifier, p.Identifier+"/"+m.principal(), u.Membership.ID, m, users, u.OwnRoleIDs, u.RoleIDs))
+			continue
+		}
+
+		// Memberships with inherited roles can not be deleted, own roles are removed instead
+		deletes = append(deletes, membershipChange(ActionUpdate, p.Identifier, p.Identifier+"/"+membershipPrincipal(u.Membership), u.Membership.ID, MembershipState{}, users, u.OwnRoleIDs, u.RoleIDs))
+	}
+
+	for _, o := range d.Delete {
+
+		var own []int64
+		for _, r := range o.Roles {
+			own = append(own, r.ID)
+		}
+
+		deletes = append(deletes, membershipChange(ActionDelete, p.Identifier, p.Identifier+"/"+membershipPrincipal(o), o.ID, MembershipState{}, users, own, nil))
+	}
+
+	return changes, deletes, nil
+}
+
+// membershipChange returns change of membership with specified ID (zero for
+// new ones) into state m, which is empty for memberships to remove. Membership
+// is diffed again on apply, as roles inherited from groups may be changed by
+// changes applied before
+func membershipChange(action Action, project, name string, id int64, m MembershipState, users map[string]int64, old, roles []int64) Change {
+
+	return Change{
+		Action: action,
+		Kind:   KindMembership,
+		Name:   name,
+		Fields: []FieldChange{{Name: "roles", Old: rolesString(old), New: rolesString(roles)}},
+		apply: func(a *applier) error {
+
+			var (
+				current []redmine.MembershipObject
+				desired []redmine.MembershipSyncObject
+			)
+
+			if id != 0 {
+				o, _, err := a.r.MembershipSingleGet(id)
+				if err != nil {
+					return err
+				}
+				current = append(current, o)
+			}
+
+			switch {
+			case m.User != "":
+				desired = append(desired, redmine.MembershipSyncObject{UserID: users[m.User], RoleIDs: m.Roles})
+			case m.Group != "":
+				desired = append(desired, redmine.MembershipSyncObject{GroupID: a.groups[strings.ToLower(m.Group)], RoleIDs: m.Roles})
+			}
+
+			d, err := redmine.MembershipSyncDiff(current, desired)
+			if err != nil {
+				return err
+			}
+
+			_, _, err = a.r.MembershipSyncApply(project, d)
+			return err
+		},
+	}
+}
+
+// membershipPrincipal returns description of existing membership principal for plan output
+func membershipPrincipal(m redmine.MembershipObject) string {
+	if m.User != nil {
+		return "user " + m.User.Name
+	}
+	return "group " + m.Group.Name
+}
+
+// pruneChanges returns deletions of projects and groups absent in the state.
+// Subprojects are deleted before their parents. Projects having
+// subprojects from the state are kept
+func pruneChanges(s State, cur current) []Change {
+
+	var changes []Change
+
+	projects := make(map[string]bool)
+	for _, p := range s.Projects {
+		projects[p.Identifier] = true
+	}
+
+	ps := []redmine.ProjectObject{}
+	for _, p := range cur.projects {
+		ps = append(ps, p)
+	}
+
+	slices.SortFunc(ps, func(a, b redmine.ProjectObject) int {
+		return int(a.ID - b.ID)
+	})
+
+	t := redmine.ProjectTreeBuild(ps)
+
+	var pruned []redmine.ProjectObject
+
+	t.Walk(func(p redmine.ProjectObject, depth int) bool {
+
+		if projects[p.Identifier] == true {
+			return true
+		}
+
+		for _, d := range t.Descendants(p.ID) {
+			if projects[d.Identifier] == true {
+				return true
+			}
+		}
+
+		pruned = append(pruned, p)
+
+		return true
+	})
+
+	for i := len(pruned) - 1; i >= 0; i-- {
+
+		identifier := pruned[i].Identifier
+
+		changes = append(changes, Change{
+			Action: ActionDelete,
+			Kind:   KindProject,
+			Name:   identifier,
+			apply: func(a *applier) error {
+				_, err := a.r.ProjectDelete(identifier)
+				return err
+			},
+		})
+	}
+
+	groups := make(map[string]bool)
+	for _, g := range s.Groups {
+		groups[strings.ToLower(g.Name)] = true
+	}
+
+	gs := []redmine.GroupObject{}
+	for n, g := range cur.groups {
+		if groups[n] == false {
+			gs = append(gs, g)
+		}
+	}
+
+	slices.SortFunc(gs, func(a, b redmine.GroupObject) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+
+	for _, g := range gs {
+
+		id := g.ID
+
+		changes = append(changes, Change{
+			Action: ActionDelete,
+			Kind:   KindGroup,
+			Name:   g.Name,
+			apply: func(a *applier) error {
+				_, err := a.r.GroupDelete(id)
+				return err
+			},
+		})
+	}
+
+	return changes
+}
+
+func rolesString(roles []int64) string {
+
+	var s []string
+
+	for _, r := range roles {
+		s = append(s, strconv.FormatInt(r, 10))
+	}
+
+	return strings.Join(s, ", ")
+}
@@ -0,0 +1,218 @@
+package reconciler
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	redmine "github.com/nixys/nxs-go-redmine/v5"
+	"github.com/nixys/nxs-go-redmine/v5/redminetest"
+)
+
+const testState = `
+groups:
+  - name: Developers
+    users: [jdoe, asmith]
+projects:
+  - identifier: acme
+    name: Acme
+    description: Acme projects
+    is_public: false
+    memberships:
+      - user: jdoe
+        roles: [3]
+      - group: Developers
+        roles: [4]
+  - identifier: acme-web
+    name: Acme web
+    parent: acme
+    memberships:
+      - user: asmith
+        roles: [3, 4]
+`
+
+func TestParse(t *testing.T) {
+
+	if _, err := Parse([]byte(`{"projects": [{"identifier": "test", "name": "Test", "unknown": 1}]}`)); err == nil {
+		t.Fatal("Parse error: unknown field is accepted")
+	}
+
+	for _, e := range []string{
+		`projects: [{name: Test}]`,
+		`projects: [{identifier: test}]`,
+		`projects: [{identifier: test, name: Test}, {identifier: test, name: Other}]`,
+		`projects: [{identifier: test, name: Test, memberships: [{user: jdoe, group: Developers, roles: [3]}]}]`,
+		`projects: [{identifier: test, name: Test, memberships: [{user: jdoe}]}]`,
+		`projects: [{identifier: test, name: Test, memberships: [{user: jdoe, roles: [3]}, {user: JDoe, roles: [4]}]}]`,
+		`groups: [{name: Developers}, {name: developers}]`,
+	} {
+		if _, err := Parse([]byte(e)); err == nil {
+			t.Fatal("Parse error: invalid state is accepted:", e)
+		}
+	}
+}
+
+func TestReconcile(t *testing.T) {
+
+	s := redminetest.Init(redminetest.Settings{})
+	defer s.Close()
+
+	r := s.Context()
+
+	s.UserAdd("jdoe", "John", "Doe", "jdoe@example.net")
+	s.UserAdd("asmith", "Alice", "Smith", "asmith@example.net")
+	s.UserAdd("bjones", "Bob", "Jones", "bjones@example.net")
+
+	if _, _, err := r.ProjectCreate(redmine.ProjectCreate{Project: redmine.ProjectCreateObject{Name: "Legacy", Identifier: "legacy"}}); err != nil {
+		t.Fatal("Project create error:", err)
+	}
+
+	st, err := Parse([]byte(testState))
+	if err != nil {
+		t.Fatal("Parse error:", err)
+	}
+
+	// Dry run makes no changes
+	var b bytes.Buffer
+
+	p, err := Init(r, Settings{DryRun: true}).Run(st, &b)
+	if err != nil {
+		t.Fatal("Run error:", err)
+	}
+
+	if p.Count(ActionCreate) != 6 || p.Count(ActionUpdate) != 0 || p.Count(ActionDelete) != 0 {
+		t.Fatal("Plan error: unexpected plan:", b.String())
+	}
+
+	if strings.Contains(b.String(), `+ create project "acme"`) == false ||
+		strings.Contains(b.String(), "Plan: 6 to create, 0 to update, 0 to delete") == false {
+		t.Fatal("Plan error: unexpected plan output:", b.String())
+	}
+
+	if _, _, err := r.ProjectSingleGet("acme", redmine.ProjectSingleGetRequest{}); err == nil {
+		t.Fatal("Run error: project is created on dry run")
+	}
+
+	// Apply
+	rc := Init(r, Settings{})
+
+	if _, err := rc.Run(st, &bytes.Buffer{}); err != nil {
+		t.Fatal("Run error:", err)
+	}
+
+	pw, _, err := r.ProjectSingleGet("acme-web", redmine.ProjectSingleGetRequest{})
+	if err != nil || pw.Parent.Name != "Acme" {
+		t.Fatal("Apply error: project is not created:", err)
+	}
+
+	ms, _, err := r.MembershipAllGet("acme")
+	if err != nil {
+		t.Fatal("Memberships get error:", err)
+	}
+
+	if len(ms.Memberships) < 2 {
+		t.Fatal("Apply error: memberships are not created:", ms.Memberships)
+	}
+
+	p, err = rc.Plan(st)
+	if err != nil {
+		t.Fatal("Plan error:", err)
+	}
+
+	if p.IsEmpty() == false {
+		t.Fatal("Plan error: plan is not empty after apply:", p.Changes)
+	}
+
+	// Modify state
+	st.Groups[0].Users = []string{"jdoe", "bjones"}
+	st.Projects[0].Name = "Acme Corp"
+	st.Projects[0].Memberships = []MembershipState{{Group: "Developers", Roles: []int64{3, 4}}}
+	st.Projects[1].Parent = redmine.StringPtr("")
+	st.Projects[1].Memberships = nil
+
+	p, err = Init(r, Settings{Prune: true}).Plan(st)
+	if err != nil {
+		t.Fatal("Plan error:", err)
+	}
+
+	b.Reset()
+	p.Write(&b)
+
+	for _, e := range []string{
+		`+ create group user "Developers/bjones"`,
+		`- delete group user "Developers/Alice Smith"`,
+		`~ update project "acme"`,
+		`name: "Acme" => "Acme Corp"`,
+		`~ update project "acme-web"`,
+		`~ update membership "acme/group Developers"`,
+		`~ update membership "acme/user John Doe"`,
+		`roles: "3" => ""`,
+		`- delete membership "acme-web/user Alice Smith"`,
+		`- delete project "legacy"`,
+	} {
+		if strings.Contains(b.String(), e) == false {
+			t.Fatalf("Plan error: %q is not in plan:\n%s", e, b.String())
+		}
+	}
+
+	// Membership with roles inherited from the group is kept without own roles
+	if strings.Contains(b.String(), `- delete membership "acme/user John Doe"`) == true {
+		t.Fatal("Plan error: membership with inherited roles is deleted:", b.String())
+	}
+
+	if err := Init(r, Settings{Prune: true}).Apply(p); err != nil {
+		t.Fatal("Apply error:", err)
+	}
+
+	ms, _, err = r.MembershipAllGet("acme")
+	if err != nil {
+		t.Fatal("Memberships get error:", err)
+	}
+
+	for _, m := range ms.Memberships {
+		if m.User == nil || m.User.Name != "John Doe" {
+			continue
+		}
+		for _, rl := range m.Roles {
+			if rl.Inherited == false {
+				t.Fatal("Apply error: own roles of membership with inherited roles are not removed:", m.Roles)
+			}
+		}
+	}
+
+	p, err = rc.Plan(st)
+	if err != nil {
+		t.Fatal("Plan error:", err)
+	}
+
+	if p.IsEmpty() == false {
+		b.Reset()
+		p.Write(&b)
+		t.Fatal("Plan error: plan is not empty after apply:", b.String())
+	}
+
+	if _, _, err := r.ProjectSingleGet("legacy", redmine.ProjectSingleGetRequest{}); err == nil {
+		t.Fatal("Apply error: project is not pruned")
+	}
+
+	// Desired roles inherited from the group are not set as own ones, as `MembershipSync` does
+	st.Projects[0].Memberships = append(st.Projects[0].Memberships, MembershipState{User: "jdoe", Roles: []int64{4, 3}})
+
+	p, err = rc.Plan(st)
+	if err != nil {
+		t.Fatal("Plan error:", err)
+	}
+
+	if p.IsEmpty() == false {
+		b.Reset()
+		p.Write(&b)
+		t.Fatal("Plan error: inherited roles are planned as own ones:", b.String())
+	}
+
+	// Unknown users fail on plan
+	st.Groups[0].Users = []string{"unknown"}
+
+	if _, err := rc.Plan(st); err == nil {
+		t.Fatal("Plan error: unknown user is resolved")
+	}
+}
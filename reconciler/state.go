@@ -0,0 +1,131 @@
+package reconciler
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/nixys/nxs-go-redmine/v5/internal/yamlstrict"
+)
+
+// State describes desired Redmine configuration. Groups and projects absent
+// in the state are left as is unless `Settings.Prune` is set
+type State struct {
+	Groups   []GroupState   `json:"groups" yaml:"groups"`
+	Projects []ProjectState `json:"projects" yaml:"projects"`
+}
+
+// GroupState describes group and its members
+type GroupState struct {
+	Name  string   `json:"name" yaml:"name"`
+	Users []string `json:"users" yaml:"users"` // User IDs, logins, emails or full names
+}
+
+// ProjectState describes project and its memberships. Memberships of
+// the project are managed exclusively: absent ones are deleted
+type ProjectState struct {
+	Identifier  string            `json:"identifier" yaml:"identifier"`
+	Name        string            `json:"name" yaml:"name"`
+	Description *string           `json:"description" yaml:"description"` // Not managed if not set
+	IsPublic    *bool             `json:"is_public" yaml:"is_public"`     // Not managed if not set
+	Parent      *string           `json:"parent" yaml:"parent"`           // Parent project identifier, empty for top level projects. Not managed if not set
+	Memberships []MembershipState `json:"memberships" yaml:"memberships"`
+}
+
+// MembershipState describes project membership of either a user or a group
+type MembershipState struct {
+	User  string  `json:"user" yaml:"user"`   // User ID, login, email or full name
+	Group string  `json:"group" yaml:"group"` // Group name
+	Roles []int64 `json:"roles" yaml:"roles"`
+}
+
+// Parse decodes state from YAML or JSON and validates it. Unknown fields are errors
+func Parse(data []byte) (State, error) {
+
+	var s State
+
+	if err := yamlstrict.Decode(data, &s); err != nil {
+		return State{}, fmt.Errorf("reconciler: state decode error: %w", err)
+	}
+
+	if err := s.Validate(); err != nil {
+		return State{}, err
+	}
+
+	return s, nil
+}
+
+// Validate checks names and identifiers are set and unique, projects are not
+// parents of themselves and memberships refer to either a user or a group and
+// have roles. Existence of users, groups and parent projects is checked by `Plan()`
+func (s State) Validate() error {
+
+	var errs []string
+
+	groups := make(map[string]bool)
+
+	for _, g := range s.Groups {
+		switch {
+		case g.Name == "":
+			errs = append(errs, "group name is empty")
+		case groups[strings.ToLower(g.Name)] == true:
+			errs = append(errs, fmt.Sprintf("group %q is duplicated", g.Name))
+		}
+		groups[strings.ToLower(g.Name)] = true
+	}
+
+	projects := make(map[string]bool)
+
+	for _, p := range s.Projects {
+
+		switch {
+		case p.Identifier == "":
+			errs = append(errs, "project identifier is empty")
+		case projects[p.Identifier] == true:
+			errs = append(errs, fmt.Sprintf("project %q is duplicated", p.Identifier))
+		}
+
+		if p.Name == "" {
+			errs = append(errs, fmt.Sprintf("project %q name is empty", p.Identifier))
+		}
+
+		if p.Parent != nil && *p.Parent == p.Identifier {
+			errs = append(errs, fmt.Sprintf("project %q is a parent of itself", p.Identifier))
+		}
+
+		principals := make(map[string]bool)
+
+		for _, m := range p.Memberships {
+
+			if (m.User == "") == (m.Group == "") {
+				errs = append(errs, fmt.Sprintf("project %q membership must have either user or group", p.Identifier))
+				continue
+			}
+
+			if len(m.Roles) == 0 {
+				errs = append(errs, fmt.Sprintf("project %q membership of %s has no roles", p.Identifier, m.principal()))
+			}
+
+			k := strings.ToLower(m.principal())
+			if principals[k] == true {
+				errs = append(errs, fmt.Sprintf("project %q membership of %s is duplicated", p.Identifier, m.principal()))
+			}
+			principals[k] = true
+		}
+
+		projects[p.Identifier] = true
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("reconciler: invalid state: %s", strings.Join(errs, "; "))
+	}
+
+	return nil
+}
+
+// principal returns membership principal description for plan output
+func (m MembershipState) principal() string {
+	if m.User != "" {
+		return "user " + m.User
+	}
+	return "group " + m.Group
+}
@@ -0,0 +1,498 @@
+// Package recorder provides an `http.RoundTripper` to record Redmine API
+// interactions into fixture files and replay them back in tests.
+//
+// In record mode requests are passed to the underlying transport and
+// request/response pairs are captured. API key and configured fields are
+// redacted before interactions are saved. In replay mode requests are served
+// from fixture file and requests without recorded interaction fail.
+//
+// Usage:
+//
+//	rec, err := recorder.Init(recorder.Settings{
+//		Mode: recorder.ModeReplay,
+//		Path: "testdata/issues.json",
+//	})
+//	...
+//	r := redmine.Init(redmine.Settings{
+//		Endpoint:   endpoint,
+//		APIKey:     apiKey,
+//		HTTPClient: rec.Client(),
+//	})
+package recorder
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+// Mode defines recorder mode type
+type Mode int
+
+// Mode const
+const (
+	ModeReplay Mode = iota
+	ModeRecord
+)
+
+// Redacted is a value redacted data is replaced with
+const Redacted = "REDACTED"
+
+// BodyEncodingBase64 is set for bodies recorded in base64, i.e. ones which
+// are not a valid UTF-8 JSON (e.g. attachments uploads and downloads)
+const BodyEncodingBase64 = "base64"
+
+// Settings contains data to init recorder
+type Settings struct {
+	Mode Mode
+	Path string // Fixture file path
+
+	// Transport used to make real requests in record mode. `http.DefaultTransport` if not set
+	Transport http.RoundTripper
+
+	// Names of request headers and query parameters to redact. `X-Redmine-API-Key`
+	// header and `key` query parameter are always redacted
+	RedactHeaders []string
+	RedactQuery   []string
+
+	// Names of JSON fields to redact in request and response bodies at any depth
+	// (e.g. `api_key`, `mail`, `password`)
+	RedactFields []string
+}
+
+// Recorder records or replays HTTP interactions
+type Recorder struct {
+	mode      Mode
+	path      string
+	transport http.RoundTripper
+
+	redactHeaders map[string]bool
+	redactQuery   map[string]bool
+	redactFields  map[string]bool
+
+	mu           sync.Mutex
+	interactions []Interaction
+	used         []bool
+}
+
+// Interaction is a recorded request/response pair
+type Interaction struct {
+	Request  Request  `json:"request"`
+	Response Response `json:"response"`
+}
+
+// Request contains recorded request data
+type Request struct {
+	Method       string      `json:"method"`
+	URL          string      `json:"url"` // Path with query, endpoint is not recorded
+	Header       http.Header `json:"header,omitempty"`
+	Body         string      `json:"body,omitempty"`
+	BodyEncoding string      `json:"body_encoding,omitempty"` // Empty or `BodyEncodingBase64`
+}
+
+// Response contains recorded response data
+type Response struct {
+	StatusCode   int         `json:"status_code"`
+	Header       http.Header `json:"header,omitempty"`
+	Body         string      `json:"body,omitempty"`
+	BodyEncoding string      `json:"body_encoding,omitempty"` // Empty or `BodyEncodingBase64`
+}
+
+type fixture struct {
+	Interactions []Interaction `json:"interactions"`
+}
+
+// Init creates new recorder. In replay mode fixture file is loaded
+func Init(s Settings) (*Recorder, error) {
+
+	rec := &Recorder{
+		mode:          s.Mode,
+		path:          s.Path,
+		transport:     s.Transport,
+		redactHeaders: map[string]bool{"X-Redmine-Api-Key": true},
+		redactQuery:   map[string]bool{"key": true},
+		redactFields:  make(map[string]bool),
+	}
+
+	if rec.transport == nil {
+		rec.transport = http.DefaultTransport
+	}
+
+	for _, h := range s.RedactHeaders {
+		rec.redactHeaders[http.CanonicalHeaderKey(h)] = true
+	}
+
+	for _, q := range s.RedactQuery {
+		rec.redactQuery[q] = true
+	}
+
+	for _, f := range s.RedactFields {
+		rec.redactFields[f] = true
+	}
+
+	if rec.mode == ModeReplay {
+
+		b, err := os.ReadFile(rec.path)
+		if err != nil {
+			return nil, fmt.Errorf("recorder init: %w", err)
+		}
+
+		var f fixture
+		if err := json.Unmarshal(b, &f); err != nil {
+			return nil, fmt.Errorf("recorder init: fixture decode: %w", err)
+		}
+
+		rec.interactions = f.Interactions
+		rec.used = make([]bool, len(f.Interactions))
+	}
+
+	return rec, nil
+}
+
+// Client returns HTTP client using the recorder as a transport
+func (rec *Recorder) Client() *http.Client {
+	return &http.Client{
+		Transport: rec,
+	}
+}
+
+// Interactions returns recorded or loaded interactions
+func (rec *Recorder) Interactions() []Interaction {
+
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+
+	return append([]Interaction{}, rec.interactions...)
+}
+
+// Unused returns loaded interactions that have not been replayed yet
+func (rec *Recorder) Unused() []Interaction {
+
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+
+	var is []Interaction
+
+	for i, u := range rec.used {
+		if u == false {
+			is = append(is, rec.interactions[i])
+		}
+	}
+
+	return is
+}
+
+// Save writes recorded interactions into fixture file. Does nothing in replay mode
+func (rec *Recorder) Save() error {
+
+	if rec.mode != ModeRecord {
+		return nil
+	}
+
+	rec.mu.Lock()
+	b, err := json.MarshalIndent(fixture{Interactions: rec.interactions}, "", "  ")
+	rec.mu.Unlock()
+
+	if err != nil {
+		return fmt.Errorf("recorder save: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(rec.path), 0755); err != nil {
+		return fmt.Errorf("recorder save: %w", err)
+	}
+
+	if err := os.WriteFile(rec.path, append(b, '\n'), 0644); err != nil {
+		return fmt.Errorf("recorder save: %w", err)
+	}
+
+	return nil
+}
+
+// RoundTrip implements `http.RoundTripper` interface. Request body is read
+// and closed, the request is not modified: its clone is sent in record mode
+func (rec *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+
+	var body []byte
+
+	if req.Body != nil && req.Body != http.NoBody {
+		b, err := io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		body = b
+	}
+
+	r := rec.request(req, body)
+
+	if rec.mode == ModeReplay {
+		return rec.replay(req, r)
+	}
+
+	out := req.Clone(req.Context())
+	if body != nil {
+		out.Body = io.NopCloser(bytes.NewReader(body))
+		out.GetBody = func() (io.ReadCloser, error) {
+			return io.NopCloser(bytes.NewReader(body)), nil
+		}
+	}
+
+	res, err := rec.transport.RoundTrip(out)
+	if err != nil {
+		return nil, err
+	}
+
+	resBody, err := readBody(&res.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	b, enc := rec.body(resBody)
+
+	rec.mu.Lock()
+	rec.interactions = append(rec.interactions, Interaction{
+		Request: r,
+		Response: Response{
+			StatusCode:   res.StatusCode,
+			Header:       rec.header(res.Header),
+			Body:         b,
+			BodyEncoding: enc,
+		},
+	})
+	rec.mu.Unlock()
+
+	return res, nil
+}
+
+func (rec *Recorder) replay(req *http.Request, r Request) (*http.Response, error) {
+
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+
+	for i, e := range rec.interactions {
+
+		if rec.used[i] == true || match(e.Request, r) == false {
+			continue
+		}
+
+		b, err := bodyDecode(e.Response.Body, e.Response.BodyEncoding)
+		if err != nil {
+			return nil, fmt.Errorf("recorder: interaction %d response body decode error: %w", i, err)
+		}
+
+		rec.used[i] = true
+
+		return &http.Response{
+			Status:        fmt.Sprintf("%d %s", e.Response.StatusCode, http.StatusText(e.Response.StatusCode)),
+			StatusCode:    e.Response.StatusCode,
+			Proto:         "HTTP/1.1",
+			ProtoMajor:    1,
+			ProtoMinor:    1,
+			Header:        e.Response.Header.Clone(),
+			Body:          io.NopCloser(bytes.NewReader(b)),
+			ContentLength: int64(len(b)),
+			Request:       req,
+		}, nil
+	}
+
+	return nil, fmt.Errorf("recorder: no recorded interaction for request %s %s", r.Method, r.URL)
+}
+
+// request converts HTTP request into redacted recorded request
+func (rec *Recorder) request(req *http.Request, body []byte) Request {
+
+	u := *req.URL
+
+	q := u.Query()
+	for k := range q {
+		if rec.redactQuery[k] == true {
+			q.Set(k, Redacted)
+		}
+	}
+
+	b, enc := rec.body(body)
+
+	return Request{
+		Method:       req.Method,
+		URL:          (&url.URL{Path: u.Path, RawQuery: q.Encode()}).String(),
+		Header:       rec.header(req.Header),
+		Body:         b,
+		BodyEncoding: enc,
+	}
+}
+
+func (rec *Recorder) header(h http.Header) http.Header {
+
+	c := h.Clone()
+
+	for k := range c {
+		if rec.redactHeaders[http.CanonicalHeaderKey(k)] == true {
+			c.Set(k, Redacted)
+		}
+	}
+
+	return c
+}
+
+// body redacts configured fields within JSON body and returns the body with
+// its encoding. Bodies which are not a valid UTF-8 JSON are encoded in base64,
+// otherwise JSON strings of the fixture would corrupt them
+func (rec *Recorder) body(b []byte) (string, string) {
+
+	if len(b) == 0 {
+		return "", ""
+	}
+
+	if utf8.Valid(b) == false || json.Valid(b) == false {
+		return base64.StdEncoding.EncodeToString(b), BodyEncodingBase64
+	}
+
+	if len(rec.redactFields) == 0 {
+		return string(b), ""
+	}
+
+	var v interface{}
+
+	d := json.NewDecoder(bytes.NewReader(b))
+	d.UseNumber()
+
+	if err := d.Decode(&v); err != nil {
+		return string(b), ""
+	}
+
+	o, err := json.Marshal(rec.redact(v))
+	if err != nil {
+		return string(b), ""
+	}
+
+	return string(o), ""
+}
+
+// bodyDecode decodes recorded body with specified encoding
+func bodyDecode(body, encoding string) ([]byte, error) {
+
+	switch encoding {
+	case "":
+		return []byte(body), nil
+	case BodyEncodingBase64:
+		return base64.StdEncoding.DecodeString(body)
+	}
+
+	return nil, fmt.Errorf("unknown body encoding %q", encoding)
+}
+
+func (rec *Recorder) redact(v interface{}) interface{} {
+
+	switch e := v.(type) {
+	case map[string]interface{}:
+		for k, f := range e {
+			if rec.redactFields[k] == true && f != nil {
+				e[k] = Redacted
+			} else {
+				e[k] = rec.redact(f)
+			}
+		}
+	case []interface{}:
+		for i := range e {
+			e[i] = rec.redact(e[i])
+		}
+	}
+
+	return v
+}
+
+// match checks requests are the same. Query parameters order and
+// JSON bodies formatting are not taken into account
+func match(a, b Request) bool {
+
+	if a.Method != b.Method {
+		return false
+	}
+
+	ua, err := url.Parse(a.URL)
+	if err != nil {
+		return false
+	}
+
+	ub, err := url.Parse(b.URL)
+	if err != nil {
+		return false
+	}
+
+	if ua.Path != ub.Path || queryString(ua.Query()) != queryString(ub.Query()) {
+		return false
+	}
+
+	ba, err := bodyDecode(a.Body, a.BodyEncoding)
+	if err != nil {
+		return false
+	}
+
+	bb, err := bodyDecode(b.Body, b.BodyEncoding)
+	if err != nil {
+		return false
+	}
+
+	return bodyNormalize(ba) == bodyNormalize(bb)
+}
+
+func queryString(q url.Values) string {
+
+	var ks []string
+
+	for k, vs := range q {
+		sort.Strings(vs)
+		ks = append(ks, k+"="+strings.Join(vs, ","))
+	}
+
+	sort.Strings(ks)
+
+	return strings.Join(ks, "&")
+}
+
+func bodyNormalize(s []byte) string {
+
+	var v interface{}
+
+	d := json.NewDecoder(bytes.NewReader(s))
+	d.UseNumber()
+
+	if err := d.Decode(&v); err != nil {
+		return string(s)
+	}
+
+	b, err := json.Marshal(v)
+	if err != nil {
+		return string(s)
+	}
+
+	return string(b)
+}
+
+// readBody reads body and replaces it with a new reader with the same content
+func readBody(body *io.ReadCloser) ([]byte, error) {
+
+	if *body == nil || *body == http.NoBody {
+		return nil, nil
+	}
+
+	b, err := io.ReadAll(*body)
+	(*body).Close()
+	if err != nil {
+		return nil, err
+	}
+
+	*body = io.NopCloser(bytes.NewReader(b))
+
+	return b, nil
+}
@@ -0,0 +1,234 @@
+package recorder
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	redmine "github.com/nixys/nxs-go-redmine/v5"
+	"github.com/nixys/nxs-go-redmine/v5/redminetest"
+)
+
+func TestRecordReplay(t *testing.T) {
+
+	path := filepath.Join(t.TempDir(), "fixture.json")
+
+	// Record
+	s := redminetest.Init(redminetest.Settings{})
+
+	rec, err := Init(
+		Settings{
+			Mode:         ModeRecord,
+			Path:         path,
+			RedactFields: []string{"api_key", "mail"},
+		},
+	)
+	if err != nil {
+		t.Fatal("Recorder init error:", err)
+	}
+
+	p, err := testProjectCreateGet(rec, s.URL())
+	if err != nil {
+		t.Fatal("Record error:", err)
+	}
+
+	s.Close()
+
+	if err := rec.Save(); err != nil {
+		t.Fatal("Recorder save error:", err)
+	}
+
+	b, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal("Fixture read error:", err)
+	}
+
+	for _, e := range []string{redminetest.APIKeyDefault, "admin@example.net"} {
+		if strings.Contains(string(b), e) == true {
+			t.Fatalf("Fixture error: %q is not redacted", e)
+		}
+	}
+
+	// Replay with the server stopped
+	rep, err := Init(
+		Settings{
+			Mode:         ModeReplay,
+			Path:         path,
+			RedactFields: []string{"api_key", "mail"},
+		},
+	)
+	if err != nil {
+		t.Fatal("Recorder init error:", err)
+	}
+
+	pr, err := testProjectCreateGet(rep, "http://redmine.invalid")
+	if err != nil {
+		t.Fatal("Replay error:", err)
+	}
+
+	if pr.ID != p.ID || pr.Identifier != p.Identifier {
+		t.Fatalf("Replay error: expected project %+v, got %+v", p, pr)
+	}
+
+	if len(rep.Unused()) != 0 {
+		t.Fatal("Replay error: not all interactions replayed")
+	}
+
+	// Unmatched request
+	r := redmine.Init(
+		redmine.Settings{
+			Endpoint:   "http://redmine.invalid",
+			APIKey:     redminetest.APIKeyDefault,
+			HTTPClient: rep.Client(),
+		},
+	)
+
+	if _, _, err := r.ProjectSingleGet("unknown", redmine.ProjectSingleGetRequest{}); err == nil || strings.Contains(err.Error(), "no recorded interaction") == false {
+		t.Fatal("Replay error: expected unmatched request error, got:", err)
+	}
+}
+
+func testProjectCreateGet(rec *Recorder, endpoint string) (redmine.ProjectObject, error) {
+
+	r := redmine.Init(
+		redmine.Settings{
+			Endpoint:   endpoint,
+			APIKey:     redminetest.APIKeyDefault,
+			HTTPClient: rec.Client(),
+		},
+	)
+
+	if _, _, err := r.UserCurrentGet(redmine.UserCurrentGetRequest{}); err != nil {
+		return redmine.ProjectObject{}, err
+	}
+
+	if _, _, err := r.ProjectCreate(
+		redmine.ProjectCreate{
+			Project: redmine.ProjectCreateObject{
+				Name:       "Test project",
+				Identifier: "test-project",
+			},
+		},
+	); err != nil {
+		return redmine.ProjectObject{}, err
+	}
+
+	p, _, err := r.ProjectSingleGet("test-project", redmine.ProjectSingleGetRequest{})
+
+	return p, err
+}
+
+func TestRecordReplayAttachments(t *testing.T) {
+
+	path := filepath.Join(t.TempDir(), "fixture.json")
+	content := []byte{0xff, 0xfe, 0x00, 0x80, 'b', 'i', 'n'}
+
+	// Record
+	s := redminetest.Init(redminetest.Settings{})
+
+	rec, err := Init(Settings{Mode: ModeRecord, Path: path})
+	if err != nil {
+		t.Fatal("Recorder init error:", err)
+	}
+
+	p, _, err := s.Context().ProjectCreate(redmine.ProjectCreate{Project: redmine.ProjectCreateObject{Name: "Test project", Identifier: "test-project"}})
+	if err != nil {
+		t.Fatal("Project create error:", err)
+	}
+
+	b, err := testAttachmentUploadDownload(rec, s.URL(), p.ID, content)
+	if err != nil {
+		t.Fatal("Record error:", err)
+	}
+
+	s.Close()
+
+	if bytes.Equal(b, content) == false {
+		t.Fatal("Record error: unexpected downloaded content:", b)
+	}
+
+	if err := rec.Save(); err != nil {
+		t.Fatal("Recorder save error:", err)
+	}
+
+	// Replay with the server stopped
+	rep, err := Init(Settings{Mode: ModeReplay, Path: path})
+	if err != nil {
+		t.Fatal("Recorder init error:", err)
+	}
+
+	if b, err = testAttachmentUploadDownload(rep, "http://redmine.invalid", p.ID, content); err != nil {
+		t.Fatal("Replay error:", err)
+	}
+
+	if bytes.Equal(b, content) == false {
+		t.Fatal("Replay error: unexpected downloaded content:", b)
+	}
+
+	if len(rep.Unused()) != 0 {
+		t.Fatal("Replay error: not all interactions replayed")
+	}
+
+	// Request passed to recorder is not modified
+	body := io.NopCloser(bytes.NewReader(content))
+
+	req, err := http.NewRequest(http.MethodPost, "http://redmine.invalid/uploads.json", body)
+	if err != nil {
+		t.Fatal("Request create error:", err)
+	}
+
+	rep.RoundTrip(req)
+
+	if req.Body != body {
+		t.Fatal("Replay error: request body is replaced")
+	}
+}
+
+func testAttachmentUploadDownload(rec *Recorder, endpoint string, projectID int64, content []byte) ([]byte, error) {
+
+	r := redmine.Init(
+		redmine.Settings{
+			Endpoint:   endpoint,
+			APIKey:     redminetest.APIKeyDefault,
+			HTTPClient: rec.Client(),
+		},
+	)
+
+	up, _, err := r.AttachmentUploadStream(bytes.NewReader(content), "file.bin")
+	if err != nil {
+		return nil, err
+	}
+
+	i, _, err := r.IssueCreate(redmine.IssueCreate{
+		Issue: redmine.IssueCreateObject{
+			ProjectID: projectID,
+			Subject:   "Attachment",
+			Uploads:   &[]redmine.AttachmentUploadObject{up},
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	i, _, err = r.IssueSingleGet(i.ID, redmine.IssueSingleGetRequest{Includes: []redmine.IssueInclude{redmine.IssueIncludeAttachments}})
+	if err != nil {
+		return nil, err
+	}
+
+	if i.Attachments == nil || len(*i.Attachments) != 1 {
+		return nil, fmt.Errorf("attachment is not added")
+	}
+
+	f, _, _, err := r.AttachmentDownloadStream((*i.Attachments)[0].ID)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return io.ReadAll(f)
+}
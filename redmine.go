@@ -8,6 +8,7 @@ import (
 	"net/http"
 	"net/url"
 	"strings"
+	"time"
 
 	"github.com/mitchellh/mapstructure"
 )
@@ -18,15 +19,23 @@ const (
 
 type StatusCode int64
 
+// decodeHook converts raw API values into library types while decoding responses
+var decodeHook = mapstructure.ComposeDecodeHookFunc(textUnmarshalerHook, customFieldValueHook)
+
 type Settings struct {
-	Endpoint string
-	APIKey   string
+	Endpoint   string
+	APIKey     string
+	HTTPClient *http.Client // Client used to make requests. `http.DefaultClient` if not set
+
+	MetadataTTL time.Duration // Metadata cache TTL (see `Context.Metadata`). 10 minutes if not set
 }
 
 // Context struct used for store settings to communicate with Redmine API
 type Context struct {
-	endpoint string
-	apiKey   string
+	endpoint   string
+	apiKey     string
+	httpClient *http.Client
+	metadata   *Metadata
 }
 
 // IDName used as embedded struct for other structs within package
@@ -40,10 +49,16 @@ type errorsResult struct {
 }
 
 func Init(s Settings) *Context {
-	return &Context{
-		endpoint: s.Endpoint,
-		apiKey:   s.APIKey,
+
+	r := &Context{
+		endpoint:   s.Endpoint,
+		apiKey:     s.APIKey,
+		httpClient: s.HTTPClient,
 	}
+
+	r.metadata = metadataInit(r, s.MetadataTTL)
+
+	return r
 }
 
 // SetAPIKey is used to set Redmine API key
@@ -56,6 +71,19 @@ func (r *Context) SetEndpoint(endpoint string) {
 	r.endpoint = endpoint
 }
 
+// SetHTTPClient is used to set HTTP client to make requests to Redmine API
+// (e.g. with custom transport, timeouts or proxy)
+func (r *Context) SetHTTPClient(c *http.Client) {
+	r.httpClient = c
+}
+
+func (r *Context) client() *http.Client {
+	if r.httpClient == nil {
+		return http.DefaultClient
+	}
+	return r.httpClient
+}
+
 func (r *Context) Get(out interface{}, uri url.URL, statusExpected StatusCode) (StatusCode, error) {
 
 	var er errorsResult
@@ -72,7 +100,7 @@ func (r *Context) Get(out interface{}, uri url.URL, statusExpected StatusCode) (
 	req.Header.Add("X-Redmine-API-Key", r.apiKey)
 
 	// Make request
-	res, err := http.DefaultClient.Do(req)
+	res, err := r.client().Do(req)
 	if err != nil {
 		return 0, err
 	}
@@ -98,6 +126,7 @@ func (r *Context) Get(out interface{}, uri url.URL, statusExpected StatusCode) (
 
 			dM, err := mapstructure.NewDecoder(&mapstructure.DecoderConfig{
 				WeaklyTypedInput: true,
+				DecodeHook:       decodeHook,
 				Result:           out,
 				TagName:          "json",
 			})
@@ -146,7 +175,7 @@ func (r *Context) alter(method string, in interface{}, out interface{}, uri url.
 	req.Header.Add("X-Redmine-API-Key", r.apiKey)
 
 	// Make request
-	res, err := http.DefaultClient.Do(req)
+	res, err := r.client().Do(req)
 	if err != nil {
 		return 0, err
 	}
@@ -173,6 +202,7 @@ func (r *Context) alter(method string, in interface{}, out interface{}, uri url.
 
 			dM, err := mapstructure.NewDecoder(&mapstructure.DecoderConfig{
 				WeaklyTypedInput: true,
+				DecodeHook:       decodeHook,
 				Result:           out,
 				TagName:          "json",
 			})
@@ -206,7 +236,7 @@ func (r *Context) uploadFile(f io.Reader, out interface{}, uri url.URL, statusEx
 	req.Header.Add("X-Redmine-API-Key", r.apiKey)
 
 	// Make request
-	res, err := http.DefaultClient.Do(req)
+	res, err := r.client().Do(req)
 	if err != nil {
 		return 0, err
 	}
@@ -246,7 +276,7 @@ func (r *Context) downloadFile(url string, statusExpected StatusCode) (io.ReadCl
 	req.Header.Add("X-Redmine-API-Key", r.apiKey)
 
 	// Make request
-	res, err := http.DefaultClient.Do(req)
+	res, err := r.client().Do(req)
 	if err != nil {
 		return nil, 0, err
 	}
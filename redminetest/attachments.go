@@ -0,0 +1,163 @@
+package redminetest
+
+import (
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+type attachment struct {
+	id          int64
+	fileName    string
+	contentType string
+	description string
+	content     []byte
+	authorID    int64
+	attached    bool
+	createdOn   time.Time
+}
+
+type uploadIn struct {
+	Token       string `json:"token"`
+	Filename    string `json:"filename"`
+	ContentType string `json:"content_type"`
+	Description string `json:"description"`
+}
+
+func (db *database) attachmentRender(a *attachment, endpoint string) map[string]interface{} {
+
+	n, _ := db.principalName(a.authorID)
+
+	return map[string]interface{}{
+		"id":           a.id,
+		"filename":     a.fileName,
+		"filesize":     len(a.content),
+		"content_type": a.contentType,
+		"description":  a.description,
+		"content_url":  endpoint + "/attachments/download/" + strconv.FormatInt(a.id, 10) + "/" + url.PathEscape(a.fileName),
+		"author":       idName(a.authorID, n),
+		"created_on":   timeFormat(a.createdOn),
+	}
+}
+
+func (db *database) attachmentsRender(ids []int64) []interface{} {
+
+	as := []interface{}{}
+
+	for _, id := range ids {
+		if a, b := db.attachments[id]; b == true {
+			as = append(as, db.attachmentRender(a, db.endpoint))
+		}
+	}
+
+	return as
+}
+
+// uploadsAttach marks uploaded files with specified tokens as attached
+// and returns IDs of these attachments. Unknown tokens are ignored
+func (db *database) uploadsAttach(uploads []uploadIn) []int64 {
+
+	var ids []int64
+
+	for _, u := range uploads {
+
+		id, b := db.uploads[u.Token]
+		if b == false {
+			continue
+		}
+
+		a := db.attachments[id]
+		if a == nil {
+			continue
+		}
+
+		if u.Filename != "" {
+			a.fileName = u.Filename
+		}
+
+		if u.ContentType != "" {
+			a.contentType = u.ContentType
+		}
+
+		a.description = u.Description
+		a.attached = true
+
+		delete(db.uploads, u.Token)
+
+		ids = append(ids, a.id)
+	}
+
+	return ids
+}
+
+func (s *Server) upload(w http.ResponseWriter, r *http.Request, _ []string) {
+
+	if r.Header.Get("Content-Type") != "application/octet-stream" {
+		writeStatus(w, http.StatusNotAcceptable)
+		return
+	}
+
+	content, err := io.ReadAll(r.Body)
+	if err != nil {
+		writeStatus(w, http.StatusBadRequest)
+		return
+	}
+
+	a := &attachment{
+		id:          s.db.nextID(),
+		fileName:    r.URL.Query().Get("filename"),
+		contentType: "application/octet-stream",
+		content:     content,
+		authorID:    s.db.currentUserID,
+		createdOn:   s.now(),
+	}
+
+	if a.fileName == "" {
+		a.fileName = "upload"
+	}
+
+	token := strconv.FormatInt(a.id, 10) + "." + apiKeyGenerate()
+
+	s.db.attachments[a.id] = a
+	s.db.uploads[token] = a.id
+
+	writeJSON(w, http.StatusCreated, map[string]interface{}{
+		"upload": map[string]interface{}{
+			"id":    a.id,
+			"token": token,
+		},
+	})
+}
+
+func (s *Server) attachmentGet(w http.ResponseWriter, r *http.Request, p []string) {
+
+	id, _ := parseID(p[1])
+
+	a, b := s.db.attachments[id]
+	if b == false || a.attached == false {
+		writeStatus(w, http.StatusNotFound)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"attachment": s.db.attachmentRender(a, s.db.endpoint),
+	})
+}
+
+func (s *Server) attachmentDownload(w http.ResponseWriter, r *http.Request, p []string) {
+
+	id, _ := parseID(p[2])
+
+	a, b := s.db.attachments[id]
+	if b == false || a.attached == false {
+		writeStatus(w, http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", a.contentType)
+	w.Header().Set("Content-Length", strconv.Itoa(len(a.content)))
+	w.WriteHeader(http.StatusOK)
+	w.Write(a.content)
+}
@@ -0,0 +1,277 @@
+package redminetest
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"strconv"
+	"time"
+
+	redmine "github.com/nixys/nxs-go-redmine/v5"
+)
+
+type customFieldIn struct {
+	ID    int64       `json:"id"`
+	Value interface{} `json:"value"`
+}
+
+// CustomFieldAdd adds custom field definition into fake server and returns its ID.
+// Fields `ID`, `Name`, `CustomizedType`, `FieldFormat`, `Multiple`, `IsRequired`,
+// `PossibleValues` and `Trackers` are taken into account. ID is generated if not set
+func (s *Server) CustomFieldAdd(cf redmine.CustomFieldObject) int64 {
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	c := customField{
+		id:             cf.ID,
+		name:           cf.Name,
+		customizedType: cf.CustomizedType,
+		fieldFormat:    cf.FieldFormat,
+		multiple:       cf.Multiple,
+		isRequired:     cf.IsRequired,
+	}
+
+	if c.id == 0 {
+		c.id = s.db.nextID()
+	}
+
+	if c.customizedType == "" {
+		c.customizedType = "issue"
+	}
+
+	if c.fieldFormat == "" {
+		c.fieldFormat = "string"
+	}
+
+	if cf.PossibleValues != nil {
+		for _, v := range *cf.PossibleValues {
+			c.possibleValues = append(c.possibleValues, v.Value)
+		}
+	}
+
+	for _, t := range cf.Trackers {
+		c.trackers = append(c.trackers, t.ID)
+	}
+
+	s.db.customFields = append(s.db.customFields, c)
+
+	return c.id
+}
+
+func (s *Server) customFieldsList(w http.ResponseWriter, r *http.Request, _ []string) {
+
+	cs := []interface{}{}
+
+	for _, c := range s.db.customFields {
+
+		o := map[string]interface{}{
+			"id":              c.id,
+			"name":            c.name,
+			"customized_type": c.customizedType,
+			"field_format":    c.fieldFormat,
+			"regexp":          "",
+			"min_length":      nil,
+			"max_length":      nil,
+			"is_required":     c.isRequired,
+			"is_filter":       true,
+			"searchable":      false,
+			"multiple":        c.multiple,
+			"default_value":   "",
+			"visible":         true,
+		}
+
+		if c.fieldFormat == "list" {
+			pvs := []interface{}{}
+			for _, v := range c.possibleValues {
+				pvs = append(pvs, map[string]interface{}{
+					"value": v,
+					"label": v,
+				})
+			}
+			o["possible_values"] = pvs
+		}
+
+		if c.customizedType == "issue" {
+			ts := []interface{}{}
+			for _, id := range c.trackers {
+				if t := s.db.tracker(id); t != nil {
+					ts = append(ts, idName(t.id, t.name))
+				}
+			}
+			o["trackers"] = ts
+		}
+
+		o["roles"] = []interface{}{}
+
+		cs = append(cs, o)
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"custom_fields": cs,
+	})
+}
+
+// customFieldAvailable checks custom field is available for object of specified type
+// (and tracker for issues)
+func (c customField) available(customizedType string, trackerID int64) bool {
+
+	if c.customizedType != customizedType {
+		return false
+	}
+
+	if customizedType != "issue" {
+		return true
+	}
+
+	for _, t := range c.trackers {
+		if t == trackerID {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (db *database) customFieldsRender(customizedType string, values map[int64][]string, trackerID int64) []interface{} {
+
+	cs := []interface{}{}
+
+	for _, c := range db.customFields {
+
+		if c.available(customizedType, trackerID) == false {
+			continue
+		}
+
+		o := map[string]interface{}{
+			"id":   c.id,
+			"name": c.name,
+		}
+
+		if c.multiple == true {
+			o["multiple"] = true
+			o["value"] = append([]string{}, values[c.id]...)
+		} else if vs := values[c.id]; len(vs) > 0 {
+			o["value"] = vs[0]
+		} else {
+			o["value"] = ""
+		}
+
+		cs = append(cs, o)
+	}
+
+	return cs
+}
+
+// customFieldsApply validates and applies incoming custom field values
+func (db *database) customFieldsApply(values map[int64][]string, customizedType string, trackerID int64, in []customFieldIn) []string {
+
+	var errs []string
+
+	for _, e := range in {
+
+		c := db.customField(e.ID)
+		if c == nil || c.available(customizedType, trackerID) == false {
+			// Redmine silently ignores unavailable custom fields
+			continue
+		}
+
+		var vs []string
+
+		switch v := e.Value.(type) {
+		case nil:
+		case []interface{}:
+			for _, i := range v {
+				vs = append(vs, fmt.Sprint(i))
+			}
+		default:
+			vs = append(vs, fmt.Sprint(v))
+		}
+
+		if len(vs) > 1 && c.multiple == false {
+			errs = append(errs, c.name+" is invalid")
+			continue
+		}
+
+		var ok []string
+
+		for _, v := range vs {
+
+			if v == "" {
+				continue
+			}
+
+			if err := c.validate(v); err != "" {
+				errs = append(errs, c.name+" "+err)
+				continue
+			}
+
+			ok = append(ok, v)
+		}
+
+		if len(ok) == 0 {
+			delete(values, c.id)
+		} else {
+			values[c.id] = ok
+		}
+	}
+
+	return errs
+}
+
+func (db *database) customFieldsRequired(values map[int64][]string, customizedType string, trackerID int64) []string {
+
+	var errs []string
+
+	for _, c := range db.customFields {
+		if c.isRequired == true && c.available(customizedType, trackerID) == true && len(values[c.id]) == 0 {
+			errs = append(errs, c.name+" cannot be blank")
+		}
+	}
+
+	return errs
+}
+
+var customFieldIntRegexp = regexp.MustCompile(`^[+-]?\d+$`)
+
+func (c customField) validate(v string) string {
+
+	switch c.fieldFormat {
+	case "int", "user", "version":
+		if customFieldIntRegexp.MatchString(v) == false {
+			return "is not a number"
+		}
+	case "float":
+		if _, err := strconv.ParseFloat(v, 64); err != nil {
+			return "is invalid"
+		}
+	case "date":
+		if _, err := time.Parse(dateFormat, v); err != nil {
+			return "is not a valid date"
+		}
+	case "bool":
+		if v != "0" && v != "1" {
+			return "is not included in the list"
+		}
+	case "list":
+		for _, p := range c.possibleValues {
+			if p == v {
+				return ""
+			}
+		}
+		return "is not included in the list"
+	}
+
+	return ""
+}
+
+func copyCustomFields(values map[int64][]string) map[int64][]string {
+
+	c := make(map[int64][]string)
+
+	for k, v := range values {
+		c[k] = append([]string{}, v...)
+	}
+
+	return c
+}
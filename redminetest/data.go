@@ -0,0 +1,246 @@
+package redminetest
+
+import (
+	"sort"
+	"time"
+)
+
+// Seeded data
+const (
+	APIKeyDefault = "redminetest"
+
+	AdminID    int64 = 1
+	AdminLogin       = "admin"
+
+	StatusNewID        int64 = 1
+	StatusInProgressID int64 = 2
+	StatusResolvedID   int64 = 3
+	StatusFeedbackID   int64 = 4
+	StatusClosedID     int64 = 5
+	StatusRejectedID   int64 = 6
+
+	TrackerBugID     int64 = 1
+	TrackerFeatureID int64 = 2
+	TrackerSupportID int64 = 3
+
+	PriorityLowID    int64 = 1
+	PriorityNormalID int64 = 2
+	PriorityHighID   int64 = 3
+
+	ActivityDesignID      int64 = 8
+	ActivityDevelopmentID int64 = 9
+
+	DocumentCategoryUserID      int64 = 10
+	DocumentCategoryTechnicalID int64 = 11
+
+	RoleManagerID   int64 = 3
+	RoleDeveloperID int64 = 4
+	RoleReporterID  int64 = 5
+)
+
+type database struct {
+	seq           int64
+	endpoint      string
+	currentUserID int64
+
+	statuses           []status
+	trackers           []enumeration
+	priorities         []enumeration
+	activities         []enumeration
+	documentCategories []enumeration
+	roles              map[int64]string
+	customFields       []customField
+
+	projects    map[int64]*project
+	issues      map[int64]*issue
+	users       map[int64]*user
+	groups      map[int64]*group
+	memberships map[int64]*membership
+	timeEntries map[int64]*timeEntry
+	versions    map[int64]*version
+	categories  map[int64]*issueCategory
+	attachments map[int64]*attachment
+	uploads     map[string]int64
+}
+
+type status struct {
+	id       int64
+	name     string
+	isClosed bool
+}
+
+type enumeration struct {
+	id        int64
+	name      string
+	isDefault bool
+}
+
+type customField struct {
+	id             int64
+	name           string
+	customizedType string
+	fieldFormat    string
+	multiple       bool
+	isRequired     bool
+	possibleValues []string
+	trackers       []int64
+}
+
+func seed(apiKey string, now time.Time) *database {
+
+	db := &database{
+		seq: 100,
+
+		statuses: []status{
+			{StatusNewID, "New", false},
+			{StatusInProgressID, "In Progress", false},
+			{StatusResolvedID, "Resolved", false},
+			{StatusFeedbackID, "Feedback", false},
+			{StatusClosedID, "Closed", true},
+			{StatusRejectedID, "Rejected", true},
+		},
+		trackers: []enumeration{
+			{TrackerBugID, "Bug", false},
+			{TrackerFeatureID, "Feature", false},
+			{TrackerSupportID, "Support", false},
+		},
+		priorities: []enumeration{
+			{PriorityLowID, "Low", false},
+			{PriorityNormalID, "Normal", true},
+			{PriorityHighID, "High", false},
+		},
+		activities: []enumeration{
+			{ActivityDesignID, "Design", false},
+			{ActivityDevelopmentID, "Development", true},
+		},
+		documentCategories: []enumeration{
+			{DocumentCategoryUserID, "User documentation", false},
+			{DocumentCategoryTechnicalID, "Technical documentation", false},
+		},
+		roles: map[int64]string{
+			RoleManagerID:   "Manager",
+			RoleDeveloperID: "Developer",
+			RoleReporterID:  "Reporter",
+		},
+
+		projects:    make(map[int64]*project),
+		issues:      make(map[int64]*issue),
+		users:       make(map[int64]*user),
+		groups:      make(map[int64]*group),
+		memberships: make(map[int64]*membership),
+		timeEntries: make(map[int64]*timeEntry),
+		versions:    make(map[int64]*version),
+		categories:  make(map[int64]*issueCategory),
+		attachments: make(map[int64]*attachment),
+		uploads:     make(map[string]int64),
+	}
+
+	db.users[AdminID] = &user{
+		id:        AdminID,
+		login:     AdminLogin,
+		admin:     true,
+		firstName: "Redmine",
+		lastName:  "Admin",
+		mail:      "admin@example.net",
+		status:    1,
+		apiKey:    apiKey,
+		createdOn: now,
+	}
+
+	return db
+}
+
+func (db *database) nextID() int64 {
+	db.seq++
+	return db.seq
+}
+
+func (db *database) userByAPIKey(key string) *user {
+
+	if key == "" {
+		return nil
+	}
+
+	for _, u := range db.users {
+		if u.apiKey == key && u.status == 1 {
+			return u
+		}
+	}
+
+	return nil
+}
+
+func (db *database) status(id int64) *status {
+	for i := range db.statuses {
+		if db.statuses[i].id == id {
+			return &db.statuses[i]
+		}
+	}
+	return nil
+}
+
+func (db *database) tracker(id int64) *enumeration {
+	return enumerationFind(db.trackers, id)
+}
+
+func (db *database) priority(id int64) *enumeration {
+	return enumerationFind(db.priorities, id)
+}
+
+func (db *database) activity(id int64) *enumeration {
+	return enumerationFind(db.activities, id)
+}
+
+func (db *database) customField(id int64) *customField {
+	for i := range db.customFields {
+		if db.customFields[i].id == id {
+			return &db.customFields[i]
+		}
+	}
+	return nil
+}
+
+// principalName returns name of user or group with specified ID
+func (db *database) principalName(id int64) (string, bool) {
+
+	if u, b := db.users[id]; b == true {
+		return u.name(), true
+	}
+
+	if g, b := db.groups[id]; b == true {
+		return g.name, true
+	}
+
+	return "", false
+}
+
+func enumerationFind(es []enumeration, id int64) *enumeration {
+	for i := range es {
+		if es[i].id == id {
+			return &es[i]
+		}
+	}
+	return nil
+}
+
+func idName(id int64, name string) map[string]interface{} {
+	return map[string]interface{}{
+		"id":   id,
+		"name": name,
+	}
+}
+
+func sortedIDs[T any](m map[int64]T) []int64 {
+
+	var ids []int64
+
+	for id := range m {
+		ids = append(ids, id)
+	}
+
+	sort.Slice(ids, func(i, j int) bool {
+		return ids[i] < ids[j]
+	})
+
+	return ids
+}
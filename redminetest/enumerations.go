@@ -0,0 +1,74 @@
+package redminetest
+
+import (
+	"net/http"
+)
+
+func enumerationsRender(es []enumeration) []interface{} {
+
+	os := []interface{}{}
+
+	for _, e := range es {
+		os = append(os, map[string]interface{}{
+			"id":         e.id,
+			"name":       e.name,
+			"is_default": e.isDefault,
+			"active":     true,
+		})
+	}
+
+	return os
+}
+
+func (s *Server) prioritiesList(w http.ResponseWriter, r *http.Request, _ []string) {
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"issue_priorities": enumerationsRender(s.db.priorities),
+	})
+}
+
+func (s *Server) activitiesList(w http.ResponseWriter, r *http.Request, _ []string) {
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"time_entry_activities": enumerationsRender(s.db.activities),
+	})
+}
+
+func (s *Server) documentCategoriesList(w http.ResponseWriter, r *http.Request, _ []string) {
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"document_categories": enumerationsRender(s.db.documentCategories),
+	})
+}
+
+func (s *Server) statusesList(w http.ResponseWriter, r *http.Request, _ []string) {
+
+	ss := []interface{}{}
+
+	for _, st := range s.db.statuses {
+		ss = append(ss, map[string]interface{}{
+			"id":        st.id,
+			"name":      st.name,
+			"is_closed": st.isClosed,
+		})
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"issue_statuses": ss,
+	})
+}
+
+func (s *Server) trackersList(w http.ResponseWriter, r *http.Request, _ []string) {
+
+	ts := []interface{}{}
+
+	for _, t := range s.db.trackers {
+		ts = append(ts, map[string]interface{}{
+			"id":             t.id,
+			"name":           t.name,
+			"default_status": idName(StatusNewID, s.db.status(StatusNewID).name),
+			"description":    nil,
+		})
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"trackers": ts,
+	})
+}
@@ -0,0 +1,190 @@
+package redminetest
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// filterValues splits filter values. Redmine accepts both `,` and `|` separators
+func filterValues(v string) []string {
+	return strings.FieldsFunc(v, func(r rune) bool {
+		return r == ',' || r == '|'
+	})
+}
+
+// filterID checks ID matches filter expression. Supported expressions:
+// `*` (any), `!*` (none), `1,2` (one of) and `!1,2` (none of).
+// Zero ID means the field is not set
+func filterID(expr string, id int64, me int64) bool {
+
+	switch expr {
+	case "":
+		return true
+	case "*":
+		return id != 0
+	case "!*":
+		return id == 0
+	}
+
+	neg := strings.HasPrefix(expr, "!")
+	if neg == true {
+		expr = expr[1:]
+	}
+
+	found := false
+
+	for _, v := range filterValues(expr) {
+
+		if v == "me" {
+			v = strconv.FormatInt(me, 10)
+		}
+
+		if n, b := parseID(v); b == true && n == id {
+			found = true
+			break
+		}
+	}
+
+	return found != neg
+}
+
+// filterInteger checks value of integer field (e.g. `issue_id`) matches filter
+// expression. As in Redmine values of `=` operator are comma-separated and only
+// the first `|`-separated value is taken, values of `!` operator are `|`-separated
+func filterInteger(expr string, n int64) bool {
+
+	switch expr {
+	case "", "*":
+		return true
+	case "!*":
+		return false
+	}
+
+	var vs []string
+
+	if strings.HasPrefix(expr, "!") == true {
+		vs = strings.Split(expr[1:], "|")
+	} else {
+		v, _, _ := strings.Cut(strings.TrimPrefix(expr, "="), "|")
+		vs = strings.Split(v, ",")
+	}
+
+	found := false
+
+	for _, v := range vs {
+		if i, err := strconv.ParseInt(strings.TrimSpace(v), 10, 64); err == nil && i == n {
+			found = true
+			break
+		}
+	}
+
+	return found != strings.HasPrefix(expr, "!")
+}
+
+// filterString checks string matches filter expression. Supported expressions:
+// `*`, `!*`, `~text` (contains), `!~text` (not contains), `^text` (starts with),
+// `$text` (ends with) and exact value optionally prefixed with `=`
+func filterString(expr string, s string) bool {
+
+	l := strings.ToLower(s)
+
+	switch {
+	case expr == "":
+		return true
+	case expr == "*":
+		return s != ""
+	case expr == "!*":
+		return s == ""
+	case strings.HasPrefix(expr, "!~"):
+		return strings.Contains(l, strings.ToLower(expr[2:])) == false
+	case strings.HasPrefix(expr, "~"):
+		return strings.Contains(l, strings.ToLower(expr[1:]))
+	case strings.HasPrefix(expr, "^"):
+		return strings.HasPrefix(l, strings.ToLower(expr[1:]))
+	case strings.HasPrefix(expr, "$"):
+		return strings.HasSuffix(l, strings.ToLower(expr[1:]))
+	case strings.HasPrefix(expr, "!"):
+		return s != expr[1:]
+	case strings.HasPrefix(expr, "="):
+		return s == expr[1:]
+	}
+
+	return s == expr
+}
+
+// filterStrings checks any of values matches filter expression
+func filterStrings(expr string, vs []string) bool {
+
+	switch expr {
+	case "":
+		return true
+	case "*":
+		return len(vs) > 0
+	case "!*":
+		return len(vs) == 0
+	}
+
+	neg := strings.HasPrefix(expr, "!") && strings.HasPrefix(expr, "!~") == false
+	if neg == true {
+		expr = expr[1:]
+	}
+
+	for _, v := range vs {
+		for _, e := range filterValues(expr) {
+			if filterString(e, v) == true {
+				return neg == false
+			}
+		}
+	}
+
+	return neg == true
+}
+
+// filterTime checks time matches filter expression. Supported expressions:
+// `>=value`, `<=value`, `><from|to`, `*`, `!*` and exact date.
+// Values may be either dates or RFC3339 timestamps
+func filterTime(expr string, t time.Time) bool {
+
+	switch {
+	case expr == "":
+		return true
+	case expr == "*":
+		return t.IsZero() == false
+	case expr == "!*":
+		return t.IsZero() == true
+	case t.IsZero() == true:
+		return false
+	case strings.HasPrefix(expr, "><"):
+		vs := strings.SplitN(expr[2:], "|", 2)
+		if len(vs) != 2 {
+			return false
+		}
+		return filterTime(">="+vs[0], t) && filterTime("<="+vs[1], t)
+	case strings.HasPrefix(expr, ">="):
+		from, _, b := filterTimeParse(expr[2:])
+		return b == true && t.Before(from) == false
+	case strings.HasPrefix(expr, "<="):
+		_, to, b := filterTimeParse(expr[2:])
+		return b == true && t.Before(to) == true
+	}
+
+	from, to, b := filterTimeParse(expr)
+
+	return b == true && t.Before(from) == false && t.Before(to) == true
+}
+
+// filterTimeParse parses filter value and returns half-open interval it covers.
+// Date covers the whole day, timestamp covers one second
+func filterTimeParse(v string) (time.Time, time.Time, bool) {
+
+	if t, err := time.Parse(time.RFC3339, v); err == nil {
+		return t, t.Add(time.Second), true
+	}
+
+	if t, err := time.Parse(dateFormat, v); err == nil {
+		return t, t.AddDate(0, 0, 1), true
+	}
+
+	return time.Time{}, time.Time{}, false
+}
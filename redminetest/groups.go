@@ -0,0 +1,287 @@
+package redminetest
+
+import (
+	"net/http"
+	"strings"
+)
+
+type group struct {
+	id    int64
+	name  string
+	users []int64
+}
+
+type groupIn struct {
+	Name    *string  `json:"name"`
+	UserIDs *[]int64 `json:"user_ids"`
+}
+
+func (g *group) member(userID int64) bool {
+	for _, id := range g.users {
+		if id == userID {
+			return true
+		}
+	}
+	return false
+}
+
+func (g *group) userDelete(userID int64) {
+
+	us := []int64{}
+
+	for _, id := range g.users {
+		if id != userID {
+			us = append(us, id)
+		}
+	}
+
+	g.users = us
+}
+
+func (db *database) group(id string) *group {
+
+	n, b := parseID(id)
+	if b == false {
+		return nil
+	}
+
+	return db.groups[n]
+}
+
+func (db *database) groupRender(g *group, is map[string]bool) map[string]interface{} {
+
+	o := map[string]interface{}{
+		"id":   g.id,
+		"name": g.name,
+	}
+
+	if is["users"] == true {
+		us := []interface{}{}
+		for _, id := range g.users {
+			if u, b := db.users[id]; b == true {
+				us = append(us, idName(u.id, u.name()))
+			}
+		}
+		o["users"] = us
+	}
+
+	if is["memberships"] == true {
+		o["memberships"] = db.principalMembershipsRender(g.id)
+	}
+
+	return o
+}
+
+func (s *Server) groupsList(w http.ResponseWriter, r *http.Request, _ []string) {
+
+	var items []interface{}
+
+	for _, id := range sortedIDs(s.db.groups) {
+		items = append(items, s.db.groupRender(s.db.groups[id], nil))
+	}
+
+	writeJSON(w, http.StatusOK, paged("groups", items, r))
+}
+
+func (s *Server) groupGet(w http.ResponseWriter, r *http.Request, p []string) {
+
+	g := s.db.group(p[1])
+	if g == nil {
+		writeStatus(w, http.StatusNotFound)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"group": s.db.groupRender(g, includes(r)),
+	})
+}
+
+func (s *Server) groupCreate(w http.ResponseWriter, r *http.Request, _ []string) {
+
+	var in struct {
+		Group groupIn `json:"group"`
+	}
+
+	if s.db.users[s.db.currentUserID].admin == false {
+		writeStatus(w, http.StatusForbidden)
+		return
+	}
+
+	if err := readJSON(r, &in); err != nil {
+		writeStatus(w, http.StatusBadRequest)
+		return
+	}
+
+	g := &group{
+		users: []int64{},
+	}
+
+	if errs := s.db.groupApply(g, in.Group); len(errs) > 0 {
+		writeErrors(w, errs...)
+		return
+	}
+
+	g.id = s.db.nextID()
+	s.db.groups[g.id] = g
+
+	s.db.membershipsInherit()
+
+	writeJSON(w, http.StatusCreated, map[string]interface{}{
+		"group": s.db.groupRender(g, nil),
+	})
+}
+
+func (s *Server) groupUpdate(w http.ResponseWriter, r *http.Request, p []string) {
+
+	var in struct {
+		Group groupIn `json:"group"`
+	}
+
+	g := s.db.group(p[1])
+	if g == nil {
+		writeStatus(w, http.StatusNotFound)
+		return
+	}
+
+	if s.db.users[s.db.currentUserID].admin == false {
+		writeStatus(w, http.StatusForbidden)
+		return
+	}
+
+	if err := readJSON(r, &in); err != nil {
+		writeStatus(w, http.StatusBadRequest)
+		return
+	}
+
+	upd := *g
+
+	if errs := s.db.groupApply(&upd, in.Group); len(errs) > 0 {
+		writeErrors(w, errs...)
+		return
+	}
+
+	*g = upd
+
+	s.db.membershipsInherit()
+
+	writeStatus(w, http.StatusNoContent)
+}
+
+func (s *Server) groupDelete(w http.ResponseWriter, r *http.Request, p []string) {
+
+	g := s.db.group(p[1])
+	if g == nil {
+		writeStatus(w, http.StatusNotFound)
+		return
+	}
+
+	if s.db.users[s.db.currentUserID].admin == false {
+		writeStatus(w, http.StatusForbidden)
+		return
+	}
+
+	for id, m := range s.db.memberships {
+		if m.principalID == g.id {
+			delete(s.db.memberships, id)
+		}
+	}
+
+	delete(s.db.groups, g.id)
+
+	s.db.membershipsInherit()
+
+	writeStatus(w, http.StatusNoContent)
+}
+
+func (s *Server) groupUserAdd(w http.ResponseWriter, r *http.Request, p []string) {
+
+	var in struct {
+		UserID int64 `json:"user_id"`
+	}
+
+	g := s.db.group(p[1])
+	if g == nil {
+		writeStatus(w, http.StatusNotFound)
+		return
+	}
+
+	if err := readJSON(r, &in); err != nil {
+		writeStatus(w, http.StatusBadRequest)
+		return
+	}
+
+	if _, b := s.db.users[in.UserID]; b == false {
+		writeStatus(w, http.StatusNotFound)
+		return
+	}
+
+	if g.member(in.UserID) == true {
+		writeErrors(w, "User is invalid")
+		return
+	}
+
+	g.users = append(g.users, in.UserID)
+
+	s.db.membershipsInherit()
+
+	writeStatus(w, http.StatusNoContent)
+}
+
+func (s *Server) groupUserDelete(w http.ResponseWriter, r *http.Request, p []string) {
+
+	g := s.db.group(p[1])
+	if g == nil {
+		writeStatus(w, http.StatusNotFound)
+		return
+	}
+
+	uid, b := parseID(p[3])
+	if b == false || g.member(uid) == false {
+		writeStatus(w, http.StatusNotFound)
+		return
+	}
+
+	g.userDelete(uid)
+
+	s.db.membershipsInherit()
+
+	writeStatus(w, http.StatusNoContent)
+}
+
+// groupApply applies incoming data to group and returns validation errors
+func (db *database) groupApply(g *group, in groupIn) []string {
+
+	var errs []string
+
+	if in.Name != nil {
+		g.name = *in.Name
+	}
+
+	switch {
+	case g.name == "":
+		errs = append(errs, "Name cannot be blank")
+	case func() bool {
+		for _, e := range db.groups {
+			if e.id != g.id && strings.EqualFold(e.name, g.name) == true {
+				return true
+			}
+		}
+		return false
+	}() == true:
+		errs = append(errs, "Name has already been taken")
+	}
+
+	if in.UserIDs != nil {
+		us := []int64{}
+		for _, id := range *in.UserIDs {
+			if _, b := db.users[id]; b == false {
+				errs = append(errs, "Users is invalid")
+				break
+			}
+			us = append(us, id)
+		}
+		g.users = us
+	}
+
+	return errs
+}
@@ -0,0 +1,213 @@
+package redminetest
+
+import (
+	"net/http"
+	"strings"
+)
+
+type issueCategory struct {
+	id           int64
+	projectID    int64
+	name         string
+	assignedToID int64
+}
+
+type issueCategoryIn struct {
+	Name         *string `json:"name"`
+	AssignedToID *int64  `json:"assigned_to_id"`
+}
+
+func (db *database) issueCategory(id string) *issueCategory {
+
+	n, b := parseID(id)
+	if b == false {
+		return nil
+	}
+
+	return db.categories[n]
+}
+
+func (db *database) issueCategoryRender(c *issueCategory) map[string]interface{} {
+
+	o := map[string]interface{}{
+		"id":      c.id,
+		"project": idName(c.projectID, db.projects[c.projectID].name),
+		"name":    c.name,
+	}
+
+	if n, b := db.principalName(c.assignedToID); b == true {
+		o["assigned_to"] = idName(c.assignedToID, n)
+	}
+
+	return o
+}
+
+// projectIssueCategories returns issue categories of project
+func (db *database) projectIssueCategories(projectID int64) []*issueCategory {
+
+	var cs []*issueCategory
+
+	for _, id := range sortedIDs(db.categories) {
+		if c := db.categories[id]; c.projectID == projectID {
+			cs = append(cs, c)
+		}
+	}
+
+	return cs
+}
+
+func (s *Server) issueCategoriesList(w http.ResponseWriter, r *http.Request, p []string) {
+
+	pr := s.db.project(p[1])
+	if pr == nil {
+		writeStatus(w, http.StatusNotFound)
+		return
+	}
+
+	items := []interface{}{}
+
+	for _, c := range s.db.projectIssueCategories(pr.id) {
+		items = append(items, s.db.issueCategoryRender(c))
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"issue_categories": items,
+		"total_count":      len(items),
+	})
+}
+
+func (s *Server) issueCategoryGet(w http.ResponseWriter, r *http.Request, p []string) {
+
+	c := s.db.issueCategory(p[1])
+	if c == nil {
+		writeStatus(w, http.StatusNotFound)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"issue_category": s.db.issueCategoryRender(c),
+	})
+}
+
+func (s *Server) issueCategoryCreate(w http.ResponseWriter, r *http.Request, p []string) {
+
+	var in struct {
+		IssueCategory issueCategoryIn `json:"issue_category"`
+	}
+
+	pr := s.db.project(p[1])
+	if pr == nil {
+		writeStatus(w, http.StatusNotFound)
+		return
+	}
+
+	if err := readJSON(r, &in); err != nil {
+		writeStatus(w, http.StatusBadRequest)
+		return
+	}
+
+	c := &issueCategory{
+		projectID: pr.id,
+	}
+
+	if errs := s.db.issueCategoryApply(c, in.IssueCategory); len(errs) > 0 {
+		writeErrors(w, errs...)
+		return
+	}
+
+	c.id = s.db.nextID()
+	s.db.categories[c.id] = c
+
+	writeJSON(w, http.StatusCreated, map[string]interface{}{
+		"issue_category": s.db.issueCategoryRender(c),
+	})
+}
+
+func (s *Server) issueCategoryUpdate(w http.ResponseWriter, r *http.Request, p []string) {
+
+	var in struct {
+		IssueCategory issueCategoryIn `json:"issue_category"`
+	}
+
+	c := s.db.issueCategory(p[1])
+	if c == nil {
+		writeStatus(w, http.StatusNotFound)
+		return
+	}
+
+	if err := readJSON(r, &in); err != nil {
+		writeStatus(w, http.StatusBadRequest)
+		return
+	}
+
+	upd := *c
+
+	if errs := s.db.issueCategoryApply(&upd, in.IssueCategory); len(errs) > 0 {
+		writeErrors(w, errs...)
+		return
+	}
+
+	*c = upd
+
+	writeStatus(w, http.StatusNoContent)
+}
+
+func (s *Server) issueCategoryDelete(w http.ResponseWriter, r *http.Request, p []string) {
+
+	c := s.db.issueCategory(p[1])
+	if c == nil {
+		writeStatus(w, http.StatusNotFound)
+		return
+	}
+
+	// Issues of deleted category are either reassigned or left without category
+	var reassign int64
+
+	if v := r.URL.Query().Get("reassign_to_id"); v != "" {
+		id, _ := parseID(v)
+		if n, b := s.db.categories[id]; b == true && n.projectID == c.projectID && n.id != c.id {
+			reassign = n.id
+		}
+	}
+
+	for _, i := range s.db.issues {
+		if i.categoryID == c.id {
+			i.categoryID = reassign
+		}
+	}
+
+	delete(s.db.categories, c.id)
+
+	writeStatus(w, http.StatusNoContent)
+}
+
+// issueCategoryApply applies incoming data to issue category and returns validation errors
+func (db *database) issueCategoryApply(c *issueCategory, in issueCategoryIn) []string {
+
+	var errs []string
+
+	if in.Name != nil {
+		c.name = *in.Name
+	}
+
+	if c.name == "" {
+		errs = append(errs, "Name cannot be blank")
+	} else {
+		for _, e := range db.categories {
+			if e.id != c.id && e.projectID == c.projectID && strings.EqualFold(e.name, c.name) == true {
+				errs = append(errs, "Name has already been taken")
+				break
+			}
+		}
+	}
+
+	if in.AssignedToID != nil {
+		if *in.AssignedToID != 0 && db.memberRoles(c.projectID, *in.AssignedToID) == nil {
+			errs = append(errs, "Assignee is invalid")
+		} else {
+			c.assignedToID = *in.AssignedToID
+		}
+	}
+
+	return errs
+}
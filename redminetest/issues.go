@@ -0,0 +1,1030 @@
+package redminetest
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type issue struct {
+	id             int64
+	projectID      int64
+	trackerID      int64
+	statusID       int64
+	priorityID     int64
+	authorID       int64
+	assignedToID   int64
+	categoryID     int64
+	fixedVersionID int64
+	parentID       int64
+	subject        string
+	description    string
+	startDate      string
+	dueDate        string
+	doneRatio      int64
+	isPrivate      bool
+	estimatedHours *float64
+	customFields   map[int64][]string
+	watchers       []int64
+	attachments    []int64
+	journals       []journal
+	createdOn      time.Time
+	updatedOn      time.Time
+	closedOn       time.Time
+}
+
+type journal struct {
+	id           int64
+	userID       int64
+	notes        string
+	privateNotes bool
+	createdOn    time.Time
+	details      []journalDetail
+}
+
+type journalDetail struct {
+	property string
+	name     string
+	oldValue string
+	newValue string
+}
+
+type issueIn struct {
+	ProjectID      *int64           `json:"project_id"`
+	TrackerID      *int64           `json:"tracker_id"`
+	StatusID       *int64           `json:"status_id"`
+	PriorityID     *int64           `json:"priority_id"`
+	Subject        *string          `json:"subject"`
+	Description    *string          `json:"description"`
+	StartDate      *string          `json:"start_date"`
+	DueDate        *string          `json:"due_date"`
+	DoneRatio      *int64           `json:"done_ratio"`
+	AssignedToID   clearableID      `json:"assigned_to_id"`
+	CategoryID     *int64           `json:"category_id"`
+	FixedVersionID *int64           `json:"fixed_version_id"`
+	ParentIssueID  *int64           `json:"parent_issue_id"`
+	CustomFields   *[]customFieldIn `json:"custom_fields"`
+	WatcherUserIDs *[]int64         `json:"watcher_user_ids"`
+	IsPrivate      *bool            `json:"is_private"`
+	EstimatedHours *float64         `json:"estimated_hours"`
+	Uploads        *[]uploadIn      `json:"uploads"`
+	Notes          *string          `json:"notes"`
+	PrivateNotes   *bool            `json:"private_notes"`
+}
+
+// clearableID is an ID attribute which is cleared with `null` or empty
+// string as Redmine does. Zero ID is not a valid value
+type clearableID struct {
+	set   bool
+	clear bool
+	id    int64
+}
+
+func (v *clearableID) UnmarshalJSON(b []byte) error {
+
+	v.set = true
+
+	if string(b) == "null" || string(b) == `""` {
+		v.clear = true
+		return nil
+	}
+
+	return json.Unmarshal(b, &v.id)
+}
+
+// issueSortFields maps sort criteria to issues compare functions
+var issueSortFields = map[string]func(db *database, a, b *issue) int{
+	"id": func(_ *database, a, b *issue) int {
+		return compareInt(a.id, b.id)
+	},
+	"subject": func(_ *database, a, b *issue) int {
+		return strings.Compare(a.subject, b.subject)
+	},
+	"project": func(db *database, a, b *issue) int {
+		return strings.Compare(db.projects[a.projectID].name, db.projects[b.projectID].name)
+	},
+	"tracker": func(_ *database, a, b *issue) int {
+		return compareInt(a.trackerID, b.trackerID)
+	},
+	"status": func(_ *database, a, b *issue) int {
+		return compareInt(a.statusID, b.statusID)
+	},
+	"priority": func(_ *database, a, b *issue) int {
+		return compareInt(a.priorityID, b.priorityID)
+	},
+	"assigned_to": func(db *database, a, b *issue) int {
+		an, _ := db.principalName(a.assignedToID)
+		bn, _ := db.principalName(b.assignedToID)
+		return strings.Compare(an, bn)
+	},
+	"author": func(db *database, a, b *issue) int {
+		an, _ := db.principalName(a.authorID)
+		bn, _ := db.principalName(b.authorID)
+		return strings.Compare(an, bn)
+	},
+	"start_date": func(_ *database, a, b *issue) int {
+		return strings.Compare(a.startDate, b.startDate)
+	},
+	"due_date": func(_ *database, a, b *issue) int {
+		return strings.Compare(a.dueDate, b.dueDate)
+	},
+	"done_ratio": func(_ *database, a, b *issue) int {
+		return compareInt(a.doneRatio, b.doneRatio)
+	},
+	"created_on": func(_ *database, a, b *issue) int {
+		return a.createdOn.Compare(b.createdOn)
+	},
+	"updated_on": func(_ *database, a, b *issue) int {
+		return a.updatedOn.Compare(b.updatedOn)
+	},
+	"closed_on": func(_ *database, a, b *issue) int {
+		return a.closedOn.Compare(b.closedOn)
+	},
+}
+
+func compareInt(a, b int64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+func (db *database) issue(id string) *issue {
+
+	n, b := parseID(id)
+	if b == false {
+		return nil
+	}
+
+	return db.issues[n]
+}
+
+// issueVisible checks issue is visible for current user
+func (db *database) issueVisible(i *issue) bool {
+
+	p := db.projects[i.projectID]
+	if p == nil || p.status == projectStatusArchived {
+		return false
+	}
+
+	u := db.users[db.currentUserID]
+	if u != nil && u.admin == true {
+		return true
+	}
+
+	if p.isPublic == false && db.memberRoles(p.id, db.currentUserID) == nil {
+		return false
+	}
+
+	if i.isPrivate == true && i.authorID != db.currentUserID && i.assignedToID != db.currentUserID {
+		return false
+	}
+
+	return true
+}
+
+func (db *database) issueRender(i *issue, is map[string]bool, single bool) map[string]interface{} {
+
+	st := db.status(i.statusID)
+
+	o := map[string]interface{}{
+		"id":      i.id,
+		"project": idName(i.projectID, db.projects[i.projectID].name),
+		"tracker": idName(i.trackerID, db.tracker(i.trackerID).name),
+		"status": map[string]interface{}{
+			"id":        st.id,
+			"name":      st.name,
+			"is_closed": st.isClosed,
+		},
+		"priority":              idName(i.priorityID, db.priority(i.priorityID).name),
+		"subject":               i.subject,
+		"description":           i.description,
+		"start_date":            nullString(i.startDate),
+		"due_date":              nullString(i.dueDate),
+		"done_ratio":            i.doneRatio,
+		"is_private":            i.isPrivate,
+		"estimated_hours":       i.estimatedHours,
+		"total_estimated_hours": db.issueTotalEstimatedHours(i),
+		"spent_hours":           db.issueSpentHours(i.id),
+		"total_spent_hours":     db.issueTotalSpentHours(i),
+		"custom_fields":         db.customFieldsRender("issue", i.customFields, i.trackerID),
+		"created_on":            timeFormat(i.createdOn),
+		"updated_on":            timeFormat(i.updatedOn),
+		"closed_on":             nil,
+	}
+
+	if n, b := db.principalName(i.authorID); b == true {
+		o["author"] = idName(i.authorID, n)
+	}
+
+	if n, b := db.principalName(i.assignedToID); b == true {
+		o["assigned_to"] = idName(i.assignedToID, n)
+	}
+
+	if c, b := db.categories[i.categoryID]; b == true {
+		o["category"] = idName(c.id, c.name)
+	}
+
+	if v, b := db.versions[i.fixedVersionID]; b == true {
+		o["fixed_version"] = idName(v.id, v.name)
+	}
+
+	if i.parentID != 0 {
+		o["parent"] = map[string]interface{}{
+			"id": i.parentID,
+		}
+	}
+
+	if i.closedOn.IsZero() == false {
+		o["closed_on"] = timeFormat(i.closedOn)
+	}
+
+	if is["attachments"] == true {
+		o["attachments"] = db.attachmentsRender(i.attachments)
+	}
+
+	if is["relations"] == true {
+		o["relations"] = []interface{}{}
+	}
+
+	// Following includes are available only for single issue
+	if single == false {
+		return o
+	}
+
+	if is["children"] == true {
+		o["children"] = db.issueChildrenRender(i.id)
+	}
+
+	if is["changesets"] == true {
+		o["changesets"] = []interface{}{}
+	}
+
+	if is["journals"] == true {
+		js := []interface{}{}
+		for _, j := range i.journals {
+			js = append(js, db.journalRender(j))
+		}
+		o["journals"] = js
+	}
+
+	if is["watchers"] == true {
+		ws := []interface{}{}
+		for _, id := range i.watchers {
+			if n, b := db.principalName(id); b == true {
+				ws = append(ws, idName(id, n))
+			}
+		}
+		o["watchers"] = ws
+	}
+
+	if is["allowed_statuses"] == true {
+		ss := []interface{}{}
+		for _, s := range db.statuses {
+			ss = append(ss, map[string]interface{}{
+				"id":        s.id,
+				"name":      s.name,
+				"is_closed": s.isClosed,
+			})
+		}
+		o["allowed_statuses"] = ss
+	}
+
+	return o
+}
+
+func (db *database) journalRender(j journal) map[string]interface{} {
+
+	ds := []interface{}{}
+	for _, d := range j.details {
+		ds = append(ds, map[string]interface{}{
+			"property":  d.property,
+			"name":      d.name,
+			"old_value": nullString(d.oldValue),
+			"new_value": nullString(d.newValue),
+		})
+	}
+
+	n, _ := db.principalName(j.userID)
+
+	return map[string]interface{}{
+		"id":            j.id,
+		"user":          idName(j.userID, n),
+		"notes":         j.notes,
+		"created_on":    timeFormat(j.createdOn),
+		"private_notes": j.privateNotes,
+		"details":       ds,
+	}
+}
+
+func (db *database) issueChildrenRender(id int64) []interface{} {
+
+	cs := []interface{}{}
+
+	for _, cid := range sortedIDs(db.issues) {
+
+		c := db.issues[cid]
+		if c.parentID != id {
+			continue
+		}
+
+		o := map[string]interface{}{
+			"id":      c.id,
+			"tracker": idName(c.trackerID, db.tracker(c.trackerID).name),
+			"subject": c.subject,
+		}
+
+		if ch := db.issueChildrenRender(c.id); len(ch) > 0 {
+			o["children"] = ch
+		}
+
+		cs = append(cs, o)
+	}
+
+	return cs
+}
+
+// issueDescendants returns IDs of all issue descendants
+func (db *database) issueDescendants(id int64) []int64 {
+
+	var ids []int64
+
+	for _, i := range db.issues {
+		if i.parentID == id {
+			ids = append(ids, i.id)
+			ids = append(ids, db.issueDescendants(i.id)...)
+		}
+	}
+
+	return ids
+}
+
+func (db *database) issueSpentHours(id int64) float64 {
+
+	var h float64
+
+	for _, t := range db.timeEntries {
+		if t.issueID == id {
+			h += t.hours
+		}
+	}
+
+	return h
+}
+
+func (db *database) issueTotalSpentHours(i *issue) float64 {
+
+	h := db.issueSpentHours(i.id)
+
+	for _, id := range db.issueDescendants(i.id) {
+		h += db.issueSpentHours(id)
+	}
+
+	return h
+}
+
+func (db *database) issueTotalEstimatedHours(i *issue) *float64 {
+
+	var (
+		h   float64
+		set bool
+	)
+
+	for _, id := range append([]int64{i.id}, db.issueDescendants(i.id)...) {
+		if e := db.issues[id].estimatedHours; e != nil {
+			h += *e
+			set = true
+		}
+	}
+
+	if set == false {
+		return nil
+	}
+
+	return &h
+}
+
+// issueMatch checks issue matches all filters specified in request
+func (db *database) issueMatch(i *issue, r *http.Request) bool {
+
+	q := r.URL.Query()
+
+	if v := q.Get("project_id"); v != "" {
+		p := db.project(v)
+		if p == nil || db.projectSubtree(p.id)[i.projectID] == false {
+			return false
+		}
+		// Only `!*` (no subprojects) is supported
+		if q.Get("subproject_id") == "!*" && p.id != i.projectID {
+			return false
+		}
+	}
+
+	switch v := q.Get("status_id"); v {
+	case "", "o", "open":
+		if db.status(i.statusID).isClosed == true {
+			return false
+		}
+	case "c", "closed":
+		if db.status(i.statusID).isClosed == false {
+			return false
+		}
+	default:
+		if filterID(v, i.statusID, db.currentUserID) == false {
+			return false
+		}
+	}
+
+	if v := q.Get("issue_id"); filterInteger(v, i.id) == false {
+		return false
+	}
+
+	ids := map[string]int64{
+		"tracker_id":       i.trackerID,
+		"priority_id":      i.priorityID,
+		"author_id":        i.authorID,
+		"assigned_to_id":   i.assignedToID,
+		"category_id":      i.categoryID,
+		"fixed_version_id": i.fixedVersionID,
+		"parent_id":        i.parentID,
+	}
+
+	for k, id := range ids {
+		if filterID(q.Get(k), id, db.currentUserID) == false {
+			return false
+		}
+	}
+
+	if v := q.Get("watcher_id"); v != "" {
+		found := false
+		for _, w := range i.watchers {
+			if filterID(v, w, db.currentUserID) == true {
+				found = true
+				break
+			}
+		}
+		if found == false {
+			return false
+		}
+	}
+
+	if filterString(q.Get("subject"), i.subject) == false {
+		return false
+	}
+
+	if filterString(q.Get("description"), i.description) == false {
+		return false
+	}
+
+	times := map[string]time.Time{
+		"created_on": i.createdOn,
+		"updated_on": i.updatedOn,
+		"closed_on":  i.closedOn,
+		"start_date": dateParse(i.startDate),
+		"due_date":   dateParse(i.dueDate),
+	}
+
+	for k, t := range times {
+		if filterTime(q.Get(k), t) == false {
+			return false
+		}
+	}
+
+	for k, vs := range q {
+		if strings.HasPrefix(k, "cf_") == false || len(vs) == 0 {
+			continue
+		}
+		id, b := parseID(strings.TrimPrefix(k, "cf_"))
+		if b == false {
+			continue
+		}
+		if filterStrings(vs[0], i.customFields[id]) == false {
+			return false
+		}
+	}
+
+	return true
+}
+
+// issuesSort sorts issues in accordance with `sort` request parameter.
+// Issues are sorted by ID descending by default
+func (db *database) issuesSort(is []*issue, r *http.Request) {
+
+	type criterion struct {
+		cmp  func(db *database, a, b *issue) int
+		desc bool
+	}
+
+	var cs []criterion
+
+	for _, e := range strings.Split(r.URL.Query().Get("sort"), ",") {
+
+		f, d, _ := strings.Cut(strings.TrimSpace(e), ":")
+
+		if cmp, b := issueSortFields[f]; b == true {
+			cs = append(cs, criterion{cmp, d == "desc"})
+		}
+	}
+
+	cs = append(cs, criterion{issueSortFields["id"], true})
+
+	sort.SliceStable(is, func(i, j int) bool {
+		for _, c := range cs {
+			r := c.cmp(db, is[i], is[j])
+			if r == 0 {
+				continue
+			}
+			if c.desc == true {
+				return r > 0
+			}
+			return r < 0
+		}
+		return false
+	})
+}
+
+func (s *Server) issuesList(w http.ResponseWriter, r *http.Request, _ []string) {
+
+	var (
+		items  []interface{}
+		issues []*issue
+	)
+
+	for _, i := range s.db.issues {
+		if s.db.issueVisible(i) == true && s.db.issueMatch(i, r) == true {
+			issues = append(issues, i)
+		}
+	}
+
+	s.db.issuesSort(issues, r)
+
+	is := includes(r)
+
+	for _, i := range issues {
+		items = append(items, s.db.issueRender(i, is, false))
+	}
+
+	writeJSON(w, http.StatusOK, paged("issues", items, r))
+}
+
+func (s *Server) issueGet(w http.ResponseWriter, r *http.Request, p []string) {
+
+	i := s.db.issue(p[1])
+	if i == nil || s.db.issueVisible(i) == false {
+		writeStatus(w, http.StatusNotFound)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"issue": s.db.issueRender(i, includes(r), true),
+	})
+}
+
+func (s *Server) issueCreate(w http.ResponseWriter, r *http.Request, _ []string) {
+
+	var in struct {
+		Issue issueIn `json:"issue"`
+	}
+
+	if err := readJSON(r, &in); err != nil {
+		writeStatus(w, http.StatusBadRequest)
+		return
+	}
+
+	now := s.now()
+
+	i := &issue{
+		statusID:     StatusNewID,
+		authorID:     s.db.currentUserID,
+		customFields: make(map[int64][]string),
+		createdOn:    now,
+		updatedOn:    now,
+	}
+
+	for _, p := range s.db.priorities {
+		if p.isDefault == true {
+			i.priorityID = p.id
+		}
+	}
+
+	errs := s.db.issueApply(i, in.Issue, nil)
+
+	if in.Issue.WatcherUserIDs != nil {
+		for _, id := range *in.Issue.WatcherUserIDs {
+			if _, b := s.db.users[id]; b == false {
+				errs = append(errs, "Watchers is invalid")
+				break
+			}
+			i.watchers = append(i.watchers, id)
+		}
+	}
+
+	if len(errs) > 0 {
+		writeErrors(w, errs...)
+		return
+	}
+
+	i.id = s.db.nextID()
+	s.db.issues[i.id] = i
+
+	if in.Issue.Uploads != nil {
+		i.attachments = append(i.attachments, s.db.uploadsAttach(*in.Issue.Uploads)...)
+	}
+
+	if s.db.status(i.statusID).isClosed == true {
+		i.closedOn = now
+	}
+
+	writeJSON(w, http.StatusCreated, map[string]interface{}{
+		"issue": s.db.issueRender(i, nil, false),
+	})
+}
+
+func (s *Server) issueUpdate(w http.ResponseWriter, r *http.Request, p []string) {
+
+	var in struct {
+		Issue issueIn `json:"issue"`
+	}
+
+	i := s.db.issue(p[1])
+	if i == nil || s.db.issueVisible(i) == false {
+		writeStatus(w, http.StatusNotFound)
+		return
+	}
+
+	if err := readJSON(r, &in); err != nil {
+		writeStatus(w, http.StatusBadRequest)
+		return
+	}
+
+	now := s.now()
+
+	j := journal{
+		userID:    s.db.currentUserID,
+		createdOn: now,
+	}
+
+	upd := *i
+	upd.customFields = copyCustomFields(i.customFields)
+
+	if errs := s.db.issueApply(&upd, in.Issue, &j); len(errs) > 0 {
+		writeErrors(w, errs...)
+		return
+	}
+
+	if in.Issue.Uploads != nil {
+		for _, id := range s.db.uploadsAttach(*in.Issue.Uploads) {
+			upd.attachments = append(upd.attachments, id)
+			j.details = append(j.details, journalDetail{
+				property: "attachment",
+				name:     strconv.FormatInt(id, 10),
+				newValue: s.db.attachments[id].fileName,
+			})
+		}
+	}
+
+	if in.Issue.Notes != nil {
+		j.notes = *in.Issue.Notes
+	}
+
+	if in.Issue.PrivateNotes != nil {
+		j.privateNotes = *in.Issue.PrivateNotes
+	}
+
+	if len(j.details) > 0 || j.notes != "" {
+		j.id = s.db.nextID()
+		upd.journals = append(upd.journals, j)
+		upd.updatedOn = now
+	}
+
+	if s.db.status(upd.statusID).isClosed == true && s.db.status(i.statusID).isClosed == false {
+		upd.closedOn = now
+	}
+
+	*i = upd
+
+	writeStatus(w, http.StatusNoContent)
+}
+
+func (s *Server) issueDelete(w http.ResponseWriter, r *http.Request, p []string) {
+
+	i := s.db.issue(p[1])
+	if i == nil || s.db.issueVisible(i) == false {
+		writeStatus(w, http.StatusNotFound)
+		return
+	}
+
+	for _, id := range append([]int64{i.id}, s.db.issueDescendants(i.id)...) {
+
+		for tid, t := range s.db.timeEntries {
+			if t.issueID == id {
+				delete(s.db.timeEntries, tid)
+			}
+		}
+
+		delete(s.db.issues, id)
+	}
+
+	writeStatus(w, http.StatusNoContent)
+}
+
+func (s *Server) issueWatcherAdd(w http.ResponseWriter, r *http.Request, p []string) {
+
+	var in struct {
+		UserID int64 `json:"user_id"`
+	}
+
+	i := s.db.issue(p[1])
+	if i == nil || s.db.issueVisible(i) == false {
+		writeStatus(w, http.StatusNotFound)
+		return
+	}
+
+	if err := readJSON(r, &in); err != nil {
+		writeStatus(w, http.StatusBadRequest)
+		return
+	}
+
+	if _, b := s.db.users[in.UserID]; b == false {
+		writeStatus(w, http.StatusNotFound)
+		return
+	}
+
+	for _, id := range i.watchers {
+		if id == in.UserID {
+			writeStatus(w, http.StatusNoContent)
+			return
+		}
+	}
+
+	i.watchers = append(i.watchers, in.UserID)
+
+	writeStatus(w, http.StatusNoContent)
+}
+
+func (s *Server) issueWatcherDelete(w http.ResponseWriter, r *http.Request, p []string) {
+
+	i := s.db.issue(p[1])
+	if i == nil || s.db.issueVisible(i) == false {
+		writeStatus(w, http.StatusNotFound)
+		return
+	}
+
+	uid, b := parseID(p[3])
+	if b == false {
+		writeStatus(w, http.StatusNotFound)
+		return
+	}
+
+	ws := []int64{}
+	for _, id := range i.watchers {
+		if id != uid {
+			ws = append(ws, id)
+		}
+	}
+	i.watchers = ws
+
+	writeStatus(w, http.StatusNoContent)
+}
+
+// issueApply applies incoming data to issue and returns validation errors.
+// Changes of existing issue are recorded into specified journal
+func (db *database) issueApply(i *issue, in issueIn, j *journal) []string {
+
+	var errs []string
+
+	attr := func(name, old, new string) {
+		if j != nil && old != new {
+			j.details = append(j.details, journalDetail{
+				property: "attr",
+				name:     name,
+				oldValue: old,
+				newValue: new,
+			})
+		}
+	}
+
+	id := func(v int64) string {
+		if v == 0 {
+			return ""
+		}
+		return strconv.FormatInt(v, 10)
+	}
+
+	if in.ProjectID != nil {
+		if p, b := db.projects[*in.ProjectID]; b == false || p.status == projectStatusArchived {
+			errs = append(errs, "Project is invalid")
+		} else {
+			attr("project_id", id(i.projectID), id(p.id))
+			i.projectID = p.id
+		}
+	}
+
+	p := db.projects[i.projectID]
+	if p == nil {
+		return append(errs, "Project cannot be blank")
+	}
+
+	if in.TrackerID != nil {
+		attr("tracker_id", id(i.trackerID), id(*in.TrackerID))
+		i.trackerID = *in.TrackerID
+	} else if i.trackerID == 0 && len(p.trackers) > 0 {
+		i.trackerID = p.trackers[0]
+	}
+
+	if func() bool {
+		for _, t := range p.trackers {
+			if t == i.trackerID {
+				return true
+			}
+		}
+		return false
+	}() == false {
+		errs = append(errs, "Tracker is not included in the list")
+	}
+
+	if in.StatusID != nil {
+		if db.status(*in.StatusID) == nil {
+			errs = append(errs, "Status is not included in the list")
+		} else {
+			attr("status_id", id(i.statusID), id(*in.StatusID))
+			i.statusID = *in.StatusID
+		}
+	}
+
+	if in.PriorityID != nil {
+		if db.priority(*in.PriorityID) == nil {
+			errs = append(errs, "Priority cannot be blank")
+		} else {
+			attr("priority_id", id(i.priorityID), id(*in.PriorityID))
+			i.priorityID = *in.PriorityID
+		}
+	}
+
+	if in.Subject != nil {
+		attr("subject", i.subject, *in.Subject)
+		i.subject = *in.Subject
+	}
+	if i.subject == "" {
+		errs = append(errs, "Subject cannot be blank")
+	}
+
+	if in.Description != nil {
+		attr("description", i.description, *in.Description)
+		i.description = *in.Description
+	}
+
+	if in.StartDate != nil {
+		if *in.StartDate != "" && dateParse(*in.StartDate).IsZero() == true {
+			errs = append(errs, "Start date is not a valid date")
+		} else {
+			attr("start_date", i.startDate, *in.StartDate)
+			i.startDate = *in.StartDate
+		}
+	}
+
+	if in.DueDate != nil {
+		if *in.DueDate != "" && dateParse(*in.DueDate).IsZero() == true {
+			errs = append(errs, "Due date is not a valid date")
+		} else {
+			attr("due_date", i.dueDate, *in.DueDate)
+			i.dueDate = *in.DueDate
+		}
+	}
+
+	if i.startDate != "" && i.dueDate != "" && i.dueDate < i.startDate {
+		errs = append(errs, "Due date must be greater than start date")
+	}
+
+	if in.DoneRatio != nil {
+		if *in.DoneRatio < 0 || *in.DoneRatio > 100 {
+			errs = append(errs, "% Done is not included in the list")
+		} else {
+			attr("done_ratio", strconv.FormatInt(i.doneRatio, 10), strconv.FormatInt(*in.DoneRatio, 10))
+			i.doneRatio = *in.DoneRatio
+		}
+	}
+
+	if in.AssignedToID.set == true {
+		a := in.AssignedToID.id
+		if in.AssignedToID.clear == false && db.memberRoles(i.projectID, a) == nil {
+			errs = append(errs, "Assignee is invalid")
+		} else {
+			attr("assigned_to_id", id(i.assignedToID), id(a))
+			i.assignedToID = a
+		}
+	}
+
+	if in.CategoryID != nil {
+		if c, b := db.categories[*in.CategoryID]; *in.CategoryID != 0 && (b == false || c.projectID != i.projectID) {
+			errs = append(errs, "Category is not included in the list")
+		} else {
+			attr("category_id", id(i.categoryID), id(*in.CategoryID))
+			i.categoryID = *in.CategoryID
+		}
+	}
+
+	if in.FixedVersionID != nil {
+		if v, b := db.versions[*in.FixedVersionID]; *in.FixedVersionID != 0 && (b == false || db.versionShared(v, i.projectID) == false) {
+			errs = append(errs, "Target version is not included in the list")
+		} else if b == true && v.status != "open" && *in.FixedVersionID != i.fixedVersionID {
+			errs = append(errs, "Target version is not open")
+		} else {
+			attr("fixed_version_id", id(i.fixedVersionID), id(*in.FixedVersionID))
+			i.fixedVersionID = *in.FixedVersionID
+		}
+	}
+
+	if in.ParentIssueID != nil {
+		pid := *in.ParentIssueID
+		if _, b := db.issues[pid]; pid != 0 && (b == false || pid == i.id || func() bool {
+			// New issue has no descendants
+			if i.id == 0 {
+				return false
+			}
+			for _, d := range db.issueDescendants(i.id) {
+				if d == pid {
+					return true
+				}
+			}
+			return false
+		}() == true) {
+			errs = append(errs, "Parent task is invalid")
+		} else {
+			attr("parent_id", id(i.parentID), id(pid))
+			i.parentID = pid
+		}
+	}
+
+	if in.IsPrivate != nil {
+		attr("is_private", boolString(i.isPrivate), boolString(*in.IsPrivate))
+		i.isPrivate = *in.IsPrivate
+	}
+
+	if in.EstimatedHours != nil {
+		if *in.EstimatedHours < 0 {
+			errs = append(errs, "Estimated time is invalid")
+		} else {
+			attr("estimated_hours", floatString(i.estimatedHours), floatString(in.EstimatedHours))
+			h := *in.EstimatedHours
+			i.estimatedHours = &h
+		}
+	}
+
+	if in.CustomFields != nil {
+
+		old := copyCustomFields(i.customFields)
+
+		errs = append(errs, db.customFieldsApply(i.customFields, "issue", i.trackerID, *in.CustomFields)...)
+
+		if j != nil {
+			for _, c := range db.customFields {
+				o, n := strings.Join(old[c.id], ","), strings.Join(i.customFields[c.id], ",")
+				if o != n {
+					j.details = append(j.details, journalDetail{
+						property: "cf",
+						name:     strconv.FormatInt(c.id, 10),
+						oldValue: o,
+						newValue: n,
+					})
+				}
+			}
+		}
+	}
+
+	errs = append(errs, db.customFieldsRequired(i.customFields, "issue", i.trackerID)...)
+
+	return errs
+}
+
+func dateParse(s string) time.Time {
+	t, _ := time.Parse(dateFormat, s)
+	return t
+}
+
+func nullString(s string) interface{} {
+	if s == "" {
+		return nil
+	}
+	return s
+}
+
+func boolString(b bool) string {
+	if b == true {
+		return "1"
+	}
+	return "0"
+}
+
+func floatString(f *float64) string {
+	if f == nil {
+		return ""
+	}
+	return strconv.FormatFloat(*f, 'f', -1, 64)
+}
@@ -0,0 +1,400 @@
+package redminetest
+
+import (
+	"net/http"
+)
+
+type membership struct {
+	id          int64
+	projectID   int64
+	principalID int64
+	roles       []memberRole
+}
+
+type memberRole struct {
+	id            int64
+	inheritedFrom int64 // ID of the group or parent project membership role is inherited from, 0 for own roles
+}
+
+type membershipIn struct {
+	UserID  *int64   `json:"user_id"`
+	RoleIDs *[]int64 `json:"role_ids"`
+}
+
+func (db *database) membership(id string) *membership {
+
+	n, b := parseID(id)
+	if b == false {
+		return nil
+	}
+
+	return db.memberships[n]
+}
+
+func (db *database) membershipFind(projectID, principalID int64) *membership {
+
+	for _, m := range db.memberships {
+		if m.projectID == projectID && m.principalID == principalID {
+			return m
+		}
+	}
+
+	return nil
+}
+
+// memberRoles returns IDs of principal roles in project or nil if principal is not a member
+func (db *database) memberRoles(projectID, principalID int64) []int64 {
+
+	m := db.membershipFind(projectID, principalID)
+	if m == nil {
+		return nil
+	}
+
+	var (
+		ids  []int64
+		seen = make(map[int64]bool)
+	)
+
+	for _, r := range m.roles {
+		if seen[r.id] == false {
+			ids = append(ids, r.id)
+			seen[r.id] = true
+		}
+	}
+
+	return ids
+}
+
+// membershipsInherit rebuilds roles users inherit from their groups memberships
+// and roles subprojects with `inherit_members` inherit from parent projects
+// memberships the same way Redmine does when groups, projects or their
+// memberships are changed
+func (db *database) membershipsInherit() {
+
+	for _, m := range db.memberships {
+		rs := []memberRole{}
+		for _, r := range m.roles {
+			if r.inheritedFrom == 0 {
+				rs = append(rs, r)
+			}
+		}
+		m.roles = rs
+	}
+
+	for _, id := range sortedIDs(db.memberships) {
+
+		gm := db.memberships[id]
+
+		g, b := db.groups[gm.principalID]
+		if b == false {
+			continue
+		}
+
+		for _, uid := range g.users {
+
+			um := db.membershipFind(gm.projectID, uid)
+			if um == nil {
+				um = &membership{
+					id:          db.nextID(),
+					projectID:   gm.projectID,
+					principalID: uid,
+				}
+				db.memberships[um.id] = um
+			}
+
+			for _, r := range gm.roles {
+				if r.inheritedFrom == 0 {
+					um.roles = append(um.roles, memberRole{r.id, gm.id})
+				}
+			}
+		}
+	}
+
+	// Parent projects are processed before their subprojects, so roles are inherited
+	// through the whole hierarchy
+	var inherit func(parentID int64)
+
+	inherit = func(parentID int64) {
+
+		for _, id := range sortedIDs(db.projects) {
+
+			p := db.projects[id]
+			if p.parentID != parentID {
+				continue
+			}
+
+			if parentID != 0 && p.inheritMembers == true {
+				for _, mid := range sortedIDs(db.memberships) {
+
+					pm := db.memberships[mid]
+					if pm.projectID != parentID {
+						continue
+					}
+
+					m := db.membershipFind(p.id, pm.principalID)
+					if m == nil {
+						m = &membership{
+							id:          db.nextID(),
+							projectID:   p.id,
+							principalID: pm.principalID,
+						}
+						db.memberships[m.id] = m
+					}
+
+					for _, r := range pm.roles {
+						m.roles = append(m.roles, memberRole{r.id, pm.id})
+					}
+				}
+			}
+
+			inherit(id)
+		}
+	}
+
+	inherit(0)
+
+	for id, m := range db.memberships {
+		if len(m.roles) == 0 {
+			delete(db.memberships, id)
+		}
+	}
+}
+
+func (db *database) membershipRender(m *membership) map[string]interface{} {
+
+	o := map[string]interface{}{
+		"id":      m.id,
+		"project": idName(m.projectID, db.projects[m.projectID].name),
+	}
+
+	n, _ := db.principalName(m.principalID)
+
+	if _, b := db.groups[m.principalID]; b == true {
+		o["group"] = idName(m.principalID, n)
+	} else {
+		o["user"] = idName(m.principalID, n)
+	}
+
+	rs := []interface{}{}
+	for _, r := range m.roles {
+		e := idName(r.id, db.roles[r.id])
+		if r.inheritedFrom != 0 {
+			e["inherited"] = true
+		}
+		rs = append(rs, e)
+	}
+	o["roles"] = rs
+
+	return o
+}
+
+// principalMembershipsRender renders memberships of user or group
+// as they are included into users and groups responses
+func (db *database) principalMembershipsRender(principalID int64) []interface{} {
+
+	ms := []interface{}{}
+
+	for _, id := range sortedIDs(db.memberships) {
+
+		m := db.memberships[id]
+		if m.principalID != principalID {
+			continue
+		}
+
+		rs := []interface{}{}
+		for _, r := range db.memberRoles(m.projectID, principalID) {
+			rs = append(rs, idName(r, db.roles[r]))
+		}
+
+		ms = append(ms, map[string]interface{}{
+			"id":      m.id,
+			"project": idName(m.projectID, db.projects[m.projectID].name),
+			"roles":   rs,
+		})
+	}
+
+	return ms
+}
+
+func (s *Server) membershipsList(w http.ResponseWriter, r *http.Request, p []string) {
+
+	var items []interface{}
+
+	pr := s.db.project(p[1])
+	if pr == nil {
+		writeStatus(w, http.StatusNotFound)
+		return
+	}
+
+	for _, id := range sortedIDs(s.db.memberships) {
+		if m := s.db.memberships[id]; m.projectID == pr.id {
+			items = append(items, s.db.membershipRender(m))
+		}
+	}
+
+	writeJSON(w, http.StatusOK, paged("memberships", items, r))
+}
+
+func (s *Server) membershipGet(w http.ResponseWriter, r *http.Request, p []string) {
+
+	m := s.db.membership(p[1])
+	if m == nil {
+		writeStatus(w, http.StatusNotFound)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"membership": s.db.membershipRender(m),
+	})
+}
+
+func (s *Server) membershipCreate(w http.ResponseWriter, r *http.Request, p []string) {
+
+	var in struct {
+		Membership membershipIn `json:"membership"`
+	}
+
+	pr := s.db.project(p[1])
+	if pr == nil {
+		writeStatus(w, http.StatusNotFound)
+		return
+	}
+
+	if err := readJSON(r, &in); err != nil {
+		writeStatus(w, http.StatusBadRequest)
+		return
+	}
+
+	if in.Membership.UserID == nil {
+		writeErrors(w, "Principal cannot be blank")
+		return
+	}
+
+	if _, b := s.db.principalName(*in.Membership.UserID); b == false {
+		writeErrors(w, "Principal cannot be blank")
+		return
+	}
+
+	m := s.db.membershipFind(pr.id, *in.Membership.UserID)
+	if m == nil {
+		m = &membership{
+			projectID:   pr.id,
+			principalID: *in.Membership.UserID,
+		}
+	} else {
+		for _, r := range m.roles {
+			if r.inheritedFrom == 0 {
+				writeErrors(w, "Principal has already been taken")
+				return
+			}
+		}
+	}
+
+	upd := *m
+
+	if errs := s.db.membershipApply(&upd, in.Membership); len(errs) > 0 {
+		writeErrors(w, errs...)
+		return
+	}
+
+	if upd.id == 0 {
+		upd.id = s.db.nextID()
+		s.db.memberships[upd.id] = m
+	}
+	*m = upd
+
+	s.db.membershipsInherit()
+
+	writeJSON(w, http.StatusCreated, map[string]interface{}{
+		"membership": s.db.membershipRender(m),
+	})
+}
+
+func (s *Server) membershipUpdate(w http.ResponseWriter, r *http.Request, p []string) {
+
+	var in struct {
+		Membership membershipIn `json:"membership"`
+	}
+
+	m := s.db.membership(p[1])
+	if m == nil {
+		writeStatus(w, http.StatusNotFound)
+		return
+	}
+
+	if err := readJSON(r, &in); err != nil {
+		writeStatus(w, http.StatusBadRequest)
+		return
+	}
+
+	// Membership owner can't be changed
+	in.Membership.UserID = nil
+
+	upd := *m
+
+	if errs := s.db.membershipApply(&upd, in.Membership); len(errs) > 0 {
+		writeErrors(w, errs...)
+		return
+	}
+
+	*m = upd
+
+	s.db.membershipsInherit()
+
+	writeStatus(w, http.StatusNoContent)
+}
+
+func (s *Server) membershipDelete(w http.ResponseWriter, r *http.Request, p []string) {
+
+	m := s.db.membership(p[1])
+	if m == nil {
+		writeStatus(w, http.StatusNotFound)
+		return
+	}
+
+	// Memberships with inherited roles can't be deleted
+	for _, r := range m.roles {
+		if r.inheritedFrom != 0 {
+			writeStatus(w, http.StatusUnprocessableEntity)
+			return
+		}
+	}
+
+	delete(s.db.memberships, m.id)
+
+	s.db.membershipsInherit()
+
+	writeStatus(w, http.StatusNoContent)
+}
+
+// membershipApply replaces own membership roles and returns validation errors
+func (db *database) membershipApply(m *membership, in membershipIn) []string {
+
+	if in.RoleIDs == nil {
+		return []string{"Role cannot be empty"}
+	}
+
+	rs := []memberRole{}
+
+	for _, r := range m.roles {
+		if r.inheritedFrom != 0 {
+			rs = append(rs, r)
+		}
+	}
+
+	for _, id := range *in.RoleIDs {
+		if _, b := db.roles[id]; b == false {
+			return []string{"Role is invalid"}
+		}
+		rs = append(rs, memberRole{id, 0})
+	}
+
+	// Own roles may be empty only if the principal has inherited ones
+	if len(rs) == 0 {
+		return []string{"Role cannot be empty"}
+	}
+
+	m.roles = rs
+
+	return nil
+}
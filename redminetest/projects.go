@@ -0,0 +1,516 @@
+package redminetest
+
+import (
+	"net/http"
+	"regexp"
+	"strconv"
+	"time"
+)
+
+const (
+	projectStatusActive   = 1
+	projectStatusClosed   = 5
+	projectStatusArchived = 9
+)
+
+var (
+	projectIdentifierRegexp = regexp.MustCompile(`^[a-z][a-z0-9_\-]*$`)
+	projectModulesDefault   = []string{"issue_tracking", "time_tracking", "news", "documents", "files", "wiki", "repository", "boards", "calendar", "gantt"}
+)
+
+type project struct {
+	id                int64
+	name              string
+	identifier        string
+	description       string
+	homepage          string
+	parentID          int64
+	status            int64
+	isPublic          bool
+	inheritMembers    bool
+	defaultVersionID  int64
+	defaultAssigneeID int64
+	trackers          []int64
+	modules           []string
+	issueCustomFields []int64
+	customFields      map[int64][]string
+	wiki              map[string]*wikiPage
+	createdOn         time.Time
+	updatedOn         time.Time
+}
+
+type projectIn struct {
+	Name                *string          `json:"name"`
+	Identifier          *string          `json:"identifier"`
+	Description         *string          `json:"description"`
+	Homepage            *string          `json:"homepage"`
+	IsPublic            *bool            `json:"is_public"`
+	ParentID            clearableID      `json:"parent_id"`
+	InheritMembers      *bool            `json:"inherit_members"`
+	DefaultVersionID    *int64           `json:"default_version_id"`
+	DefaultAssignedToID *int64           `json:"default_assigned_to_id"`
+	TrackerIDs          *[]int64         `json:"tracker_ids"`
+	EnabledModuleNames  *[]string        `json:"enabled_module_names"`
+	IssueCustomFieldIDs *[]int64         `json:"issue_custom_field_ids"`
+	CustomFields        *[]customFieldIn `json:"custom_fields"`
+}
+
+func (db *database) project(id string) *project {
+
+	if n, b := parseID(id); b == true {
+		return db.projects[n]
+	}
+
+	for _, p := range db.projects {
+		if p.identifier == id {
+			return p
+		}
+	}
+
+	return nil
+}
+
+// projectSubtree returns IDs of project and all its descendants
+func (db *database) projectSubtree(id int64) map[int64]bool {
+
+	ids := map[int64]bool{id: true}
+
+	for changed := true; changed == true; {
+		changed = false
+		for _, p := range db.projects {
+			if ids[p.parentID] == true && ids[p.id] == false {
+				ids[p.id] = true
+				changed = true
+			}
+		}
+	}
+
+	return ids
+}
+
+func (db *database) projectRender(p *project, is map[string]bool) map[string]interface{} {
+
+	o := map[string]interface{}{
+		"id":              p.id,
+		"name":            p.name,
+		"identifier":      p.identifier,
+		"description":     p.description,
+		"homepage":        p.homepage,
+		"status":          p.status,
+		"is_public":       p.isPublic,
+		"inherit_members": p.inheritMembers,
+		"custom_fields":   db.customFieldsRender("project", p.customFields, 0),
+		"created_on":      timeFormat(p.createdOn),
+		"updated_on":      timeFormat(p.updatedOn),
+	}
+
+	if parent, b := db.projects[p.parentID]; b == true {
+		o["parent"] = idName(parent.id, parent.name)
+	}
+
+	if v, b := db.versions[p.defaultVersionID]; b == true {
+		o["default_version"] = idName(v.id, v.name)
+	}
+
+	if n, b := db.principalName(p.defaultAssigneeID); b == true {
+		o["default_assignee"] = idName(p.defaultAssigneeID, n)
+	}
+
+	if is["trackers"] == true {
+		ts := []interface{}{}
+		for _, id := range p.trackers {
+			if t := db.tracker(id); t != nil {
+				ts = append(ts, idName(t.id, t.name))
+			}
+		}
+		o["trackers"] = ts
+	}
+
+	if is["issue_categories"] == true {
+		cs := []interface{}{}
+		for _, c := range db.projectIssueCategories(p.id) {
+			cs = append(cs, idName(c.id, c.name))
+		}
+		o["issue_categories"] = cs
+	}
+
+	if is["enabled_modules"] == true {
+		ms := []interface{}{}
+		for i, m := range p.modules {
+			ms = append(ms, idName(p.id*100+int64(i), m))
+		}
+		o["enabled_modules"] = ms
+	}
+
+	if is["time_entry_activities"] == true {
+		as := []interface{}{}
+		for _, a := range db.activities {
+			as = append(as, idName(a.id, a.name))
+		}
+		o["time_entry_activities"] = as
+	}
+
+	if is["issue_custom_fields"] == true {
+		cs := []interface{}{}
+		for _, id := range p.issueCustomFields {
+			if c := db.customField(id); c != nil {
+				cs = append(cs, idName(c.id, c.name))
+			}
+		}
+		o["issue_custom_fields"] = cs
+	}
+
+	return o
+}
+
+func (s *Server) projectsList(w http.ResponseWriter, r *http.Request, _ []string) {
+
+	var (
+		items  []interface{}
+		status *int64
+	)
+
+	if v := r.URL.Query().Get("status"); v != "" {
+		n, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			writeJSON(w, http.StatusOK, paged("projects", nil, r))
+			return
+		}
+		status = &n
+	}
+
+	is := includes(r)
+
+	for _, id := range sortedIDs(s.db.projects) {
+
+		p := s.db.projects[id]
+
+		if status != nil && p.status != *status {
+			continue
+		}
+
+		if status == nil && p.status == projectStatusArchived {
+			continue
+		}
+
+		if s.db.projectMatch(p, r) == false {
+			continue
+		}
+
+		items = append(items, s.db.projectRender(p, is))
+	}
+
+	writeJSON(w, http.StatusOK, paged("projects", items, r))
+}
+
+// projectMatch checks project matches query filters
+func (db *database) projectMatch(p *project, r *http.Request) bool {
+
+	q := r.URL.Query()
+
+	if filterID(q.Get("id"), p.id, db.currentUserID) == false {
+		return false
+	}
+
+	if filterID(q.Get("parent_id"), p.parentID, db.currentUserID) == false {
+		return false
+	}
+
+	if v := q.Get("is_public"); v != "" && filterString(v, boolString(p.isPublic)) == false {
+		return false
+	}
+
+	for k, v := range map[string]string{
+		"name":        p.name,
+		"identifier":  p.identifier,
+		"description": p.description,
+	} {
+		if filterString(q.Get(k), v) == false {
+			return false
+		}
+	}
+
+	if filterTime(q.Get("created_on"), p.createdOn) == false || filterTime(q.Get("updated_on"), p.updatedOn) == false {
+		return false
+	}
+
+	return true
+}
+
+func (s *Server) projectGet(w http.ResponseWriter, r *http.Request, p []string) {
+
+	pr := s.db.project(p[1])
+	if pr == nil {
+		writeStatus(w, http.StatusNotFound)
+		return
+	}
+
+	if pr.status == projectStatusArchived {
+		writeStatus(w, http.StatusForbidden)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"project": s.db.projectRender(pr, includes(r)),
+	})
+}
+
+func (s *Server) projectCreate(w http.ResponseWriter, r *http.Request, _ []string) {
+
+	var in struct {
+		Project projectIn `json:"project"`
+	}
+
+	if err := readJSON(r, &in); err != nil {
+		writeStatus(w, http.StatusBadRequest)
+		return
+	}
+
+	now := s.now()
+
+	pr := &project{
+		status:       projectStatusActive,
+		isPublic:     true,
+		trackers:     []int64{},
+		modules:      append([]string{}, projectModulesDefault...),
+		customFields: make(map[int64][]string),
+		wiki:         make(map[string]*wikiPage),
+		createdOn:    now,
+		updatedOn:    now,
+	}
+
+	for _, t := range s.db.trackers {
+		pr.trackers = append(pr.trackers, t.id)
+	}
+
+	if errs := s.db.projectApply(pr, in.Project, true); len(errs) > 0 {
+		writeErrors(w, errs...)
+		return
+	}
+
+	pr.id = s.db.nextID()
+	s.db.projects[pr.id] = pr
+
+	s.db.membershipsInherit()
+
+	writeJSON(w, http.StatusCreated, map[string]interface{}{
+		"project": s.db.projectRender(pr, nil),
+	})
+}
+
+func (s *Server) projectUpdate(w http.ResponseWriter, r *http.Request, p []string) {
+
+	var in struct {
+		Project projectIn `json:"project"`
+	}
+
+	pr := s.db.project(p[1])
+	if pr == nil {
+		writeStatus(w, http.StatusNotFound)
+		return
+	}
+
+	if err := readJSON(r, &in); err != nil {
+		writeStatus(w, http.StatusBadRequest)
+		return
+	}
+
+	// Identifier can't be changed
+	in.Project.Identifier = nil
+
+	upd := *pr
+	upd.customFields = copyCustomFields(pr.customFields)
+
+	if errs := s.db.projectApply(&upd, in.Project, false); len(errs) > 0 {
+		writeErrors(w, errs...)
+		return
+	}
+
+	upd.updatedOn = s.now()
+	*pr = upd
+
+	s.db.membershipsInherit()
+
+	writeStatus(w, http.StatusNoContent)
+}
+
+func (s *Server) projectDelete(w http.ResponseWriter, r *http.Request, p []string) {
+
+	pr := s.db.project(p[1])
+	if pr == nil {
+		writeStatus(w, http.StatusNotFound)
+		return
+	}
+
+	for id := range s.db.projectSubtree(pr.id) {
+		s.db.projectPurge(id)
+	}
+
+	writeStatus(w, http.StatusNoContent)
+}
+
+func (s *Server) projectArchive(w http.ResponseWriter, r *http.Request, p []string) {
+	s.projectStatusSet(w, p[1], projectStatusArchived)
+}
+
+func (s *Server) projectUnarchive(w http.ResponseWriter, r *http.Request, p []string) {
+	s.projectStatusSet(w, p[1], projectStatusActive)
+}
+
+func (s *Server) projectClose(w http.ResponseWriter, r *http.Request, p []string) {
+	s.projectStatusSet(w, p[1], projectStatusClosed)
+}
+
+func (s *Server) projectReopen(w http.ResponseWriter, r *http.Request, p []string) {
+	s.projectStatusSet(w, p[1], projectStatusActive)
+}
+
+func (s *Server) projectStatusSet(w http.ResponseWriter, id string, status int64) {
+
+	pr := s.db.project(id)
+	if pr == nil {
+		writeStatus(w, http.StatusNotFound)
+		return
+	}
+
+	for id := range s.db.projectSubtree(pr.id) {
+		s.db.projects[id].status = status
+		s.db.projects[id].updatedOn = s.now()
+	}
+
+	writeStatus(w, http.StatusNoContent)
+}
+
+// projectApply applies incoming data to project and returns validation errors
+func (db *database) projectApply(pr *project, in projectIn, create bool) []string {
+
+	var errs []string
+
+	if in.Name != nil {
+		pr.name = *in.Name
+	}
+	if pr.name == "" {
+		errs = append(errs, "Name cannot be blank")
+	}
+
+	if create == true {
+
+		if in.Identifier != nil {
+			pr.identifier = *in.Identifier
+		}
+
+		switch {
+		case pr.identifier == "":
+			errs = append(errs, "Identifier cannot be blank")
+		case projectIdentifierRegexp.MatchString(pr.identifier) == false:
+			errs = append(errs, "Identifier is invalid")
+		case db.project(pr.identifier) != nil:
+			errs = append(errs, "Identifier has already been taken")
+		}
+	}
+
+	if in.Description != nil {
+		pr.description = *in.Description
+	}
+
+	if in.Homepage != nil {
+		pr.homepage = *in.Homepage
+	}
+
+	if in.IsPublic != nil {
+		pr.isPublic = *in.IsPublic
+	}
+
+	if in.InheritMembers != nil {
+		pr.inheritMembers = *in.InheritMembers
+	}
+
+	if in.ParentID.set == true {
+		if _, b := db.projects[in.ParentID.id]; b == false && in.ParentID.clear == false {
+			errs = append(errs, "Subproject of is invalid")
+		} else if pr.id != 0 && db.projectSubtree(pr.id)[in.ParentID.id] == true {
+			errs = append(errs, "Subproject of is invalid")
+		} else {
+			pr.parentID = in.ParentID.id
+		}
+	}
+
+	if in.DefaultVersionID != nil {
+		if v, b := db.versions[*in.DefaultVersionID]; *in.DefaultVersionID != 0 && (b == false || db.versionShared(v, pr.id) == false) {
+			errs = append(errs, "Default version is not included in the list")
+		} else {
+			pr.defaultVersionID = *in.DefaultVersionID
+		}
+	}
+
+	if in.DefaultAssignedToID != nil {
+		if *in.DefaultAssignedToID != 0 && db.memberRoles(pr.id, *in.DefaultAssignedToID) == nil {
+			errs = append(errs, "Default assignee is not included in the list")
+		} else {
+			pr.defaultAssigneeID = *in.DefaultAssignedToID
+		}
+	}
+
+	if in.TrackerIDs != nil {
+		pr.trackers = []int64{}
+		for _, id := range *in.TrackerIDs {
+			if db.tracker(id) == nil {
+				errs = append(errs, "Trackers is invalid")
+				break
+			}
+			pr.trackers = append(pr.trackers, id)
+		}
+	}
+
+	if in.EnabledModuleNames != nil {
+		pr.modules = append([]string{}, *in.EnabledModuleNames...)
+	}
+
+	if in.IssueCustomFieldIDs != nil {
+		pr.issueCustomFields = append([]int64{}, *in.IssueCustomFieldIDs...)
+	}
+
+	if in.CustomFields != nil {
+		errs = append(errs, db.customFieldsApply(pr.customFields, "project", 0, *in.CustomFields)...)
+	}
+
+	errs = append(errs, db.customFieldsRequired(pr.customFields, "project", 0)...)
+
+	return errs
+}
+
+// projectPurge deletes project with all its related objects
+func (db *database) projectPurge(id int64) {
+
+	for iid, i := range db.issues {
+		if i.projectID == id {
+			delete(db.issues, iid)
+		}
+	}
+
+	for mid, m := range db.memberships {
+		if m.projectID == id {
+			delete(db.memberships, mid)
+		}
+	}
+
+	for tid, t := range db.timeEntries {
+		if t.projectID == id {
+			delete(db.timeEntries, tid)
+		}
+	}
+
+	for vid, v := range db.versions {
+		if v.projectID == id {
+			delete(db.versions, vid)
+		}
+	}
+
+	for cid, c := range db.categories {
+		if c.projectID == id {
+			delete(db.categories, cid)
+		}
+	}
+
+	delete(db.projects, id)
+}
@@ -0,0 +1,1041 @@
+package redminetest
+
+import (
+	"io"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	redmine "github.com/nixys/nxs-go-redmine/v5"
+)
+
+func TestProjectsIssues(t *testing.T) {
+
+	s := Init(Settings{})
+	defer s.Close()
+
+	r := s.Context()
+
+	p, _, err := r.ProjectCreate(
+		redmine.ProjectCreate{
+			Project: redmine.ProjectCreateObject{
+				Name:       "Test project",
+				Identifier: "test-project",
+			},
+		},
+	)
+	if err != nil {
+		t.Fatal("Project create error:", err)
+	}
+
+	// Duplicate identifier
+	if _, _, err := r.ProjectCreate(
+		redmine.ProjectCreate{
+			Project: redmine.ProjectCreateObject{
+				Name:       "Test project",
+				Identifier: "test-project",
+			},
+		},
+	); err == nil || strings.Contains(err.Error(), "Identifier has already been taken") == false {
+		t.Fatal("Project create error: expected validation error, got:", err)
+	}
+
+	// Create issues to check pagination
+	for i := 0; i < 120; i++ {
+		if _, _, err := r.IssueCreate(
+			redmine.IssueCreate{
+				Issue: redmine.IssueCreateObject{
+					ProjectID: p.ID,
+					Subject:   "Issue " + strconv.Itoa(i),
+				},
+			},
+		); err != nil {
+			t.Fatal("Issue create error:", err)
+		}
+	}
+
+	is, _, err := r.IssuesAllGet(
+		redmine.IssueAllGetRequest{
+			Filters: redmine.IssueGetRequestFiltersInit().
+				FieldAdd("project_id", p.Identifier),
+		},
+	)
+	if err != nil {
+		t.Fatal("Issues get error:", err)
+	}
+	if len(is.Issues) != 120 || is.TotalCount != 120 {
+		t.Fatalf("Issues get error: expected 120 issues, got %d (total count %d)", len(is.Issues), is.TotalCount)
+	}
+
+	id := is.Issues[0].ID
+
+	// Update issue and check journal
+	if _, err := r.IssueUpdate(
+		id,
+		redmine.IssueUpdate{
+			Issue: redmine.IssueUpdateObject{
+				StatusID: func() *int64 {
+					s := StatusClosedID
+					return &s
+				}(),
+				Notes: func() *string {
+					s := "Closed"
+					return &s
+				}(),
+			},
+		},
+	); err != nil {
+		t.Fatal("Issue update error:", err)
+	}
+
+	i, _, err := r.IssueSingleGet(
+		id,
+		redmine.IssueSingleGetRequest{
+			Includes: []redmine.IssueInclude{redmine.IssueIncludeJournals},
+		},
+	)
+	if err != nil {
+		t.Fatal("Issue get error:", err)
+	}
+
+	if i.Journals == nil || len(*i.Journals) != 1 {
+		t.Fatal("Issue get error: expected one journal")
+	}
+
+	j := (*i.Journals)[0]
+	if j.Notes != "Closed" || len(j.Details) != 1 || j.Details[0].Name != "status_id" || j.Details[0].OldValue != "1" || j.Details[0].NewValue != "5" {
+		t.Fatalf("Issue get error: unexpected journal: %+v", j)
+	}
+
+	if i.ClosedOn == nil || i.ClosedOn.IsZero() == true {
+		t.Fatal("Issue get error: closed_on is not set")
+	}
+
+	// Closed issues are not listed by default
+	is, _, err = r.IssuesMultiGet(
+		redmine.IssueMultiGetRequest{
+			Filters: redmine.IssueGetRequestFiltersInit().
+				FieldAdd("project_id", p.Identifier),
+			Limit: 10,
+		},
+	)
+	if err != nil {
+		t.Fatal("Issues get error:", err)
+	}
+	if is.TotalCount != 119 || len(is.Issues) != 10 {
+		t.Fatalf("Issues get error: expected 10 of 119 issues, got %d of %d", len(is.Issues), is.TotalCount)
+	}
+
+	// Filters with operators
+	for e, f := range map[int]*redmine.IssueGetRequestFilters{
+		1: redmine.IssueGetRequestFiltersInit().
+			Add(redmine.IssueFilterFieldStatusID, redmine.FilterOperatorClosed),
+		11: redmine.IssueGetRequestFiltersInit().
+			Add(redmine.IssueFilterFieldStatusID, redmine.FilterOperatorAny).
+			Add(redmine.IssueFilterFieldSubject, redmine.FilterOperatorContains, "Issue 11"),
+		120: redmine.IssueGetRequestFiltersInit().
+			Add(redmine.IssueFilterFieldStatusID, redmine.FilterOperatorAny).
+			Add(redmine.IssueFilterFieldCreatedOn, redmine.FilterOperatorGreaterOrEqual, "2000-01-01"),
+		3: redmine.IssueGetRequestFiltersInit().
+			Add(redmine.IssueFilterFieldStatusID, redmine.FilterOperatorAny).
+			Add(redmine.IssueFilterFieldIssueID, redmine.FilterOperatorEqual, strconv.FormatInt(is.Issues[0].ID, 10), strconv.FormatInt(is.Issues[1].ID, 10), strconv.FormatInt(is.Issues[2].ID, 10)),
+		118: redmine.IssueGetRequestFiltersInit().
+			Add(redmine.IssueFilterFieldStatusID, redmine.FilterOperatorAny).
+			Add(redmine.IssueFilterFieldIssueID, redmine.FilterOperatorNotEqual, strconv.FormatInt(is.Issues[0].ID, 10), strconv.FormatInt(is.Issues[1].ID, 10)),
+	} {
+		is, _, err = r.IssuesMultiGet(
+			redmine.IssueMultiGetRequest{
+				Filters: f.FieldAdd("project_id", p.Identifier),
+				Limit:   1,
+			},
+		)
+		if err != nil {
+			t.Fatal("Issues get error:", err)
+		}
+		if is.TotalCount != int64(e) {
+			t.Fatalf("Issues get error: expected %d issues, got %d", e, is.TotalCount)
+		}
+	}
+
+	// Assignee must be a project member
+	if _, err := r.IssueUpdate(
+		id,
+		redmine.IssueUpdate{
+			Issue: redmine.IssueUpdateObject{
+				AssignedToID: func() *int64 {
+					s := AdminID
+					return &s
+				}(),
+			},
+		},
+	); err == nil || strings.Contains(err.Error(), "Assignee is invalid") == false {
+		t.Fatal("Issue update error: expected validation error, got:", err)
+	}
+
+	// Zero assignee is invalid, assignee is cleared with `Unassign`
+	if _, err := r.IssueUpdate(id, redmine.IssueUpdate{Issue: redmine.IssueUpdateObject{AssignedToID: redmine.Int64Ptr(0)}}); err == nil {
+		t.Fatal("Issue update error: zero assignee is accepted")
+	}
+
+	if _, err := r.IssueUpdate(id, redmine.IssueUpdate{Issue: redmine.IssueUpdateObject{Unassign: true}}); err != nil {
+		t.Fatal("Issue update error:", err)
+	}
+
+	// Project filters and tree
+	sub, _, err := r.ProjectCreate(
+		redmine.ProjectCreate{
+			Project: redmine.ProjectCreateObject{
+				Name:       "Test subproject",
+				Identifier: "test-subproject",
+				IsPublic: func() *bool {
+					b := false
+					return &b
+				}(),
+				ParentID: &p.ID,
+			},
+		},
+	)
+	if err != nil {
+		t.Fatal("Project create error:", err)
+	}
+
+	for _, e := range []struct {
+		f   *redmine.ProjectGetRequestFilters
+		ids []int64
+	}{
+		{redmine.ProjectGetRequestFiltersInit().ParentIDSet(p.ID), []int64{sub.ID}},
+		{redmine.ProjectGetRequestFiltersInit().IsPublicSet(false), []int64{sub.ID}},
+		{redmine.ProjectGetRequestFiltersInit().Add(redmine.ProjectFilterFieldIdentifier, redmine.FilterOperatorStartsWith, "test-sub"), []int64{sub.ID}},
+		{redmine.ProjectGetRequestFiltersInit().Add(redmine.ProjectFilterFieldName, redmine.FilterOperatorContains, "TEST"), []int64{p.ID, sub.ID}},
+		{redmine.ProjectGetRequestFiltersInit().Add(redmine.ProjectFilterFieldParentID, redmine.FilterOperatorNone), []int64{p.ID}},
+		{redmine.ProjectGetRequestFiltersInit().Add(redmine.ProjectFilterFieldCreatedOn, redmine.FilterOperatorGreaterOrEqual, time.Now().AddDate(0, 0, -1).Format("2006-01-02")), []int64{p.ID, sub.ID}},
+	} {
+
+		ps, _, err := r.ProjectAllGet(redmine.ProjectAllGetRequest{Filters: e.f})
+		if err != nil {
+			t.Fatal("Projects get error:", err)
+		}
+
+		var ids []int64
+		for _, p := range ps.Projects {
+			ids = append(ids, p.ID)
+		}
+
+		if slices.Equal(ids, e.ids) == false {
+			t.Fatalf("Projects get error: expected projects %v, got %v", e.ids, ids)
+		}
+	}
+
+	ps, _, err := r.ProjectAllGet(redmine.ProjectAllGetRequest{})
+	if err != nil {
+		t.Fatal("Projects get error:", err)
+	}
+
+	tree := redmine.ProjectTreeBuild(ps.Projects)
+
+	if d := tree.Descendants(p.ID); len(d) != 1 || d[0].ID != sub.ID {
+		t.Fatal("Project tree error: unexpected descendants:", d)
+	}
+
+	// Zero parent is invalid, project is moved to the top level with `Unparent`
+	sid := strconv.FormatInt(sub.ID, 10)
+
+	if _, err := r.ProjectUpdate(sid, redmine.ProjectUpdate{Project: redmine.ProjectUpdateObject{ParentID: redmine.Int64Ptr(0)}}); err == nil {
+		t.Fatal("Project update error: zero parent is accepted")
+	}
+
+	if _, err := r.ProjectUpdate(sid, redmine.ProjectUpdate{Project: redmine.ProjectUpdateObject{Unparent: true}}); err != nil {
+		t.Fatal("Project update error:", err)
+	}
+
+	if sp, _, err := r.ProjectSingleGet(sid, redmine.ProjectSingleGetRequest{}); err != nil || sp.Parent.ID != 0 {
+		t.Fatal("Project update error: project is not moved to the top level:", err)
+	}
+
+	// Deleting project removes its issues
+	if _, err := r.ProjectDelete(p.Identifier); err != nil {
+		t.Fatal("Project delete error:", err)
+	}
+
+	if _, _, err := r.IssueSingleGet(id, redmine.IssueSingleGetRequest{}); err == nil {
+		t.Fatal("Issue get error: issue of deleted project is available")
+	}
+}
+
+func TestProjectCopy(t *testing.T) {
+
+	s := Init(Settings{})
+	defer s.Close()
+
+	r := s.Context()
+
+	p, _, err := r.ProjectCreate(
+		redmine.ProjectCreate{
+			Project: redmine.ProjectCreateObject{
+				Name:               "Source project",
+				Identifier:         "source",
+				TrackerIDs:         &[]int64{TrackerBugID, TrackerSupportID},
+				EnabledModuleNames: &[]string{"issue_tracking", "wiki"},
+			},
+		},
+	)
+	if err != nil {
+		t.Fatal("Project create error:", err)
+	}
+
+	uid, _ := s.UserAdd("jdoe", "John", "Doe", "jdoe@example.net")
+
+	if _, _, err := r.MembershipAdd(
+		p.Identifier,
+		redmine.MembershipAdd{
+			Membership: redmine.MembershipAddObject{
+				UserID:  uid,
+				RoleIDs: []int64{RoleDeveloperID},
+			},
+		},
+	); err != nil {
+		t.Fatal("Membership add error:", err)
+	}
+
+	c, _, err := r.IssueCategoryCreate(
+		p.Identifier,
+		redmine.IssueCategoryCreate{
+			IssueCategory: redmine.IssueCategoryCreateObject{
+				Name:         "Backend",
+				AssignedToID: &uid,
+			},
+		},
+	)
+	if err != nil {
+		t.Fatal("Issue category create error:", err)
+	}
+
+	// Only project members may be assigned to category
+	if _, _, err := r.IssueCategoryCreate(
+		p.Identifier,
+		redmine.IssueCategoryCreate{
+			IssueCategory: redmine.IssueCategoryCreateObject{
+				Name:         "Frontend",
+				AssignedToID: redmine.Int64Ptr(AdminID),
+			},
+		},
+	); err == nil || strings.Contains(err.Error(), "Assignee is invalid") == false {
+		t.Fatal("Issue category create error: expected validation error, got:", err)
+	}
+
+	v, _, err := r.VersionCreate(
+		p.Identifier,
+		redmine.VersionCreate{
+			Version: redmine.VersionCreateObject{
+				Name: "1.0",
+			},
+		},
+	)
+	if err != nil {
+		t.Fatal("Version create error:", err)
+	}
+
+	parent, _, err := r.IssueCreate(
+		redmine.IssueCreate{
+			Issue: redmine.IssueCreateObject{
+				ProjectID:      p.ID,
+				Subject:        "Parent",
+				CategoryID:     &c.ID,
+				FixedVersionID: &v.ID,
+				AssignedToID:   &uid,
+			},
+		},
+	)
+	if err != nil {
+		t.Fatal("Issue create error:", err)
+	}
+
+	child, _, err := r.IssueCreate(
+		redmine.IssueCreate{
+			Issue: redmine.IssueCreateObject{
+				ProjectID:     p.ID,
+				Subject:       "Child",
+				StatusID:      redmine.Int64Ptr(StatusClosedID),
+				ParentIssueID: &parent.ID,
+			},
+		},
+	)
+	if err != nil {
+		t.Fatal("Issue create error:", err)
+	}
+
+	// Closed version can't be assigned to issues
+	closed := redmine.VersionStatusClosed
+
+	if _, err := r.VersionUpdate(
+		v.ID,
+		redmine.VersionUpdate{
+			Version: redmine.VersionUpdateObject{
+				Status: &closed,
+			},
+		},
+	); err != nil {
+		t.Fatal("Version update error:", err)
+	}
+
+	if _, err := r.IssueUpdate(
+		child.ID,
+		redmine.IssueUpdate{
+			Issue: redmine.IssueUpdateObject{
+				FixedVersionID: &v.ID,
+			},
+		},
+	); err == nil || strings.Contains(err.Error(), "Target version is not open") == false {
+		t.Fatal("Issue update error: expected validation error, got:", err)
+	}
+
+	for _, w := range []struct {
+		title  string
+		parent *string
+	}{
+		{"Wiki", nil},
+		{"Child_page", redmine.StringPtr("Wiki")},
+	} {
+		if _, _, err := r.WikiCreate(
+			p.Identifier,
+			w.title,
+			redmine.WikiCreate{
+				WikiPage: redmine.WikiCreateObject{
+					Text:        w.title + " text",
+					ParentTitle: w.parent,
+				},
+			},
+		); err != nil {
+			t.Fatal("Wiki create error:", err)
+		}
+	}
+
+	res, _, err := r.ProjectCopy(
+		p.Identifier,
+		redmine.ProjectCopyRequest{
+			Project: redmine.ProjectCreateObject{
+				Name:       "Copy project",
+				Identifier: "copy",
+			},
+			Parts: []redmine.ProjectCopyPart{
+				redmine.ProjectCopyPartTrackers,
+				redmine.ProjectCopyPartModules,
+				redmine.ProjectCopyPartMemberships,
+				redmine.ProjectCopyPartIssueCategories,
+				redmine.ProjectCopyPartVersions,
+				redmine.ProjectCopyPartWiki,
+				redmine.ProjectCopyPartIssues,
+			},
+		},
+	)
+	if err != nil {
+		t.Fatal("Project copy error:", err)
+	}
+
+	cp, _, err := r.ProjectSingleGet(
+		res.Project.Identifier,
+		redmine.ProjectSingleGetRequest{
+			Includes: []redmine.ProjectInclude{
+				redmine.ProjectIncludeTrackers,
+				redmine.ProjectIncludeEnabledModules,
+				redmine.ProjectIncludeIssueCategories,
+			},
+		},
+	)
+	if err != nil {
+		t.Fatal("Project get error:", err)
+	}
+
+	if len(*cp.Trackers) != 2 || len(*cp.EnabledModules) != 2 || len(*cp.IssueCategories) != 1 {
+		t.Fatal("Project copy error: trackers, modules or categories are not copied")
+	}
+
+	ms, _, err := r.MembershipAllGet(cp.Identifier)
+	if err != nil {
+		t.Fatal("Memberships get error:", err)
+	}
+	if len(ms.Memberships) != 1 || ms.Memberships[0].User == nil || ms.Memberships[0].User.ID != uid {
+		t.Fatal("Project copy error: memberships are not copied")
+	}
+
+	vs, _, err := r.VersionAllGet(cp.Identifier)
+	if err != nil {
+		t.Fatal("Versions get error:", err)
+	}
+	if len(vs.Versions) != 1 || vs.Versions[0].ID != res.VersionIDs[v.ID] || vs.Versions[0].Status != redmine.VersionStatusClosed {
+		t.Fatal("Project copy error: versions are not copied:", vs.Versions)
+	}
+
+	ws, _, err := r.WikiAllGet(cp.Identifier)
+	if err != nil {
+		t.Fatal("Wiki get error:", err)
+	}
+	if len(ws) != 2 {
+		t.Fatal("Project copy error: wiki pages are not copied")
+	}
+
+	is, _, err := r.IssuesAllGet(
+		redmine.IssueAllGetRequest{
+			Filters: redmine.IssueGetRequestFiltersInit().
+				Add(redmine.IssueFilterFieldProjectID, redmine.FilterOperatorEqual, cp.Identifier).
+				Add(redmine.IssueFilterFieldStatusID, redmine.FilterOperatorAny),
+		},
+	)
+	if err != nil {
+		t.Fatal("Issues get error:", err)
+	}
+	if len(is.Issues) != 2 {
+		t.Fatalf("Project copy error: expected 2 issues, got %d", len(is.Issues))
+	}
+
+	for _, i := range is.Issues {
+		switch i.Subject {
+		case "Parent":
+			if i.Category == nil || i.Category.ID != res.IssueCategoryIDs[c.ID] ||
+				i.FixedVersion == nil || i.FixedVersion.ID != res.VersionIDs[v.ID] ||
+				i.AssignedTo == nil || i.AssignedTo.ID != uid {
+				t.Fatal("Project copy error: issue attributes are not copied:", i)
+			}
+		case "Child":
+			if i.Parent == nil || i.Parent.ID != res.IssueIDs[parent.ID] || i.Status.ID != StatusClosedID {
+				t.Fatal("Project copy error: issue attributes are not copied:", i)
+			}
+		}
+	}
+
+	// Close and reopen
+	if _, err := r.ProjectClose(cp.Identifier); err != nil {
+		t.Fatal("Project close error:", err)
+	}
+
+	cp, _, err = r.ProjectSingleGet(cp.Identifier, redmine.ProjectSingleGetRequest{})
+	if err != nil || cp.Status != redmine.ProjectStatusClosed {
+		t.Fatal("Project close error: project is not closed:", err)
+	}
+
+	if _, err := r.ProjectReopen(cp.Identifier); err != nil {
+		t.Fatal("Project reopen error:", err)
+	}
+
+	cp, _, err = r.ProjectSingleGet(cp.Identifier, redmine.ProjectSingleGetRequest{})
+	if err != nil || cp.Status != redmine.ProjectStatusActive {
+		t.Fatal("Project reopen error: project is not active:", err)
+	}
+
+	// Version in use can't be deleted, category issues are reassigned
+	if _, err := r.VersionDelete(v.ID); err == nil {
+		t.Fatal("Version delete error: version in use is deleted")
+	}
+
+	if _, err := r.IssueCategoryDelete(c.ID, redmine.IssueCategoryDeleteRequest{}); err != nil {
+		t.Fatal("Issue category delete error:", err)
+	}
+
+	i, _, err := r.IssueSingleGet(parent.ID, redmine.IssueSingleGetRequest{})
+	if err != nil || i.Category != nil {
+		t.Fatal("Issue category delete error: issue category is not reset:", err)
+	}
+}
+
+func TestMembershipsGroups(t *testing.T) {
+
+	s := Init(Settings{})
+	defer s.Close()
+
+	r := s.Context()
+
+	p, _, err := r.ProjectCreate(
+		redmine.ProjectCreate{
+			Project: redmine.ProjectCreateObject{
+				Name:       "Test project",
+				Identifier: "test-project",
+			},
+		},
+	)
+	if err != nil {
+		t.Fatal("Project create error:", err)
+	}
+
+	uid, _ := s.UserAdd("jdoe", "John", "Doe", "jdoe@example.net")
+
+	g, _, err := r.GroupCreate(
+		redmine.GroupCreate{
+			Group: redmine.GroupCreateObject{
+				Name:    "Developers",
+				UserIDs: &[]int64{uid},
+			},
+		},
+	)
+	if err != nil {
+		t.Fatal("Group create error:", err)
+	}
+
+	gm, _, err := r.MembershipAdd(
+		p.Identifier,
+		redmine.MembershipAdd{
+			Membership: redmine.MembershipAddObject{
+				UserID:  g.ID,
+				RoleIDs: []int64{RoleDeveloperID},
+			},
+		},
+	)
+	if err != nil {
+		t.Fatal("Membership add error:", err)
+	}
+
+	// Group member inherits group roles
+	ms, _, err := r.MembershipAllGet(p.Identifier)
+	if err != nil {
+		t.Fatal("Memberships get error:", err)
+	}
+	if len(ms.Memberships) != 2 {
+		t.Fatalf("Memberships get error: expected 2 memberships, got %d", len(ms.Memberships))
+	}
+
+	um := ms.Memberships[1]
+	if um.User == nil || um.User.ID != uid || len(um.Roles) != 1 || um.Roles[0].Inherited == false {
+		t.Fatalf("Memberships get error: unexpected user membership: %+v", um)
+	}
+
+	// Duplicate membership
+	if _, _, err := r.MembershipAdd(
+		p.Identifier,
+		redmine.MembershipAdd{
+			Membership: redmine.MembershipAddObject{
+				UserID:  g.ID,
+				RoleIDs: []int64{RoleReporterID},
+			},
+		},
+	); err == nil || strings.Contains(err.Error(), "Principal has already been taken") == false {
+		t.Fatal("Membership add error: expected validation error, got:", err)
+	}
+
+	// Removing user from group drops inherited membership
+	if _, err := r.GroupDeleteUser(g.ID, uid); err != nil {
+		t.Fatal("Group user delete error:", err)
+	}
+
+	ms, _, err = r.MembershipAllGet(p.Identifier)
+	if err != nil {
+		t.Fatal("Memberships get error:", err)
+	}
+	if len(ms.Memberships) != 1 || ms.Memberships[0].ID != gm.ID {
+		t.Fatalf("Memberships get error: unexpected memberships: %+v", ms.Memberships)
+	}
+}
+
+func TestMembershipSync(t *testing.T) {
+
+	s := Init(Settings{})
+	defer s.Close()
+
+	r := s.Context()
+
+	p, _, err := r.ProjectCreate(
+		redmine.ProjectCreate{
+			Project: redmine.ProjectCreateObject{
+				Name:       "Test project",
+				Identifier: "test-project",
+			},
+		},
+	)
+	if err != nil {
+		t.Fatal("Project create error:", err)
+	}
+
+	jid, _ := s.UserAdd("jdoe", "John", "Doe", "jdoe@example.net")
+	aid, _ := s.UserAdd("asmith", "Alice", "Smith", "asmith@example.net")
+	bid, _ := s.UserAdd("bjones", "Bob", "Jones", "bjones@example.net")
+
+	g, _, err := r.GroupCreate(
+		redmine.GroupCreate{
+			Group: redmine.GroupCreateObject{
+				Name:    "Developers",
+				UserIDs: &[]int64{jid},
+			},
+		},
+	)
+	if err != nil {
+		t.Fatal("Group create error:", err)
+	}
+
+	for id, role := range map[int64]int64{aid: RoleReporterID, bid: RoleManagerID} {
+		if _, _, err := r.MembershipAdd(
+			p.Identifier,
+			redmine.MembershipAdd{
+				Membership: redmine.MembershipAddObject{
+					UserID:  id,
+					RoleIDs: []int64{role},
+				},
+			},
+		); err != nil {
+			t.Fatal("Membership add error:", err)
+		}
+	}
+
+	desired := []redmine.MembershipSyncObject{
+		{GroupID: g.ID, RoleIDs: []int64{RoleDeveloperID}},
+		{UserID: jid, RoleIDs: []int64{RoleDeveloperID, RoleManagerID}},
+		{UserID: aid, RoleIDs: []int64{RoleReporterID}},
+	}
+
+	// Group membership is added first, so John gets only Manager role as own one
+	res, _, err := r.MembershipSync(p.Identifier, desired)
+	if err != nil {
+		t.Fatal("Membership sync error:", err)
+	}
+
+	if len(res.Added) != 1 || res.Added[0].Group == nil || len(res.Updated) != 1 || len(res.Deleted) != 1 {
+		t.Fatalf("Membership sync error: unexpected result: %+v", res)
+	}
+
+	roles := func(uid int64) (own, inherited []int64) {
+		ms, _, err := r.MembershipAllGet(p.Identifier)
+		if err != nil {
+			t.Fatal("Memberships get error:", err)
+		}
+		for _, m := range ms.Memberships {
+			if m.User == nil || m.User.ID != uid {
+				continue
+			}
+			for _, rl := range m.Roles {
+				if rl.Inherited == true {
+					inherited = append(inherited, rl.ID)
+				} else {
+					own = append(own, rl.ID)
+				}
+			}
+		}
+		return own, inherited
+	}
+
+	if own, inherited := roles(jid); len(own) != 1 || own[0] != RoleManagerID || len(inherited) != 1 || inherited[0] != RoleDeveloperID {
+		t.Fatalf("Membership sync error: unexpected roles: own %v, inherited %v", own, inherited)
+	}
+
+	if own, _ := roles(bid); len(own) != 0 {
+		t.Fatal("Membership sync error: membership is not deleted")
+	}
+
+	// Nothing to change
+	res, status, err := r.MembershipSync(p.Identifier, desired)
+	if err != nil {
+		t.Fatal("Membership sync error:", err)
+	}
+
+	if len(res.Added)+len(res.Updated)+len(res.Deleted) != 0 || status != http.StatusOK {
+		t.Fatalf("Membership sync error: unexpected result: %+v", res)
+	}
+
+	// Diff plans the same changes sync makes
+	ms, _, err := r.MembershipAllGet(p.Identifier)
+	if err != nil {
+		t.Fatal("Memberships get error:", err)
+	}
+
+	d, err := redmine.MembershipSyncDiff(ms.Memberships, []redmine.MembershipSyncObject{desired[0], desired[2]})
+	if err != nil {
+		t.Fatal("Membership sync diff error:", err)
+	}
+
+	if len(d.Add) != 0 || len(d.Delete) != 0 || len(d.Update) != 1 || d.Update[0].Membership.User.ID != jid ||
+		slices.Equal(d.Update[0].OwnRoleIDs, []int64{RoleManagerID}) == false || len(d.Update[0].RoleIDs) != 0 {
+		t.Fatalf("Membership sync diff error: unexpected plan: %+v", d)
+	}
+
+	// Membership with inherited roles is kept without own roles
+	res, _, err = r.MembershipSync(p.Identifier, []redmine.MembershipSyncObject{desired[0], desired[2]})
+	if err != nil {
+		t.Fatal("Membership sync error:", err)
+	}
+
+	if len(res.Updated) != 1 || len(res.Deleted) != 0 {
+		t.Fatalf("Membership sync error: unexpected result: %+v", res)
+	}
+
+	if own, inherited := roles(jid); len(own) != 0 || len(inherited) != 1 {
+		t.Fatalf("Membership sync error: unexpected roles: own %v, inherited %v", own, inherited)
+	}
+
+	if _, _, err := r.MembershipSync(p.Identifier, []redmine.MembershipSyncObject{{UserID: jid, GroupID: g.ID, RoleIDs: []int64{RoleDeveloperID}}}); err == nil {
+		t.Fatal("Membership sync error: invalid desired membership is accepted")
+	}
+}
+
+func TestUsersTimeEntriesWikiAttachments(t *testing.T) {
+
+	s := Init(Settings{})
+	defer s.Close()
+
+	r := s.Context()
+
+	// Bad API key
+	if _, _, err := redmine.Init(redmine.Settings{Endpoint: s.URL(), APIKey: "bad"}).UserCurrentGet(redmine.UserCurrentGetRequest{}); err == nil {
+		t.Fatal("User current get error: request with bad API key succeeded")
+	}
+
+	u, _, err := r.UserCurrentGet(redmine.UserCurrentGetRequest{})
+	if err != nil {
+		t.Fatal("User current get error:", err)
+	}
+	if u.ID != AdminID || u.Login != AdminLogin {
+		t.Fatalf("User current get error: unexpected user: %+v", u)
+	}
+
+	// Users statuses
+	lid, _ := s.UserAdd("asmith", "Alice", "Smith", "asmith@example.net")
+
+	if _, err := r.UserLock(lid); err != nil {
+		t.Fatal("User lock error:", err)
+	}
+
+	usersCount := func(f *redmine.UserGetRequestFilters) int {
+		us, _, err := r.UserAllGet(redmine.UserAllGetRequest{Filters: f})
+		if err != nil {
+			t.Fatal("Users get error:", err)
+		}
+		return len(us.Users)
+	}
+
+	active := usersCount(nil)
+
+	if usersCount(redmine.UserGetRequestFiltersInit().StatusSet(redmine.UserStatusLocked)) != 1 ||
+		usersCount(redmine.UserGetRequestFiltersInit().StatusAnySet()) != active+1 {
+		t.Fatal("Users get error: unexpected users count by status")
+	}
+
+	if _, err := r.UserUnlock(lid); err != nil {
+		t.Fatal("User unlock error:", err)
+	}
+
+	if lu, _, err := r.UserSingleGet(lid, redmine.UserSingleGetRequest{}); err != nil || *lu.Status != redmine.UserStatusActive {
+		t.Fatal("User unlock error: user is not active:", err)
+	}
+
+	if _, _, err := r.UserCreate(
+		redmine.UserCreate{
+			User: redmine.UserCreateObject{
+				Login:     "jdoe",
+				FirstName: "John",
+				LastName:  "Doe",
+				Mail:      "invalid",
+			},
+		},
+	); err == nil || strings.Contains(err.Error(), "Email is invalid") == false {
+		t.Fatal("User create error: expected validation error, got:", err)
+	}
+
+	p, _, err := r.ProjectCreate(
+		redmine.ProjectCreate{
+			Project: redmine.ProjectCreateObject{
+				Name:       "Test project",
+				Identifier: "test-project",
+			},
+		},
+	)
+	if err != nil {
+		t.Fatal("Project create error:", err)
+	}
+
+	// Time entries
+	for _, h := range []float64{1, 2.5} {
+		if _, _, err := r.TimeEntryCreate(
+			redmine.TimeEntryCreate{
+				TimeEntry: redmine.TimeEntryCreateObject{
+					ProjectID:  &p.Identifier,
+					ActivityID: ActivityDevelopmentID,
+					Hours:      h,
+					SpentOn: func() *redmine.Date {
+						d := redmine.DateInit(2024, time.January, 10)
+						return &d
+					}(),
+				},
+			},
+		); err != nil {
+			t.Fatal("Time entry create error:", err)
+		}
+	}
+
+	te, _, err := r.TimeEntryAllGet(
+		redmine.TimeEntryAllGetRequest{
+			Filters: redmine.TimeEntryGetRequestFiltersInit().
+				ProjectSet(p.Identifier).
+				SpentOnSet("2024-01-01", "2024-01-31"),
+		},
+	)
+	if err != nil {
+		t.Fatal("Time entries get error:", err)
+	}
+	if len(te.TimeEntries) != 2 {
+		t.Fatalf("Time entries get error: expected 2 time entries, got %d", len(te.TimeEntries))
+	}
+
+	// Time entries of issue with subtasks
+	var issueIDs []int64
+
+	for _, h := range []float64{1.5, 2} {
+
+		ic := redmine.IssueCreateObject{
+			ProjectID: p.ID,
+			Subject:   "Issue with time",
+		}
+		if len(issueIDs) > 0 {
+			ic.ParentIssueID = &issueIDs[0]
+		}
+
+		i, _, err := r.IssueCreate(redmine.IssueCreate{Issue: ic})
+		if err != nil {
+			t.Fatal("Issue create error:", err)
+		}
+
+		issueIDs = append(issueIDs, i.ID)
+
+		if _, _, err := r.TimeEntryCreate(
+			redmine.TimeEntryCreate{
+				TimeEntry: redmine.TimeEntryCreateObject{
+					IssueID:    &i.ID,
+					ActivityID: ActivityDevelopmentID,
+					Hours:      h,
+				},
+			},
+		); err != nil {
+			t.Fatal("Time entry create error:", err)
+		}
+	}
+
+	for e, f := range map[float64]*redmine.TimeEntryGetRequestFilters{
+		1.5: redmine.TimeEntryGetRequestFiltersInit().IssueIDSet(issueIDs[0], false),
+		3.5: redmine.TimeEntryGetRequestFiltersInit().IssueIDSet(issueIDs[0], true),
+		7: redmine.TimeEntryGetRequestFiltersInit().
+			ProjectSet(p.Identifier).
+			UserIDsSet(AdminID, 100),
+	} {
+		h, _, err := r.TimeEntryTotalHoursGet(f)
+		if err != nil {
+			t.Fatal("Time entries total hours get error:", err)
+		}
+		if h != e {
+			t.Fatalf("Time entries total hours get error: expected %v hours, got %v", e, h)
+		}
+	}
+
+	te, _, err = r.TimeEntryMultiGet(
+		redmine.TimeEntryMultiGetRequest{
+			Filters: redmine.TimeEntryGetRequestFiltersInit().
+				IssueIDSet(issueIDs[0], true),
+			Limit: 1,
+		},
+	)
+	if err != nil {
+		t.Fatal("Time entries get error:", err)
+	}
+	if len(te.TimeEntries) != 1 || te.TotalCount != 2 {
+		t.Fatalf("Time entries get error: expected 1 of 2 time entries, got %d of %d", len(te.TimeEntries), te.TotalCount)
+	}
+
+	// Time entry custom fields
+	billableID := s.CustomFieldAdd(redmine.CustomFieldObject{Name: "Billable", CustomizedType: "time_entry", FieldFormat: "bool"})
+	invoiceID := s.CustomFieldAdd(redmine.CustomFieldObject{Name: "Invoice #", CustomizedType: "time_entry", FieldFormat: "string"})
+
+	tc, _, err := r.TimeEntryCreate(
+		redmine.TimeEntryCreate{
+			TimeEntry: redmine.TimeEntryCreateObject{
+				IssueID:    &issueIDs[0],
+				ActivityID: ActivityDevelopmentID,
+				Hours:      1,
+				CustomFields: &[]redmine.CustomFieldUpdateObject{
+					{ID: billableID, Value: redmine.CustomFieldValueBool(true)},
+				},
+			},
+		},
+	)
+	if err != nil {
+		t.Fatal("Time entry create error:", err)
+	}
+
+	cfs := redmine.CustomFieldsSet(nil, invoiceID, redmine.CustomFieldValueInit("INV-1"))
+
+	if _, err := r.TimeEntryUpdate(tc.ID, redmine.TimeEntryUpdate{TimeEntry: redmine.TimeEntryUpdateObject{CustomFields: &cfs}}); err != nil {
+		t.Fatal("Time entry update error:", err)
+	}
+
+	tg, _, err := r.TimeEntrySingleGet(tc.ID, redmine.TimeEntrySingleGetRequest{})
+	if err != nil {
+		t.Fatal("Time entry get error:", err)
+	}
+
+	if c, b := redmine.CustomFieldGetByName(tg.CustomFields, "Billable"); b == false || c.Value.String() != "1" {
+		t.Fatalf("Time entry get error: unexpected custom fields %+v", tg.CustomFields)
+	}
+
+	if c, b := redmine.CustomFieldGetByID(tg.CustomFields, invoiceID); b == false || c.Value.String() != "INV-1" {
+		t.Fatalf("Time entry get error: unexpected custom fields %+v", tg.CustomFields)
+	}
+
+	// Wiki pages
+	if _, _, err := r.WikiCreate(p.Identifier, "Start", redmine.WikiCreate{WikiPage: redmine.WikiCreateObject{Text: "v1"}}); err != nil {
+		t.Fatal("Wiki create error:", err)
+	}
+
+	if _, err := r.WikiUpdate(p.Identifier, "Start", redmine.WikiUpdate{WikiPage: redmine.WikiUpdateObject{Text: "v2"}}); err != nil {
+		t.Fatal("Wiki update error:", err)
+	}
+
+	wp, _, err := r.WikiSingleVersionGet(p.Identifier, "Start", 1, redmine.WikiSingleGetRequest{})
+	if err != nil {
+		t.Fatal("Wiki get error:", err)
+	}
+	if wp.Text != "v1" || wp.Version != 1 {
+		t.Fatalf("Wiki get error: unexpected page: %+v", wp)
+	}
+
+	// Attachments
+	up, _, err := r.AttachmentUploadStream(strings.NewReader("content"), "file.txt")
+	if err != nil {
+		t.Fatal("Attachment upload error:", err)
+	}
+
+	i, _, err := r.IssueCreate(
+		redmine.IssueCreate{
+			Issue: redmine.IssueCreateObject{
+				ProjectID: p.ID,
+				Subject:   "Issue with attachment",
+				Uploads:   &[]redmine.AttachmentUploadObject{up},
+			},
+		},
+	)
+	if err != nil {
+		t.Fatal("Issue create error:", err)
+	}
+
+	i, _, err = r.IssueSingleGet(
+		i.ID,
+		redmine.IssueSingleGetRequest{
+			Includes: []redmine.IssueInclude{redmine.IssueIncludeAttachments},
+		},
+	)
+	if err != nil {
+		t.Fatal("Issue get error:", err)
+	}
+	if i.Attachments == nil || len(*i.Attachments) != 1 {
+		t.Fatal("Issue get error: expected one attachment")
+	}
+
+	f, a, _, err := r.AttachmentDownloadStream((*i.Attachments)[0].ID)
+	if err != nil {
+		t.Fatal("Attachment download error:", err)
+	}
+	defer f.Close()
+
+	c, err := io.ReadAll(f)
+	if err != nil {
+		t.Fatal("Attachment download error:", err)
+	}
+
+	if string(c) != "content" || a.FileName != "file.txt" || a.FileSize != int64(len(c)) {
+		t.Fatalf("Attachment download error: unexpected attachment %+v with content %q", a, c)
+	}
+}
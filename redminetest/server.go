@@ -0,0 +1,329 @@
+// Package redminetest provides an in-memory fake Redmine server for offline tests.
+//
+// The server implements projects, issues, users, groups, memberships, time entries,
+// wiki pages, attachments and uploads, enumerations, issue statuses, trackers and
+// custom fields REST API with pagination, includes, filters and error bodies
+// close to real Redmine ones.
+//
+// Server is seeded with an admin user (ID `AdminID`, API key `APIKeyDefault`),
+// issue statuses, trackers, priorities, time entry activities and roles
+// described by the constants of this package.
+package redminetest
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	redmine "github.com/nixys/nxs-go-redmine/v5"
+)
+
+const (
+	limitDefault = 25
+	limitMax     = 100
+
+	dateFormat = "2006-01-02"
+)
+
+// Settings contains data to init fake server
+type Settings struct {
+	APIKey string           // API key of the admin user. `APIKeyDefault` if not set
+	Now    func() time.Time // Clock used for `created_on` and `updated_on` fields. `time.Now` if not set
+}
+
+// Server is an in-memory fake Redmine server
+type Server struct {
+	srv *httptest.Server
+	now func() time.Time
+
+	mu sync.Mutex
+	db *database
+}
+
+type route struct {
+	method  string
+	pattern []string // `*` matches any path segment
+	handler func(s *Server, w http.ResponseWriter, r *http.Request, p []string)
+}
+
+// Init creates and starts new fake server. Server must be closed with `Close()`
+func Init(st Settings) *Server {
+
+	s := &Server{
+		now: st.Now,
+	}
+
+	if s.now == nil {
+		s.now = time.Now
+	}
+
+	if st.APIKey == "" {
+		st.APIKey = APIKeyDefault
+	}
+
+	s.db = seed(st.APIKey, s.now())
+
+	s.srv = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	s.db.endpoint = s.srv.URL
+
+	return s
+}
+
+// URL returns fake server endpoint
+func (s *Server) URL() string {
+	return s.srv.URL
+}
+
+// Context returns Redmine context initialized to work with fake server as admin
+func (s *Server) Context() *redmine.Context {
+
+	s.mu.Lock()
+	key := s.db.users[AdminID].apiKey
+	s.mu.Unlock()
+
+	return redmine.Init(
+		redmine.Settings{
+			Endpoint: s.srv.URL,
+			APIKey:   key,
+		},
+	)
+}
+
+// Close shuts down fake server
+func (s *Server) Close() {
+	s.srv.Close()
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	u := s.db.userByAPIKey(r.Header.Get("X-Redmine-API-Key"))
+	if u == nil {
+		u = s.db.userByAPIKey(r.URL.Query().Get("key"))
+	}
+	if u == nil {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	s.db.currentUserID = u.id
+
+	p := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+
+	// Attachment content download is the only non-JSON route
+	if len(p) == 4 && p[0] == "attachments" && p[1] == "download" && r.Method == http.MethodGet {
+		s.attachmentDownload(w, r, p)
+		return
+	}
+
+	if len(p) == 0 || strings.HasSuffix(p[len(p)-1], ".json") == false {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	p[len(p)-1] = strings.TrimSuffix(p[len(p)-1], ".json")
+
+	for _, rt := range routes {
+
+		if rt.method != r.Method || len(rt.pattern) != len(p) {
+			continue
+		}
+
+		match := true
+		for i, e := range rt.pattern {
+			if e != "*" && e != p[i] {
+				match = false
+				break
+			}
+		}
+
+		if match == true {
+			rt.handler(s, w, r, p)
+			return
+		}
+	}
+
+	w.WriteHeader(http.StatusNotFound)
+}
+
+var routes = []route{
+
+	// Projects
+	{http.MethodGet, []string{"projects"}, (*Server).projectsList},
+	{http.MethodPost, []string{"projects"}, (*Server).projectCreate},
+	{http.MethodGet, []string{"projects", "*"}, (*Server).projectGet},
+	{http.MethodPut, []string{"projects", "*"}, (*Server).projectUpdate},
+	{http.MethodDelete, []string{"projects", "*"}, (*Server).projectDelete},
+	{http.MethodPut, []string{"projects", "*", "archive"}, (*Server).projectArchive},
+	{http.MethodPut, []string{"projects", "*", "unarchive"}, (*Server).projectUnarchive},
+	{http.MethodPut, []string{"projects", "*", "close"}, (*Server).projectClose},
+	{http.MethodPut, []string{"projects", "*", "reopen"}, (*Server).projectReopen},
+
+	// Memberships
+	{http.MethodGet, []string{"projects", "*", "memberships"}, (*Server).membershipsList},
+	{http.MethodPost, []string{"projects", "*", "memberships"}, (*Server).membershipCreate},
+	{http.MethodGet, []string{"memberships", "*"}, (*Server).membershipGet},
+	{http.MethodPut, []string{"memberships", "*"}, (*Server).membershipUpdate},
+	{http.MethodDelete, []string{"memberships", "*"}, (*Server).membershipDelete},
+
+	// Versions
+	{http.MethodGet, []string{"projects", "*", "versions"}, (*Server).versionsList},
+	{http.MethodPost, []string{"projects", "*", "versions"}, (*Server).versionCreate},
+	{http.MethodGet, []string{"versions", "*"}, (*Server).versionGet},
+	{http.MethodPut, []string{"versions", "*"}, (*Server).versionUpdate},
+	{http.MethodDelete, []string{"versions", "*"}, (*Server).versionDelete},
+
+	// Issue categories
+	{http.MethodGet, []string{"projects", "*", "issue_categories"}, (*Server).issueCategoriesList},
+	{http.MethodPost, []string{"projects", "*", "issue_categories"}, (*Server).issueCategoryCreate},
+	{http.MethodGet, []string{"issue_categories", "*"}, (*Server).issueCategoryGet},
+	{http.MethodPut, []string{"issue_categories", "*"}, (*Server).issueCategoryUpdate},
+	{http.MethodDelete, []string{"issue_categories", "*"}, (*Server).issueCategoryDelete},
+
+	// Issues
+	{http.MethodGet, []string{"issues"}, (*Server).issuesList},
+	{http.MethodPost, []string{"issues"}, (*Server).issueCreate},
+	{http.MethodGet, []string{"issues", "*"}, (*Server).issueGet},
+	{http.MethodPut, []string{"issues", "*"}, (*Server).issueUpdate},
+	{http.MethodDelete, []string{"issues", "*"}, (*Server).issueDelete},
+	{http.MethodPost, []string{"issues", "*", "watchers"}, (*Server).issueWatcherAdd},
+	{http.MethodDelete, []string{"issues", "*", "watchers", "*"}, (*Server).issueWatcherDelete},
+
+	// Users
+	{http.MethodGet, []string{"users"}, (*Server).usersList},
+	{http.MethodPost, []string{"users"}, (*Server).userCreate},
+	{http.MethodGet, []string{"users", "*"}, (*Server).userGet},
+	{http.MethodPut, []string{"users", "*"}, (*Server).userUpdate},
+	{http.MethodDelete, []string{"users", "*"}, (*Server).userDelete},
+
+	// Groups
+	{http.MethodGet, []string{"groups"}, (*Server).groupsList},
+	{http.MethodPost, []string{"groups"}, (*Server).groupCreate},
+	{http.MethodGet, []string{"groups", "*"}, (*Server).groupGet},
+	{http.MethodPut, []string{"groups", "*"}, (*Server).groupUpdate},
+	{http.MethodDelete, []string{"groups", "*"}, (*Server).groupDelete},
+	{http.MethodPost, []string{"groups", "*", "users"}, (*Server).groupUserAdd},
+	{http.MethodDelete, []string{"groups", "*", "users", "*"}, (*Server).groupUserDelete},
+
+	// Time entries
+	{http.MethodGet, []string{"time_entries"}, (*Server).timeEntriesList},
+	{http.MethodPost, []string{"time_entries"}, (*Server).timeEntryCreate},
+	{http.MethodGet, []string{"time_entries", "*"}, (*Server).timeEntryGet},
+	{http.MethodPut, []string{"time_entries", "*"}, (*Server).timeEntryUpdate},
+	{http.MethodDelete, []string{"time_entries", "*"}, (*Server).timeEntryDelete},
+
+	// Wiki pages
+	{http.MethodGet, []string{"projects", "*", "wiki", "index"}, (*Server).wikiList},
+	{http.MethodGet, []string{"projects", "*", "wiki", "*"}, (*Server).wikiGet},
+	{http.MethodGet, []string{"projects", "*", "wiki", "*", "*"}, (*Server).wikiGet},
+	{http.MethodPut, []string{"projects", "*", "wiki", "*"}, (*Server).wikiPut},
+	{http.MethodDelete, []string{"projects", "*", "wiki", "*"}, (*Server).wikiDelete},
+
+	// Attachments
+	{http.MethodPost, []string{"uploads"}, (*Server).upload},
+	{http.MethodGet, []string{"attachments", "*"}, (*Server).attachmentGet},
+
+	// Enumerations and other metadata
+	{http.MethodGet, []string{"enumerations", "issue_priorities"}, (*Server).prioritiesList},
+	{http.MethodGet, []string{"enumerations", "time_entry_activities"}, (*Server).activitiesList},
+	{http.MethodGet, []string{"enumerations", "document_categories"}, (*Server).documentCategoriesList},
+	{http.MethodGet, []string{"issue_statuses"}, (*Server).statusesList},
+	{http.MethodGet, []string{"trackers"}, (*Server).trackersList},
+	{http.MethodGet, []string{"custom_fields"}, (*Server).customFieldsList},
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+// writeErrors writes validation errors the way Redmine does
+func writeErrors(w http.ResponseWriter, errs ...string) {
+	writeJSON(w, http.StatusUnprocessableEntity, map[string]interface{}{
+		"errors": errs,
+	})
+}
+
+func writeStatus(w http.ResponseWriter, status int) {
+	w.WriteHeader(status)
+}
+
+func readJSON(r *http.Request, v interface{}) error {
+	return json.NewDecoder(r.Body).Decode(v)
+}
+
+// page returns items range for request `offset` and `limit` parameters
+// with Redmine defaults and limits applied
+func page(r *http.Request, total int) (int, int, int) {
+
+	q := r.URL.Query()
+
+	limit, err := strconv.Atoi(q.Get("limit"))
+	if err != nil || limit <= 0 {
+		limit = limitDefault
+	}
+	if limit > limitMax {
+		limit = limitMax
+	}
+
+	offset, err := strconv.Atoi(q.Get("offset"))
+	if err != nil || offset < 0 {
+		offset = 0
+	}
+
+	from := offset
+	if from > total {
+		from = total
+	}
+
+	to := from + limit
+	if to > total {
+		to = total
+	}
+
+	return from, to, limit
+}
+
+func paged(key string, items []interface{}, r *http.Request) map[string]interface{} {
+
+	from, to, limit := page(r, len(items))
+
+	offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+	if offset < 0 {
+		offset = 0
+	}
+
+	return map[string]interface{}{
+		key:           append([]interface{}{}, items[from:to]...),
+		"total_count": len(items),
+		"offset":      offset,
+		"limit":       limit,
+	}
+}
+
+func includes(r *http.Request) map[string]bool {
+
+	is := make(map[string]bool)
+
+	for _, i := range strings.Split(r.URL.Query().Get("include"), ",") {
+		if i = strings.TrimSpace(i); i != "" {
+			is[i] = true
+		}
+	}
+
+	return is
+}
+
+func parseID(s string) (int64, bool) {
+	id, err := strconv.ParseInt(s, 10, 64)
+	return id, err == nil
+}
+
+func timeFormat(t time.Time) string {
+	return t.UTC().Format(time.RFC3339)
+}
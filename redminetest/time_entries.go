@@ -0,0 +1,306 @@
+package redminetest
+
+import (
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+)
+
+type timeEntry struct {
+	id           int64
+	projectID    int64
+	issueID      int64
+	userID       int64
+	activityID   int64
+	hours        float64
+	comments     string
+	spentOn      string
+	customFields map[int64][]string
+	createdOn    time.Time
+	updatedOn    time.Time
+}
+
+type timeEntryIn struct {
+	ProjectID    *string          `json:"project_id"`
+	IssueID      *int64           `json:"issue_id"`
+	UserID       *int64           `json:"user_id"`
+	ActivityID   *int64           `json:"activity_id"`
+	Hours        *float64         `json:"hours"`
+	Comments     *string          `json:"comments"`
+	SpentOn      *string          `json:"spent_on"`
+	CustomFields *[]customFieldIn `json:"custom_fields"`
+}
+
+func (db *database) timeEntry(id string) *timeEntry {
+
+	n, b := parseID(id)
+	if b == false {
+		return nil
+	}
+
+	return db.timeEntries[n]
+}
+
+func (db *database) timeEntryRender(t *timeEntry) map[string]interface{} {
+
+	n, _ := db.principalName(t.userID)
+
+	o := map[string]interface{}{
+		"id":            t.id,
+		"project":       idName(t.projectID, db.projects[t.projectID].name),
+		"user":          idName(t.userID, n),
+		"activity":      idName(t.activityID, db.activity(t.activityID).name),
+		"hours":         t.hours,
+		"comments":      t.comments,
+		"spent_on":      t.spentOn,
+		"custom_fields": db.customFieldsRender("time_entry", t.customFields, 0),
+		"created_on":    timeFormat(t.createdOn),
+		"updated_on":    timeFormat(t.updatedOn),
+	}
+
+	if t.issueID != 0 {
+		o["issue"] = map[string]interface{}{
+			"id": t.issueID,
+		}
+	}
+
+	return o
+}
+
+// timeEntryMatch checks time entry matches all filters specified in request
+func (db *database) timeEntryMatch(t *timeEntry, r *http.Request) bool {
+
+	q := r.URL.Query()
+
+	if v := q.Get("project_id"); v != "" {
+		p := db.project(v)
+		if p == nil || db.projectSubtree(p.id)[t.projectID] == false {
+			return false
+		}
+	}
+
+	if v := q.Get("issue_id"); v != "" {
+
+		// `~ID` matches the issue and all its descendants
+		if id, b := strings.CutPrefix(v, "~"); b == true {
+			n, _ := parseID(id)
+			if t.issueID != n && slices.Contains(db.issueDescendants(n), t.issueID) == false {
+				return false
+			}
+		} else if filterID(v, t.issueID, db.currentUserID) == false {
+			return false
+		}
+	}
+
+	if filterID(q.Get("user_id"), t.userID, db.currentUserID) == false {
+		return false
+	}
+
+	if filterID(q.Get("activity_id"), t.activityID, db.currentUserID) == false {
+		return false
+	}
+
+	if v := q.Get("from"); v != "" && t.spentOn < v {
+		return false
+	}
+
+	if v := q.Get("to"); v != "" && t.spentOn > v {
+		return false
+	}
+
+	if filterTime(q.Get("spent_on"), dateParse(t.spentOn)) == false {
+		return false
+	}
+
+	return true
+}
+
+func (s *Server) timeEntriesList(w http.ResponseWriter, r *http.Request, _ []string) {
+
+	var items []interface{}
+
+	ids := sortedIDs(s.db.timeEntries)
+
+	// Redmine returns the most recent time entries first
+	for i := len(ids) - 1; i >= 0; i-- {
+		if t := s.db.timeEntries[ids[i]]; s.db.timeEntryMatch(t, r) == true {
+			items = append(items, s.db.timeEntryRender(t))
+		}
+	}
+
+	writeJSON(w, http.StatusOK, paged("time_entries", items, r))
+}
+
+func (s *Server) timeEntryGet(w http.ResponseWriter, r *http.Request, p []string) {
+
+	t := s.db.timeEntry(p[1])
+	if t == nil {
+		writeStatus(w, http.StatusNotFound)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"time_entry": s.db.timeEntryRender(t),
+	})
+}
+
+func (s *Server) timeEntryCreate(w http.ResponseWriter, r *http.Request, _ []string) {
+
+	var in struct {
+		TimeEntry timeEntryIn `json:"time_entry"`
+	}
+
+	if err := readJSON(r, &in); err != nil {
+		writeStatus(w, http.StatusBadRequest)
+		return
+	}
+
+	now := s.now()
+
+	t := &timeEntry{
+		userID:       s.db.currentUserID,
+		spentOn:      now.Format(dateFormat),
+		customFields: make(map[int64][]string),
+		createdOn:    now,
+		updatedOn:    now,
+	}
+
+	for _, a := range s.db.activities {
+		if a.isDefault == true {
+			t.activityID = a.id
+		}
+	}
+
+	if errs := s.db.timeEntryApply(t, in.TimeEntry); len(errs) > 0 {
+		writeErrors(w, errs...)
+		return
+	}
+
+	t.id = s.db.nextID()
+	s.db.timeEntries[t.id] = t
+
+	writeJSON(w, http.StatusCreated, map[string]interface{}{
+		"time_entry": s.db.timeEntryRender(t),
+	})
+}
+
+func (s *Server) timeEntryUpdate(w http.ResponseWriter, r *http.Request, p []string) {
+
+	var in struct {
+		TimeEntry timeEntryIn `json:"time_entry"`
+	}
+
+	t := s.db.timeEntry(p[1])
+	if t == nil {
+		writeStatus(w, http.StatusNotFound)
+		return
+	}
+
+	if err := readJSON(r, &in); err != nil {
+		writeStatus(w, http.StatusBadRequest)
+		return
+	}
+
+	upd := *t
+	upd.customFields = copyCustomFields(t.customFields)
+
+	if errs := s.db.timeEntryApply(&upd, in.TimeEntry); len(errs) > 0 {
+		writeErrors(w, errs...)
+		return
+	}
+
+	upd.updatedOn = s.now()
+	*t = upd
+
+	writeStatus(w, http.StatusNoContent)
+}
+
+func (s *Server) timeEntryDelete(w http.ResponseWriter, r *http.Request, p []string) {
+
+	t := s.db.timeEntry(p[1])
+	if t == nil {
+		writeStatus(w, http.StatusNotFound)
+		return
+	}
+
+	delete(s.db.timeEntries, t.id)
+
+	writeStatus(w, http.StatusNoContent)
+}
+
+// timeEntryApply applies incoming data to time entry and returns validation errors
+func (db *database) timeEntryApply(t *timeEntry, in timeEntryIn) []string {
+
+	var errs []string
+
+	if in.IssueID != nil {
+		if i, b := db.issues[*in.IssueID]; b == false {
+			errs = append(errs, "Issue is invalid")
+		} else {
+			t.issueID = i.id
+			t.projectID = i.projectID
+		}
+	}
+
+	if in.ProjectID != nil && in.IssueID == nil {
+		if p := db.project(*in.ProjectID); p == nil {
+			errs = append(errs, "Project is invalid")
+		} else {
+			t.projectID = p.id
+		}
+	}
+
+	if p, b := db.projects[t.projectID]; b == false {
+		errs = append(errs, "Project cannot be blank")
+	} else if p.status != projectStatusActive {
+		errs = append(errs, "Project is invalid")
+	}
+
+	if in.UserID != nil {
+		if _, b := db.users[*in.UserID]; b == false {
+			errs = append(errs, "User is invalid")
+		} else {
+			t.userID = *in.UserID
+		}
+	}
+
+	if in.ActivityID != nil {
+		t.activityID = *in.ActivityID
+	}
+	if t.activityID == 0 {
+		errs = append(errs, "Activity cannot be blank")
+	} else if db.activity(t.activityID) == nil {
+		errs = append(errs, "Activity is not included in the list")
+	}
+
+	if in.Hours != nil {
+		t.hours = *in.Hours
+	}
+	if t.hours <= 0 || t.hours >= 1000 {
+		errs = append(errs, "Hours is invalid")
+	}
+
+	if in.Comments != nil {
+		if len(*in.Comments) > 1024 {
+			errs = append(errs, "Comment is too long (maximum is 1024 characters)")
+		}
+		t.comments = *in.Comments
+	}
+
+	if in.SpentOn != nil {
+		if dateParse(*in.SpentOn).IsZero() == true {
+			errs = append(errs, "Date is not a valid date")
+		} else {
+			t.spentOn = *in.SpentOn
+		}
+	}
+
+	if in.CustomFields != nil {
+		errs = append(errs, db.customFieldsApply(t.customFields, "time_entry", 0, *in.CustomFields)...)
+	}
+
+	errs = append(errs, db.customFieldsRequired(t.customFields, "time_entry", 0)...)
+
+	return errs
+}
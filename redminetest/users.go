@@ -0,0 +1,432 @@
+package redminetest
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+	"net/mail"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	userStatusActive     = 1
+	userStatusRegistered = 2
+	userStatusLocked     = 3
+)
+
+type user struct {
+	id               int64
+	login            string
+	admin            bool
+	firstName        string
+	lastName         string
+	mail             string
+	status           int64
+	apiKey           string
+	password         string
+	authSourceID     int64
+	mailNotification string
+	mustChangePasswd bool
+	customFields     map[int64][]string
+	createdOn        time.Time
+	updatedOn        time.Time
+	passwdChangedOn  time.Time
+}
+
+type userIn struct {
+	Login            *string          `json:"login"`
+	FirstName        *string          `json:"firstname"`
+	LastName         *string          `json:"lastname"`
+	Mail             *string          `json:"mail"`
+	Password         *string          `json:"password"`
+	AuthSourceID     *int64           `json:"auth_source_id"`
+	MailNotification *string          `json:"mail_notification"`
+	MustChangePasswd *bool            `json:"must_change_passwd"`
+	GeneratePassword *bool            `json:"generate_password"`
+	Admin            *bool            `json:"admin"`
+	Status           *int64           `json:"status"`
+	CustomFields     *[]customFieldIn `json:"custom_fields"`
+}
+
+// UserAdd adds active user into fake server and returns its ID and API key
+// that can be used to make requests on behalf of the user
+func (s *Server) UserAdd(login, firstName, lastName, mail string) (int64, string) {
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+
+	u := &user{
+		id:               s.db.nextID(),
+		login:            login,
+		firstName:        firstName,
+		lastName:         lastName,
+		mail:             mail,
+		status:           userStatusActive,
+		apiKey:           apiKeyGenerate(),
+		mailNotification: "only_my_events",
+		customFields:     make(map[int64][]string),
+		createdOn:        now,
+		updatedOn:        now,
+	}
+
+	s.db.users[u.id] = u
+
+	return u.id, u.apiKey
+}
+
+func (u *user) name() string {
+	return strings.TrimSpace(u.firstName + " " + u.lastName)
+}
+
+func (db *database) user(id string) *user {
+
+	if id == "current" {
+		return db.users[db.currentUserID]
+	}
+
+	n, b := parseID(id)
+	if b == false {
+		return nil
+	}
+
+	return db.users[n]
+}
+
+func (db *database) userRender(u *user, is map[string]bool, single bool) map[string]interface{} {
+
+	o := map[string]interface{}{
+		"id":                u.id,
+		"login":             u.login,
+		"admin":             u.admin,
+		"firstname":         u.firstName,
+		"lastname":          u.lastName,
+		"mail":              u.mail,
+		"created_on":        timeFormat(u.createdOn),
+		"updated_on":        timeFormat(u.updatedOn),
+		"last_login_on":     nil,
+		"passwd_changed_on": nil,
+		"twofa_scheme":      nil,
+		"custom_fields":     db.customFieldsRender("user", u.customFields, 0),
+	}
+
+	if u.passwdChangedOn.IsZero() == false {
+		o["passwd_changed_on"] = timeFormat(u.passwdChangedOn)
+	}
+
+	// Admins see status of users in lists as well
+	if single == true || db.users[db.currentUserID].admin == true {
+		o["status"] = u.status
+	}
+
+	if single == true && (u.id == db.currentUserID || db.users[db.currentUserID].admin == true) {
+		o["api_key"] = u.apiKey
+	}
+
+	if single == false {
+		return o
+	}
+
+	if is["groups"] == true {
+		gs := []interface{}{}
+		for _, gid := range sortedIDs(db.groups) {
+			g := db.groups[gid]
+			if g.member(u.id) == true {
+				gs = append(gs, idName(g.id, g.name))
+			}
+		}
+		o["groups"] = gs
+	}
+
+	if is["memberships"] == true {
+		o["memberships"] = db.principalMembershipsRender(u.id)
+	}
+
+	return o
+}
+
+func (s *Server) usersList(w http.ResponseWriter, r *http.Request, _ []string) {
+
+	var items []interface{}
+
+	q := r.URL.Query()
+
+	status := strconv.Itoa(userStatusActive)
+	if v, b := q["status"]; b == true && len(v) > 0 {
+		status = v[0]
+	}
+
+	for _, id := range sortedIDs(s.db.users) {
+
+		u := s.db.users[id]
+
+		if status != "" && status != strconv.FormatInt(u.status, 10) {
+			continue
+		}
+
+		if n := strings.ToLower(q.Get("name")); n != "" {
+			if strings.Contains(strings.ToLower(u.login), n) == false &&
+				strings.Contains(strings.ToLower(u.firstName), n) == false &&
+				strings.Contains(strings.ToLower(u.lastName), n) == false &&
+				strings.Contains(strings.ToLower(u.mail), n) == false &&
+				strings.Contains(strings.ToLower(u.name()), n) == false {
+				continue
+			}
+		}
+
+		if v := q.Get("group_id"); v != "" {
+			gid, _ := parseID(v)
+			if g, b := s.db.groups[gid]; b == false || g.member(u.id) == false {
+				continue
+			}
+		}
+
+		items = append(items, s.db.userRender(u, nil, false))
+	}
+
+	writeJSON(w, http.StatusOK, paged("users", items, r))
+}
+
+func (s *Server) userGet(w http.ResponseWriter, r *http.Request, p []string) {
+
+	u := s.db.user(p[1])
+	if u == nil {
+		writeStatus(w, http.StatusNotFound)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"user": s.db.userRender(u, includes(r), true),
+	})
+}
+
+func (s *Server) userCreate(w http.ResponseWriter, r *http.Request, _ []string) {
+
+	var in struct {
+		User userIn `json:"user"`
+	}
+
+	if s.db.users[s.db.currentUserID].admin == false {
+		writeStatus(w, http.StatusForbidden)
+		return
+	}
+
+	if err := readJSON(r, &in); err != nil {
+		writeStatus(w, http.StatusBadRequest)
+		return
+	}
+
+	now := s.now()
+
+	u := &user{
+		status:           userStatusActive,
+		apiKey:           apiKeyGenerate(),
+		mailNotification: "only_my_events",
+		customFields:     make(map[int64][]string),
+		createdOn:        now,
+		updatedOn:        now,
+	}
+
+	errs := s.db.userApply(u, in.User)
+
+	if u.password == "" && u.authSourceID == 0 && (in.User.GeneratePassword == nil || *in.User.GeneratePassword == false) {
+		errs = append(errs, "Password is too short (minimum is 8 characters)")
+	}
+
+	if len(errs) > 0 {
+		writeErrors(w, errs...)
+		return
+	}
+
+	u.id = s.db.nextID()
+	s.db.users[u.id] = u
+
+	writeJSON(w, http.StatusCreated, map[string]interface{}{
+		"user": s.db.userRender(u, nil, true),
+	})
+}
+
+func (s *Server) userUpdate(w http.ResponseWriter, r *http.Request, p []string) {
+
+	var in struct {
+		User userIn `json:"user"`
+	}
+
+	u := s.db.user(p[1])
+	if u == nil {
+		writeStatus(w, http.StatusNotFound)
+		return
+	}
+
+	if s.db.users[s.db.currentUserID].admin == false {
+		writeStatus(w, http.StatusForbidden)
+		return
+	}
+
+	if err := readJSON(r, &in); err != nil {
+		writeStatus(w, http.StatusBadRequest)
+		return
+	}
+
+	upd := *u
+	upd.customFields = copyCustomFields(u.customFields)
+
+	if errs := s.db.userApply(&upd, in.User); len(errs) > 0 {
+		writeErrors(w, errs...)
+		return
+	}
+
+	upd.updatedOn = s.now()
+	*u = upd
+
+	writeStatus(w, http.StatusNoContent)
+}
+
+func (s *Server) userDelete(w http.ResponseWriter, r *http.Request, p []string) {
+
+	u := s.db.user(p[1])
+	if u == nil {
+		writeStatus(w, http.StatusNotFound)
+		return
+	}
+
+	if s.db.users[s.db.currentUserID].admin == false {
+		writeStatus(w, http.StatusForbidden)
+		return
+	}
+
+	for id, m := range s.db.memberships {
+		if m.principalID == u.id {
+			delete(s.db.memberships, id)
+		}
+	}
+
+	for _, g := range s.db.groups {
+		g.userDelete(u.id)
+	}
+
+	for _, i := range s.db.issues {
+		if i.assignedToID == u.id {
+			i.assignedToID = 0
+		}
+	}
+
+	delete(s.db.users, u.id)
+
+	writeStatus(w, http.StatusNoContent)
+}
+
+// userApply applies incoming data to user and returns validation errors
+func (db *database) userApply(u *user, in userIn) []string {
+
+	var errs []string
+
+	if in.Login != nil {
+		u.login = *in.Login
+	}
+	switch {
+	case u.login == "":
+		errs = append(errs, "Login cannot be blank")
+	case func() bool {
+		for _, e := range db.users {
+			if e.id != u.id && strings.EqualFold(e.login, u.login) == true {
+				return true
+			}
+		}
+		return false
+	}() == true:
+		errs = append(errs, "Login has already been taken")
+	}
+
+	if in.FirstName != nil {
+		u.firstName = *in.FirstName
+	}
+	if u.firstName == "" {
+		errs = append(errs, "First name cannot be blank")
+	}
+
+	if in.LastName != nil {
+		u.lastName = *in.LastName
+	}
+	if u.lastName == "" {
+		errs = append(errs, "Last name cannot be blank")
+	}
+
+	if in.Mail != nil {
+		u.mail = *in.Mail
+	}
+	switch {
+	case u.mail == "":
+		errs = append(errs, "Email cannot be blank")
+	case func() bool {
+		_, err := mail.ParseAddress(u.mail)
+		return err != nil
+	}() == true:
+		errs = append(errs, "Email is invalid")
+	case func() bool {
+		for _, e := range db.users {
+			if e.id != u.id && strings.EqualFold(e.mail, u.mail) == true {
+				return true
+			}
+		}
+		return false
+	}() == true:
+		errs = append(errs, "Email has already been taken")
+	}
+
+	if in.Password != nil {
+		if len(*in.Password) < 8 {
+			errs = append(errs, "Password is too short (minimum is 8 characters)")
+		} else {
+			u.password = *in.Password
+			u.passwdChangedOn = time.Now()
+		}
+	}
+
+	if in.GeneratePassword != nil && *in.GeneratePassword == true {
+		u.password = apiKeyGenerate()
+		u.passwdChangedOn = time.Now()
+	}
+
+	if in.AuthSourceID != nil {
+		u.authSourceID = *in.AuthSourceID
+	}
+
+	if in.MailNotification != nil {
+		u.mailNotification = *in.MailNotification
+	}
+
+	if in.MustChangePasswd != nil {
+		u.mustChangePasswd = *in.MustChangePasswd
+	}
+
+	if in.Admin != nil {
+		u.admin = *in.Admin
+	}
+
+	if in.Status != nil {
+		switch *in.Status {
+		case userStatusActive, userStatusRegistered, userStatusLocked:
+			u.status = *in.Status
+		default:
+			errs = append(errs, "Status is not included in the list")
+		}
+	}
+
+	if in.CustomFields != nil {
+		errs = append(errs, db.customFieldsApply(u.customFields, "user", 0, *in.CustomFields)...)
+	}
+
+	errs = append(errs, db.customFieldsRequired(u.customFields, "user", 0)...)
+
+	return errs
+}
+
+func apiKeyGenerate() string {
+	b := make([]byte, 20)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,302 @@
+package redminetest
+
+import (
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+)
+
+var (
+	versionStatuses = []string{"open", "locked", "closed"}
+	versionSharings = []string{"none", "descendants", "hierarchy", "tree", "system"}
+)
+
+type version struct {
+	id            int64
+	projectID     int64
+	name          string
+	description   string
+	status        string
+	dueDate       string
+	sharing       string
+	wikiPageTitle string
+	customFields  map[int64][]string
+	createdOn     time.Time
+	updatedOn     time.Time
+}
+
+type versionIn struct {
+	Name          *string          `json:"name"`
+	Description   *string          `json:"description"`
+	Status        *string          `json:"status"`
+	DueDate       *string          `json:"due_date"`
+	Sharing       *string          `json:"sharing"`
+	WikiPageTitle *string          `json:"wiki_page_title"`
+	CustomFields  *[]customFieldIn `json:"custom_fields"`
+}
+
+func (db *database) version(id string) *version {
+
+	n, b := parseID(id)
+	if b == false {
+		return nil
+	}
+
+	return db.versions[n]
+}
+
+// versionShared checks version is available for project according to its sharing
+func (db *database) versionShared(v *version, projectID int64) bool {
+
+	if v.projectID == projectID {
+		return true
+	}
+
+	switch v.sharing {
+	case "system":
+		return true
+	case "descendants":
+		return db.projectSubtree(v.projectID)[projectID]
+	case "hierarchy":
+		return db.projectSubtree(v.projectID)[projectID] || db.projectSubtree(projectID)[v.projectID]
+	case "tree":
+		return db.projectRoot(v.projectID) == db.projectRoot(projectID)
+	}
+
+	return false
+}
+
+// projectRoot returns ID of the root project of project hierarchy
+func (db *database) projectRoot(id int64) int64 {
+
+	for {
+		p, b := db.projects[id]
+		if b == false || p.parentID == 0 {
+			return id
+		}
+		id = p.parentID
+	}
+}
+
+func (db *database) versionRender(v *version) map[string]interface{} {
+
+	var spent, estimated float64
+
+	for _, i := range db.issues {
+		if i.fixedVersionID == v.id {
+			spent += db.issueSpentHours(i.id)
+			if i.estimatedHours != nil {
+				estimated += *i.estimatedHours
+			}
+		}
+	}
+
+	return map[string]interface{}{
+		"id":              v.id,
+		"project":         idName(v.projectID, db.projects[v.projectID].name),
+		"name":            v.name,
+		"description":     v.description,
+		"status":          v.status,
+		"due_date":        nullString(v.dueDate),
+		"sharing":         v.sharing,
+		"wiki_page_title": v.wikiPageTitle,
+		"estimated_hours": estimated,
+		"spent_hours":     spent,
+		"custom_fields":   db.customFieldsRender("version", v.customFields, 0),
+		"created_on":      timeFormat(v.createdOn),
+		"updated_on":      timeFormat(v.updatedOn),
+	}
+}
+
+func (s *Server) versionsList(w http.ResponseWriter, r *http.Request, p []string) {
+
+	pr := s.db.project(p[1])
+	if pr == nil {
+		writeStatus(w, http.StatusNotFound)
+		return
+	}
+
+	items := []interface{}{}
+
+	for _, id := range sortedIDs(s.db.versions) {
+		if v := s.db.versions[id]; s.db.versionShared(v, pr.id) == true {
+			items = append(items, s.db.versionRender(v))
+		}
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"versions":    items,
+		"total_count": len(items),
+	})
+}
+
+func (s *Server) versionGet(w http.ResponseWriter, r *http.Request, p []string) {
+
+	v := s.db.version(p[1])
+	if v == nil {
+		writeStatus(w, http.StatusNotFound)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"version": s.db.versionRender(v),
+	})
+}
+
+func (s *Server) versionCreate(w http.ResponseWriter, r *http.Request, p []string) {
+
+	var in struct {
+		Version versionIn `json:"version"`
+	}
+
+	pr := s.db.project(p[1])
+	if pr == nil {
+		writeStatus(w, http.StatusNotFound)
+		return
+	}
+
+	if err := readJSON(r, &in); err != nil {
+		writeStatus(w, http.StatusBadRequest)
+		return
+	}
+
+	now := s.now()
+
+	v := &version{
+		projectID:    pr.id,
+		status:       "open",
+		sharing:      "none",
+		customFields: make(map[int64][]string),
+		createdOn:    now,
+		updatedOn:    now,
+	}
+
+	if errs := s.db.versionApply(v, in.Version); len(errs) > 0 {
+		writeErrors(w, errs...)
+		return
+	}
+
+	v.id = s.db.nextID()
+	s.db.versions[v.id] = v
+
+	writeJSON(w, http.StatusCreated, map[string]interface{}{
+		"version": s.db.versionRender(v),
+	})
+}
+
+func (s *Server) versionUpdate(w http.ResponseWriter, r *http.Request, p []string) {
+
+	var in struct {
+		Version versionIn `json:"version"`
+	}
+
+	v := s.db.version(p[1])
+	if v == nil {
+		writeStatus(w, http.StatusNotFound)
+		return
+	}
+
+	if err := readJSON(r, &in); err != nil {
+		writeStatus(w, http.StatusBadRequest)
+		return
+	}
+
+	upd := *v
+	upd.customFields = copyCustomFields(v.customFields)
+
+	if errs := s.db.versionApply(&upd, in.Version); len(errs) > 0 {
+		writeErrors(w, errs...)
+		return
+	}
+
+	upd.updatedOn = s.now()
+	*v = upd
+
+	writeStatus(w, http.StatusNoContent)
+}
+
+func (s *Server) versionDelete(w http.ResponseWriter, r *http.Request, p []string) {
+
+	v := s.db.version(p[1])
+	if v == nil {
+		writeStatus(w, http.StatusNotFound)
+		return
+	}
+
+	for _, i := range s.db.issues {
+		if i.fixedVersionID == v.id {
+			writeErrors(w, "Unable to delete version")
+			return
+		}
+	}
+
+	delete(s.db.versions, v.id)
+
+	for _, pr := range s.db.projects {
+		if pr.defaultVersionID == v.id {
+			pr.defaultVersionID = 0
+		}
+	}
+
+	writeStatus(w, http.StatusNoContent)
+}
+
+// versionApply applies incoming data to version and returns validation errors
+func (db *database) versionApply(v *version, in versionIn) []string {
+
+	var errs []string
+
+	if in.Name != nil {
+		v.name = *in.Name
+	}
+
+	if v.name == "" {
+		errs = append(errs, "Name cannot be blank")
+	} else {
+		for _, e := range db.versions {
+			if e.id != v.id && e.projectID == v.projectID && strings.EqualFold(e.name, v.name) == true {
+				errs = append(errs, "Name has already been taken")
+				break
+			}
+		}
+	}
+
+	if in.Description != nil {
+		v.description = *in.Description
+	}
+
+	if in.Status != nil {
+		if slices.Contains(versionStatuses, *in.Status) == false {
+			errs = append(errs, "Status is not included in the list")
+		} else {
+			v.status = *in.Status
+		}
+	}
+
+	if in.DueDate != nil {
+		if *in.DueDate != "" && dateParse(*in.DueDate).IsZero() == true {
+			errs = append(errs, "Due date is not a valid date")
+		} else {
+			v.dueDate = *in.DueDate
+		}
+	}
+
+	if in.Sharing != nil {
+		if slices.Contains(versionSharings, *in.Sharing) == false {
+			errs = append(errs, "Sharing is not included in the list")
+		} else {
+			v.sharing = *in.Sharing
+		}
+	}
+
+	if in.WikiPageTitle != nil {
+		v.wikiPageTitle = *in.WikiPageTitle
+	}
+
+	if in.CustomFields != nil {
+		errs = append(errs, db.customFieldsApply(v.customFields, "version", 0, *in.CustomFields)...)
+	}
+
+	return errs
+}
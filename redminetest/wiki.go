@@ -0,0 +1,259 @@
+package redminetest
+
+import (
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"time"
+)
+
+type wikiPage struct {
+	title       string
+	parentTitle string
+	versions    []wikiVersion
+	attachments []int64
+	createdOn   time.Time
+}
+
+type wikiVersion struct {
+	text      string
+	comments  string
+	authorID  int64
+	updatedOn time.Time
+}
+
+type wikiPageIn struct {
+	Text        *string     `json:"text"`
+	Comments    *string     `json:"comments"`
+	Version     *int64      `json:"version"`
+	ParentTitle *string     `json:"parent_title"`
+	Uploads     *[]uploadIn `json:"uploads"`
+}
+
+func (wp *wikiPage) parentRender() interface{} {
+
+	if wp.parentTitle == "" {
+		return nil
+	}
+
+	return map[string]interface{}{
+		"title": wp.parentTitle,
+	}
+}
+
+func (db *database) wikiPageRender(wp *wikiPage, version int, is map[string]bool) map[string]interface{} {
+
+	v := wp.versions[version-1]
+
+	n, _ := db.principalName(v.authorID)
+
+	o := map[string]interface{}{
+		"title":      wp.title,
+		"parent":     wp.parentRender(),
+		"text":       v.text,
+		"version":    version,
+		"author":     idName(v.authorID, n),
+		"comments":   v.comments,
+		"created_on": timeFormat(wp.createdOn),
+		"updated_on": timeFormat(v.updatedOn),
+	}
+
+	if is["attachments"] == true {
+		o["attachments"] = db.attachmentsRender(wp.attachments)
+	}
+
+	return o
+}
+
+// wikiProject looks up project with enabled wiki module
+func (db *database) wikiProject(id string) *project {
+
+	p := db.project(id)
+	if p == nil || p.status == projectStatusArchived {
+		return nil
+	}
+
+	for _, m := range p.modules {
+		if m == "wiki" {
+			return p
+		}
+	}
+
+	return nil
+}
+
+func (s *Server) wikiList(w http.ResponseWriter, r *http.Request, p []string) {
+
+	pr := s.db.wikiProject(p[1])
+	if pr == nil {
+		writeStatus(w, http.StatusNotFound)
+		return
+	}
+
+	var titles []string
+	for t := range pr.wiki {
+		titles = append(titles, t)
+	}
+	sort.Strings(titles)
+
+	ws := []interface{}{}
+	for _, t := range titles {
+		wp := pr.wiki[t]
+		ws = append(ws, map[string]interface{}{
+			"title":      wp.title,
+			"parent":     wp.parentRender(),
+			"version":    len(wp.versions),
+			"created_on": timeFormat(wp.createdOn),
+			"updated_on": timeFormat(wp.versions[len(wp.versions)-1].updatedOn),
+		})
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"wiki_pages": ws,
+	})
+}
+
+func (s *Server) wikiGet(w http.ResponseWriter, r *http.Request, p []string) {
+
+	pr := s.db.wikiProject(p[1])
+	if pr == nil {
+		writeStatus(w, http.StatusNotFound)
+		return
+	}
+
+	wp := pr.wiki[wikiTitle(p[3])]
+	if wp == nil {
+		writeStatus(w, http.StatusNotFound)
+		return
+	}
+
+	version := len(wp.versions)
+
+	if len(p) == 5 {
+		v, err := strconv.Atoi(p[4])
+		if err != nil || v < 1 || v > len(wp.versions) {
+			writeStatus(w, http.StatusNotFound)
+			return
+		}
+		version = v
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"wiki_page": s.db.wikiPageRender(wp, version, includes(r)),
+	})
+}
+
+func (s *Server) wikiPut(w http.ResponseWriter, r *http.Request, p []string) {
+
+	var in struct {
+		WikiPage wikiPageIn `json:"wiki_page"`
+	}
+
+	pr := s.db.wikiProject(p[1])
+	if pr == nil {
+		writeStatus(w, http.StatusNotFound)
+		return
+	}
+
+	if err := readJSON(r, &in); err != nil {
+		writeStatus(w, http.StatusBadRequest)
+		return
+	}
+
+	title := wikiTitle(p[3])
+	now := s.now()
+
+	if in.WikiPage.Text == nil || *in.WikiPage.Text == "" {
+		writeErrors(w, "Text cannot be blank")
+		return
+	}
+
+	v := wikiVersion{
+		text:      *in.WikiPage.Text,
+		authorID:  s.db.currentUserID,
+		updatedOn: now,
+	}
+
+	if in.WikiPage.Comments != nil {
+		v.comments = *in.WikiPage.Comments
+	}
+
+	wp, exists := pr.wiki[title]
+
+	if exists == false {
+		wp = &wikiPage{
+			title:     title,
+			createdOn: now,
+		}
+	} else if in.WikiPage.Version != nil && *in.WikiPage.Version != int64(len(wp.versions)) {
+		// Page has been updated since specified version
+		writeStatus(w, http.StatusConflict)
+		return
+	}
+
+	if in.WikiPage.ParentTitle != nil {
+		if *in.WikiPage.ParentTitle != "" && pr.wiki[*in.WikiPage.ParentTitle] == nil {
+			writeErrors(w, "Parent page is invalid")
+			return
+		}
+		wp.parentTitle = *in.WikiPage.ParentTitle
+	}
+
+	if in.WikiPage.Uploads != nil {
+		wp.attachments = append(wp.attachments, s.db.uploadsAttach(*in.WikiPage.Uploads)...)
+	}
+
+	// Redmine doesn't create new version if content is not changed
+	if exists == true && wp.versions[len(wp.versions)-1].text == v.text {
+		writeStatus(w, http.StatusNoContent)
+		return
+	}
+
+	wp.versions = append(wp.versions, v)
+
+	if exists == true {
+		writeStatus(w, http.StatusNoContent)
+		return
+	}
+
+	pr.wiki[title] = wp
+
+	writeJSON(w, http.StatusCreated, map[string]interface{}{
+		"wiki_page": s.db.wikiPageRender(wp, len(wp.versions), nil),
+	})
+}
+
+func (s *Server) wikiDelete(w http.ResponseWriter, r *http.Request, p []string) {
+
+	pr := s.db.wikiProject(p[1])
+	if pr == nil {
+		writeStatus(w, http.StatusNotFound)
+		return
+	}
+
+	title := wikiTitle(p[3])
+
+	if pr.wiki[title] == nil {
+		writeStatus(w, http.StatusNotFound)
+		return
+	}
+
+	delete(pr.wiki, title)
+
+	// Children of deleted page become root pages
+	for _, wp := range pr.wiki {
+		if wp.parentTitle == title {
+			wp.parentTitle = ""
+		}
+	}
+
+	writeStatus(w, http.StatusNoContent)
+}
+
+func wikiTitle(s string) string {
+	if t, err := url.PathUnescape(s); err == nil {
+		return t
+	}
+	return s
+}
@@ -0,0 +1,292 @@
+package redmine
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Resolver converts user input (names, logins, emails and project identifiers)
+// into IDs and back. Users and projects lists are cached with the same TTL as
+// context metadata, trackers, statuses, priorities and activities are got from
+// context metadata (see `Context.Metadata`). Resolver is safe for concurrent use
+type Resolver struct {
+	r     *Context
+	cache *listCache
+
+	users    metadataList[UserObject]
+	projects metadataList[ProjectObject]
+}
+
+// ResolveError is returned when value can't be resolved into single ID
+type ResolveError struct {
+	Kind    string   // Kind of resolved object, e.g. `user` or `project`
+	Value   string   // Value to resolve
+	Matches []IDName // Matched objects if value is ambiguous
+}
+
+// ResolverInit creates new resolver
+func ResolverInit(r *Context) *Resolver {
+	return &Resolver{
+		r:     r,
+		cache: listCacheInit(r.Metadata().cache.ttl),
+	}
+}
+
+func (e ResolveError) Error() string {
+
+	if len(e.Matches) == 0 {
+		return fmt.Sprintf("%s %q not found", e.Kind, e.Value)
+	}
+
+	var ms []string
+	for _, m := range e.Matches {
+		ms = append(ms, fmt.Sprintf("%d (%s)", m.ID, m.Name))
+	}
+
+	return fmt.Sprintf("%s %q is ambiguous, matches: %s", e.Kind, e.Value, strings.Join(ms, ", "))
+}
+
+// Invalidate drops cached users and projects lists
+func (rs *Resolver) Invalidate() {
+
+	rs.cache.mu.Lock()
+	defer rs.cache.mu.Unlock()
+
+	rs.users = metadataList[UserObject]{}
+	rs.projects = metadataList[ProjectObject]{}
+}
+
+// UserID resolves user by ID, login, email or full name (`Firstname Lastname`).
+// Value `me` is resolved into current user. Only active users are looked up.
+// Administrator privileges are required to get users list
+func (rs *Resolver) UserID(v string) (int64, error) {
+
+	if id, err := strconv.ParseInt(v, 10, 64); err == nil {
+		return id, nil
+	}
+
+	if v == "me" {
+		u, _, err := rs.r.UserCurrentGet(UserCurrentGetRequest{})
+		if err != nil {
+			return 0, err
+		}
+		return u.ID, nil
+	}
+
+	us, err := rs.usersGet()
+	if err != nil {
+		return 0, err
+	}
+
+	return resolveMatch("user", v, us,
+		func(u UserObject) IDName {
+			return IDName{ID: u.ID, Name: u.Login}
+		},
+		func(u UserObject) bool {
+			return strings.EqualFold(u.Login, v)
+		},
+		func(u UserObject) bool {
+			return strings.EqualFold(u.Mail, v) || strings.EqualFold(u.FirstName+" "+u.LastName, v)
+		},
+	)
+}
+
+// UserLogin resolves user ID into login
+func (rs *Resolver) UserLogin(id int64) (string, error) {
+
+	us, err := rs.usersGet()
+	if err != nil {
+		return "", err
+	}
+
+	for _, u := range us {
+		if u.ID == id {
+			return u.Login, nil
+		}
+	}
+
+	// User may be inactive, so get it directly
+	u, _, err := rs.r.UserSingleGet(id, UserSingleGetRequest{})
+	if err != nil {
+		return "", err
+	}
+
+	return u.Login, nil
+}
+
+// ProjectID resolves project by ID, identifier or name
+func (rs *Resolver) ProjectID(v string) (int64, error) {
+
+	if id, err := strconv.ParseInt(v, 10, 64); err == nil {
+		return id, nil
+	}
+
+	ps, err := rs.projectsGet()
+	if err != nil {
+		return 0, err
+	}
+
+	return resolveMatch("project", v, ps,
+		func(p ProjectObject) IDName {
+			return IDName{ID: p.ID, Name: p.Identifier}
+		},
+		func(p ProjectObject) bool {
+			return p.Identifier == v
+		},
+		func(p ProjectObject) bool {
+			return strings.EqualFold(p.Name, v)
+		},
+	)
+}
+
+// ProjectIdentifier resolves project ID into identifier
+func (rs *Resolver) ProjectIdentifier(id int64) (string, error) {
+
+	ps, err := rs.projectsGet()
+	if err != nil {
+		return "", err
+	}
+
+	for _, p := range ps {
+		if p.ID == id {
+			return p.Identifier, nil
+		}
+	}
+
+	return "", ResolveError{Kind: "project", Value: strconv.FormatInt(id, 10)}
+}
+
+// TrackerID resolves tracker by ID or name
+func (rs *Resolver) TrackerID(v string) (int64, error) {
+	return resolveName("tracker", v, rs.r.Metadata().Trackers, func(e TrackerObject) IDName {
+		return IDName{ID: e.ID, Name: e.Name}
+	})
+}
+
+// TrackerName resolves tracker ID into name
+func (rs *Resolver) TrackerName(id int64) (string, error) {
+	return resolveID("tracker", id, rs.r.Metadata().Trackers, func(e TrackerObject) IDName {
+		return IDName{ID: e.ID, Name: e.Name}
+	})
+}
+
+// IssueStatusID resolves issue status by ID or name
+func (rs *Resolver) IssueStatusID(v string) (int64, error) {
+	return resolveName("issue status", v, rs.r.Metadata().IssueStatuses, func(e IssueStatusObject) IDName {
+		return IDName{ID: e.ID, Name: e.Name}
+	})
+}
+
+// IssueStatusName resolves issue status ID into name
+func (rs *Resolver) IssueStatusName(id int64) (string, error) {
+	return resolveID("issue status", id, rs.r.Metadata().IssueStatuses, func(e IssueStatusObject) IDName {
+		return IDName{ID: e.ID, Name: e.Name}
+	})
+}
+
+// PriorityID resolves issue priority by ID or name
+func (rs *Resolver) PriorityID(v string) (int64, error) {
+	return resolveName("priority", v, rs.r.Metadata().Priorities, func(e EnumerationPriorityObject) IDName {
+		return IDName{ID: e.ID, Name: e.Name}
+	})
+}
+
+// PriorityName resolves issue priority ID into name
+func (rs *Resolver) PriorityName(id int64) (string, error) {
+	return resolveID("priority", id, rs.r.Metadata().Priorities, func(e EnumerationPriorityObject) IDName {
+		return IDName{ID: e.ID, Name: e.Name}
+	})
+}
+
+// TimeEntryActivityID resolves time entry activity by ID or name
+func (rs *Resolver) TimeEntryActivityID(v string) (int64, error) {
+	return resolveName("time entry activity", v, rs.r.Metadata().TimeEntryActivities, func(e EnumerationTimeEntryActivityObject) IDName {
+		return IDName{ID: e.ID, Name: e.Name}
+	})
+}
+
+// TimeEntryActivityName resolves time entry activity ID into name
+func (rs *Resolver) TimeEntryActivityName(id int64) (string, error) {
+	return resolveID("time entry activity", id, rs.r.Metadata().TimeEntryActivities, func(e EnumerationTimeEntryActivityObject) IDName {
+		return IDName{ID: e.ID, Name: e.Name}
+	})
+}
+
+func (rs *Resolver) usersGet() ([]UserObject, error) {
+	return metadataGet(rs.cache, &rs.users, func() ([]UserObject, StatusCode, error) {
+		u, s, err := rs.r.UserAllGet(UserAllGetRequest{})
+		return u.Users, s, err
+	})
+}
+
+func (rs *Resolver) projectsGet() ([]ProjectObject, error) {
+	return metadataGet(rs.cache, &rs.projects, func() ([]ProjectObject, StatusCode, error) {
+		p, s, err := rs.r.ProjectAllGet(ProjectAllGetRequest{})
+		return p.Projects, s, err
+	})
+}
+
+// resolveMatch looks up single element matching value. Matchers are applied
+// in order, the first matcher with any matched elements is used
+func resolveMatch[T any](kind, v string, items []T, idName func(T) IDName, matchers ...func(T) bool) (int64, error) {
+
+	for _, match := range matchers {
+
+		var ms []IDName
+
+		for _, e := range items {
+			if match(e) == true {
+				ms = append(ms, idName(e))
+			}
+		}
+
+		switch len(ms) {
+		case 0:
+			continue
+		case 1:
+			return ms[0].ID, nil
+		default:
+			return 0, ResolveError{Kind: kind, Value: v, Matches: ms}
+		}
+	}
+
+	return 0, ResolveError{Kind: kind, Value: v}
+}
+
+func resolveName[T any](kind, v string, get func() ([]T, error), idName func(T) IDName) (int64, error) {
+
+	if id, err := strconv.ParseInt(v, 10, 64); err == nil {
+		return id, nil
+	}
+
+	items, err := get()
+	if err != nil {
+		return 0, err
+	}
+
+	return resolveMatch(kind, v, items, idName,
+		func(e T) bool {
+			return idName(e).Name == v
+		},
+		func(e T) bool {
+			return strings.EqualFold(idName(e).Name, v)
+		},
+	)
+}
+
+func resolveID[T any](kind string, id int64, get func() ([]T, error), idName func(T) IDName) (string, error) {
+
+	items, err := get()
+	if err != nil {
+		return "", err
+	}
+
+	for _, e := range items {
+		if n := idName(e); n.ID == id {
+			return n.Name, nil
+		}
+	}
+
+	return "", ResolveError{Kind: kind, Value: strconv.FormatInt(id, 10)}
+}
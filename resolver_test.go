@@ -0,0 +1,93 @@
+package redmine
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestResolver(t *testing.T) {
+
+	var userHits int
+
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("/users.json", func(w http.ResponseWriter, req *http.Request) {
+		userHits++
+		fmt.Fprint(w, `{"users":[
+			{"id":3,"login":"jdoe","firstname":"John","lastname":"Doe","mail":"jdoe@example.com"},
+			{"id":4,"login":"jsmith","firstname":"John","lastname":"Smith","mail":"js@example.com"},
+			{"id":5,"login":"jdoe2","firstname":"John","lastname":"Doe","mail":"jdoe2@example.com"}
+		],"total_count":3,"offset":0,"limit":25}`)
+	})
+
+	mux.HandleFunc("/users/current.json", func(w http.ResponseWriter, req *http.Request) {
+		fmt.Fprint(w, `{"user":{"id":1,"login":"admin"}}`)
+	})
+
+	mux.HandleFunc("/projects.json", func(w http.ResponseWriter, req *http.Request) {
+		fmt.Fprint(w, `{"projects":[
+			{"id":1,"name":"Web","identifier":"web"},
+			{"id":2,"name":"Support","identifier":"web-support"},
+			{"id":3,"name":"Support","identifier":"app-support"}
+		],"total_count":3,"offset":0,"limit":25}`)
+	})
+
+	mux.HandleFunc("/trackers.json", func(w http.ResponseWriter, req *http.Request) {
+		fmt.Fprint(w, `{"trackers":[{"id":1,"name":"Bug"},{"id":2,"name":"Feature"}]}`)
+	})
+
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	rs := ResolverInit(Init(Settings{Endpoint: srv.URL}))
+
+	for v, e := range map[string]int64{
+		"jdoe":              3,
+		"JSmith":            4,
+		"jdoe2@example.com": 5,
+		"John Smith":        4,
+		"me":                1,
+		"42":                42,
+	} {
+		if id, err := rs.UserID(v); err != nil || id != e {
+			t.Fatalf("Resolve error: user %q resolved into %d (%v), expected %d", v, id, err, e)
+		}
+	}
+
+	var re ResolveError
+
+	if _, err := rs.UserID("John Doe"); errors.As(err, &re) == false || len(re.Matches) != 2 {
+		t.Fatal("Resolve error: expected ambiguity error, got:", err)
+	}
+
+	if _, err := rs.UserID("nobody"); errors.As(err, &re) == false || len(re.Matches) != 0 {
+		t.Fatal("Resolve error: expected not found error, got:", err)
+	}
+
+	if l, err := rs.UserLogin(4); err != nil || l != "jsmith" {
+		t.Fatal("Resolve error: unexpected login:", l, err)
+	}
+
+	if userHits != 1 {
+		t.Fatalf("Resolve error: users list requested %d times", userHits)
+	}
+
+	if id, err := rs.ProjectID("web-support"); err != nil || id != 2 {
+		t.Fatal("Resolve error: unexpected project:", id, err)
+	}
+
+	if _, err := rs.ProjectID("support"); errors.As(err, &re) == false || len(re.Matches) != 2 {
+		t.Fatal("Resolve error: expected ambiguity error, got:", err)
+	}
+
+	if id, err := rs.TrackerID("bug"); err != nil || id != 1 {
+		t.Fatal("Resolve error: unexpected tracker:", id, err)
+	}
+
+	if n, err := rs.TrackerName(2); err != nil || n != "Feature" {
+		t.Fatal("Resolve error: unexpected tracker name:", n, err)
+	}
+}
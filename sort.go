@@ -0,0 +1,124 @@
+package redmine
+
+import (
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// sortFields contains sort keys in priority order
+type sortFields []sortField
+
+type sortField struct {
+	field string
+	desc  bool
+}
+
+// add appends sort key. Existing key for the same field is replaced
+func (s sortFields) add(field string, desc bool) sortFields {
+
+	for i, e := range s {
+		if e.field == field {
+			s[i].desc = desc
+			return s
+		}
+	}
+
+	return append(s, sortField{
+		field: field,
+		desc:  desc,
+	})
+}
+
+func (s sortFields) url(v *url.Values) {
+
+	if len(s) == 0 {
+		return
+	}
+
+	var fs []string
+
+	for _, e := range s {
+		f := e.field
+		if e.desc == true {
+			f += ":desc"
+		}
+		fs = append(fs, f)
+	}
+
+	v.Set("sort", strings.Join(fs, ","))
+}
+
+func sortCustomField(id int64) string {
+	return "cf_" + strconv.FormatInt(id, 10)
+}
+
+// ProjectGetRequestSort contains sort keys for projects get request
+type ProjectGetRequestSort struct {
+	fields sortFields
+}
+
+// UserGetRequestSort contains sort keys for users get request
+type UserGetRequestSort struct {
+	fields sortFields
+}
+
+// TimeEntryGetRequestSort contains sort keys for time entries get request
+type TimeEntryGetRequestSort struct {
+	fields sortFields
+}
+
+func ProjectGetRequestSortInit() *ProjectGetRequestSort {
+	return &ProjectGetRequestSort{}
+}
+
+// Add adds sort key (e.g. `name`, `identifier` or `created_on`)
+func (s *ProjectGetRequestSort) Add(field string, desc bool) *ProjectGetRequestSort {
+	s.fields = s.fields.add(field, desc)
+	return s
+}
+
+// CustomFieldAdd adds sort key for custom field
+func (s *ProjectGetRequestSort) CustomFieldAdd(id int64, desc bool) *ProjectGetRequestSort {
+	s.fields = s.fields.add(sortCustomField(id), desc)
+	return s
+}
+
+func (s *ProjectGetRequestSort) url(v *url.Values) {
+	s.fields.url(v)
+}
+
+func UserGetRequestSortInit() *UserGetRequestSort {
+	return &UserGetRequestSort{}
+}
+
+// Add adds sort key (e.g. `login`, `firstname`, `lastname`,
+// `admin`, `created_on` or `last_login_on`)
+func (s *UserGetRequestSort) Add(field string, desc bool) *UserGetRequestSort {
+	s.fields = s.fields.add(field, desc)
+	return s
+}
+
+func (s *UserGetRequestSort) url(v *url.Values) {
+	s.fields.url(v)
+}
+
+func TimeEntryGetRequestSortInit() *TimeEntryGetRequestSort {
+	return &TimeEntryGetRequestSort{}
+}
+
+// Add adds sort key (e.g. `spent_on`, `user`, `activity`, `project` or `hours`)
+func (s *TimeEntryGetRequestSort) Add(field string, desc bool) *TimeEntryGetRequestSort {
+	s.fields = s.fields.add(field, desc)
+	return s
+}
+
+// CustomFieldAdd adds sort key for custom field
+func (s *TimeEntryGetRequestSort) CustomFieldAdd(id int64, desc bool) *TimeEntryGetRequestSort {
+	s.fields = s.fields.add(sortCustomField(id), desc)
+	return s
+}
+
+func (s *TimeEntryGetRequestSort) url(v *url.Values) {
+	s.fields.url(v)
+}
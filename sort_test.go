@@ -0,0 +1,43 @@
+package redmine
+
+import (
+	"testing"
+)
+
+func TestSort(t *testing.T) {
+
+	for e, v := range map[string]string{
+		"priority:desc,cf_12,updated_on:desc": IssueAllGetRequest{
+			Sort: IssueGetRequestSortInit().
+				Add("priority", true).
+				CustomFieldAdd(12, false).
+				Add("updated_on", false).
+				Add("updated_on", true),
+		}.url().Get("sort"),
+		"id": IssueMultiGetRequest{
+			Sort: IssueGetRequestSortInit().
+				Add("priority", true).
+				Set("id", false),
+		}.url().Get("sort"),
+		"name,cf_3:desc": ProjectAllGetRequest{
+			Sort: ProjectGetRequestSortInit().
+				Add("name", false).
+				CustomFieldAdd(3, true),
+		}.url().Get("sort"),
+		"lastname,firstname": UserMultiGetRequest{
+			Sort: UserGetRequestSortInit().
+				Add("lastname", false).
+				Add("firstname", false),
+		}.url().Get("sort"),
+		"spent_on:desc,hours": TimeEntryAllGetRequest{
+			Sort: TimeEntryGetRequestSortInit().
+				Add("spent_on", true).
+				Add("hours", false),
+		}.url().Get("sort"),
+		"": UserAllGetRequest{}.url().Get("sort"),
+	} {
+		if v != e {
+			t.Fatalf("Sort error: expected %q, got %q", e, v)
+		}
+	}
+}
@@ -1,24 +1,27 @@
 package redmine
 
 import (
+	"fmt"
 	"net/http"
 	"net/url"
 	"strconv"
+	"strings"
 )
 
 /* Get */
 
 type TimeEntryObject struct {
-	ID        int64                `json:"id"`
-	Project   IDName               `json:"project"`
-	Issue     TimeEntryIssueObject `json:"issue"`
-	User      IDName               `json:"user"`
-	Activity  IDName               `json:"activity"`
-	Hours     float64              `json:"hours"`
-	Comments  string               `json:"comments"`
-	SpentOn   string               `json:"spent_on"`
-	CreatedOn string               `json:"created_on"`
-	UpdatedOn string               `json:"updated_on"`
+	ID           int64                  `json:"id"`
+	Project      IDName                 `json:"project"`
+	Issue        TimeEntryIssueObject   `json:"issue"`
+	User         IDName                 `json:"user"`
+	Activity     IDName                 `json:"activity"`
+	Hours        float64                `json:"hours"`
+	Comments     string                 `json:"comments"`
+	SpentOn      Date                   `json:"spent_on"`
+	CustomFields []CustomFieldGetObject `json:"custom_fields"`
+	CreatedOn    DateTime               `json:"created_on"`
+	UpdatedOn    DateTime               `json:"updated_on"`
 }
 
 type TimeEntryIssueObject struct {
@@ -32,13 +35,14 @@ type TimeEntryCreate struct {
 }
 
 type TimeEntryCreateObject struct {
-	ProjectID  *string `json:"project_id,omitempty"`
-	IssueID    *int64  `json:"issue_id,omitempty"`
-	UserID     *int64  `json:"user_id,omitempty"`
-	ActivityID int64   `json:"activity_id"`
-	Hours      float64 `json:"hours"`
-	Comments   string  `json:"comments"`
-	SpentOn    *string `json:"spent_on,omitempty"`
+	ProjectID    *string                    `json:"project_id,omitempty"`
+	IssueID      *int64                     `json:"issue_id,omitempty"`
+	UserID       *int64                     `json:"user_id,omitempty"`
+	ActivityID   int64                      `json:"activity_id"`
+	Hours        float64                    `json:"hours"`
+	Comments     string                     `json:"comments"`
+	SpentOn      *Date                      `json:"spent_on,omitempty"`
+	CustomFields *[]CustomFieldUpdateObject `json:"custom_fields,omitempty"`
 }
 
 /* Update */
@@ -48,31 +52,45 @@ type TimeEntryUpdate struct {
 }
 
 type TimeEntryUpdateObject struct {
-	ProjectID  *string  `json:"project_id,omitempty"`
-	IssueID    *int64   `json:"issue_id,omitempty"`
-	UserID     *int64   `json:"user_id,omitempty"`
-	ActivityID *int64   `json:"activity_id,omitempty"`
-	Hours      *float64 `json:"hours,omitempty"`
-	Comments   *string  `json:"comments,omitempty"`
-	SpentOn    *string  `json:"spent_on,omitempty"`
+	ProjectID    *string                    `json:"project_id,omitempty"`
+	IssueID      *int64                     `json:"issue_id,omitempty"`
+	UserID       *int64                     `json:"user_id,omitempty"`
+	ActivityID   *int64                     `json:"activity_id,omitempty"`
+	Hours        *float64                   `json:"hours,omitempty"`
+	Comments     *string                    `json:"comments,omitempty"`
+	SpentOn      *Date                      `json:"spent_on,omitempty"`
+	CustomFields *[]CustomFieldUpdateObject `json:"custom_fields,omitempty"`
 }
 
 /* Requests */
 
 type TimeEntryAllGetRequest struct {
+	Sort    *TimeEntryGetRequestSort
 	Filters *TimeEntryGetRequestFilters
 }
 
+// TimeEntryMultiGetRequest contains data for making request to get limited time entries count satisfying specified filters
+type TimeEntryMultiGetRequest struct {
+	Sort    *TimeEntryGetRequestSort
+	Filters *TimeEntryGetRequestFilters
+	Offset  int64
+	Limit   int64
+}
+
 // Empty struct (uses as placeholder)
 type TimeEntrySingleGetRequest struct {
 }
 
 type TimeEntryGetRequestFilters struct {
-	userID      *int64
-	projectID   *string
-	spentOnFrom *string
-	spentOnTo   *string
-	activityID  *int64
+	userIDs       []int64
+	projectID     *string
+	issueID       *int64
+	issueSubtasks bool
+	spentOnFrom   *string
+	spentOnTo     *string
+	activityID    *int64
+	filters       map[string]filter
+	err           error
 }
 
 /* Results */
@@ -91,6 +109,21 @@ type timeEntrySingleResult struct {
 	TimeEntry TimeEntryObject `json:"time_entry"`
 }
 
+type timeEntryHoursResult struct {
+	TimeEntries []struct {
+		Hours float64 `json:"hours"`
+	} `json:"time_entries"`
+	TotalCount int64 `json:"total_count"`
+	Limit      int64 `json:"limit"`
+}
+
+type timeEntryIssueSpentResult struct {
+	Issue struct {
+		SpentHours      *float64 `json:"spent_hours"`
+		TotalSpentHours *float64 `json:"total_spent_hours"`
+	} `json:"issue"`
+}
+
 // TimeEntryAllGet gets info for all time entries satisfying specified filters
 //
 // see: https://www.redmine.org/projects/redmine/wiki/Rest_TimeEntries#Listing-time-entries
@@ -102,6 +135,10 @@ func (r *Context) TimeEntryAllGet(request TimeEntryAllGetRequest) (TimeEntryResu
 		status    StatusCode
 	)
 
+	if request.Filters != nil && request.Filters.err != nil {
+		return timeEntry, 0, request.Filters.err
+	}
+
 	up := request.url()
 	up.Set("limit", strconv.FormatInt(limitDefault, 10))
 
@@ -140,6 +177,112 @@ func (r *Context) TimeEntryAllGet(request TimeEntryAllGetRequest) (TimeEntryResu
 	return timeEntry, status, nil
 }
 
+// TimeEntryMultiGet gets info for multiple time entries satisfying specified filters
+//
+// see: https://www.redmine.org/projects/redmine/wiki/Rest_TimeEntries#Listing-time-entries
+func (r *Context) TimeEntryMultiGet(request TimeEntryMultiGetRequest) (TimeEntryResult, StatusCode, error) {
+
+	var t TimeEntryResult
+
+	if request.Filters != nil && request.Filters.err != nil {
+		return t, 0, request.Filters.err
+	}
+
+	s, err := r.Get(
+		&t,
+		url.URL{
+			Path:     "/time_entries.json",
+			RawQuery: request.url().Encode(),
+		},
+		http.StatusOK,
+	)
+
+	return t, s, err
+}
+
+// TimeEntryTotalHoursGet gets total hours of time entries satisfying specified filters.
+// Redmine API does not provide totals for time entries lists, so if filters contain an
+// issue only, total is got from the issue `spent_hours` (or `total_spent_hours` with
+// subtasks) with a single request. Redmine provides no spent hours for projects, so
+// for any other filters (including project ones) it is a full scan: all matching time
+// entries are requested by pages of max size (one request per 100 entries) and only
+// hours are accumulated. Narrow project totals with dates filters where possible
+//
+// see: https://www.redmine.org/projects/redmine/wiki/Rest_TimeEntries#Listing-time-entries
+func (r *Context) TimeEntryTotalHoursGet(filters *TimeEntryGetRequestFilters) (float64, StatusCode, error) {
+
+	var (
+		hours  float64
+		offset int64
+		status StatusCode
+	)
+
+	if filters != nil && filters.err != nil {
+		return 0, 0, filters.err
+	}
+
+	if filters != nil && filters.issueOnly() == true {
+
+		var i timeEntryIssueSpentResult
+
+		s, err := r.Get(
+			&i,
+			url.URL{
+				Path: "/issues/" + strconv.FormatInt(*filters.issueID, 10) + ".json",
+			},
+			http.StatusOK,
+		)
+		if err != nil {
+			return 0, s, err
+		}
+
+		// Spent hours are absent if user is not allowed to view time entries
+		// within the issue project, in this case time entries are requested
+		switch {
+		case filters.issueSubtasks == true && i.Issue.TotalSpentHours != nil:
+			return *i.Issue.TotalSpentHours, s, nil
+		case filters.issueSubtasks == false && i.Issue.SpentHours != nil:
+			return *i.Issue.SpentHours, s, nil
+		}
+	}
+
+	up := TimeEntryAllGetRequest{Filters: filters}.url()
+	up.Set("limit", strconv.FormatInt(limitDefault, 10))
+
+	for {
+
+		var t timeEntryHoursResult
+
+		up.Set("offset", strconv.FormatInt(offset, 10))
+
+		s, err := r.Get(
+			&t,
+			url.URL{
+				Path:     "/time_entries.json",
+				RawQuery: up.Encode(),
+			},
+			http.StatusOK,
+		)
+		if err != nil {
+			return hours, s, err
+		}
+
+		status = s
+
+		for _, e := range t.TimeEntries {
+			hours += e.Hours
+		}
+
+		if t.Limit == 0 || offset+t.Limit >= t.TotalCount {
+			break
+		}
+
+		offset += t.Limit
+	}
+
+	return hours, status, nil
+}
+
 // TimeEntrySingleGet gets single time entry info by specific ID
 //
 // see: https://www.redmine.org/projects/redmine/wiki/Rest_TimeEntries#Showing-a-time-entry
@@ -216,10 +359,32 @@ func (tr TimeEntryAllGetRequest) url() url.Values {
 
 	v := url.Values{}
 
+	if tr.Sort != nil {
+		tr.Sort.url(&v)
+	}
+
+	if tr.Filters != nil {
+		tr.Filters.url(&v)
+	}
+
+	return v
+}
+
+func (tr TimeEntryMultiGetRequest) url() url.Values {
+
+	v := url.Values{}
+
+	if tr.Sort != nil {
+		tr.Sort.url(&v)
+	}
+
 	if tr.Filters != nil {
 		tr.Filters.url(&v)
 	}
 
+	v.Set("offset", strconv.FormatInt(tr.Offset, 10))
+	v.Set("limit", strconv.FormatInt(tr.Limit, 10))
+
 	return v
 }
 
@@ -228,7 +393,9 @@ func (ur TimeEntrySingleGetRequest) url() url.Values {
 }
 
 func TimeEntryGetRequestFiltersInit() *TimeEntryGetRequestFilters {
-	return &TimeEntryGetRequestFilters{}
+	return &TimeEntryGetRequestFilters{
+		filters: make(map[string]filter),
+	}
 }
 
 func (f *TimeEntryGetRequestFilters) ProjectSet(id string) *TimeEntryGetRequestFilters {
@@ -243,7 +410,21 @@ func (f *TimeEntryGetRequestFilters) SpentOnSet(from, to string) *TimeEntryGetRe
 }
 
 func (f *TimeEntryGetRequestFilters) UserIDSet(u int64) *TimeEntryGetRequestFilters {
-	f.userID = &u
+	f.userIDs = []int64{u}
+	return f
+}
+
+// UserIDsSet sets filter for time entries of any of specified users
+func (f *TimeEntryGetRequestFilters) UserIDsSet(us ...int64) *TimeEntryGetRequestFilters {
+	f.userIDs = append([]int64{}, us...)
+	return f
+}
+
+// IssueIDSet sets filter for time entries of specified issue.
+// With `withSubtasks` time entries of all issue descendants are included
+func (f *TimeEntryGetRequestFilters) IssueIDSet(id int64, withSubtasks bool) *TimeEntryGetRequestFilters {
+	f.issueID = &id
+	f.issueSubtasks = withSubtasks
 	return f
 }
 
@@ -252,6 +433,40 @@ func (f *TimeEntryGetRequestFilters) ActivityIDSet(a int64) *TimeEntryGetRequest
 	return f
 }
 
+// CustomFieldFilterAdd adds filter for time entry custom field with specified operator
+// (see `IssueGetRequestFilters.Add` for operators usage)
+func (f *TimeEntryGetRequestFilters) CustomFieldFilterAdd(id int64, op FilterOperator, values ...string) *TimeEntryGetRequestFilters {
+
+	field := "cf_" + strconv.FormatInt(id, 10)
+
+	e, err := filterInit(filterTypeAny, op, values)
+	if err != nil {
+		if f.err == nil {
+			f.err = fmt.Errorf("time entry filter `%s`: %w", field, err)
+		}
+		return f
+	}
+
+	if f.filters == nil {
+		f.filters = make(map[string]filter)
+	}
+
+	f.filters[field] = e
+
+	return f
+}
+
+// Err returns the first error occurred while filters adding
+func (f *TimeEntryGetRequestFilters) Err() error {
+	return f.err
+}
+
+// issueOnly checks issue is the only filter
+func (f *TimeEntryGetRequestFilters) issueOnly() bool {
+	return f.issueID != nil && len(f.userIDs) == 0 && f.projectID == nil && f.spentOnFrom == nil &&
+		f.spentOnTo == nil && f.activityID == nil && len(f.filters) == 0
+}
+
 func (f *TimeEntryGetRequestFilters) url(v *url.Values) {
 
 	if f.projectID != nil {
@@ -266,11 +481,27 @@ func (f *TimeEntryGetRequestFilters) url(v *url.Values) {
 		v.Set("to", *f.spentOnTo)
 	}
 
-	if f.userID != nil {
-		v.Set("user_id", strconv.FormatInt(*f.userID, 10))
+	if f.issueID != nil {
+		id := strconv.FormatInt(*f.issueID, 10)
+		if f.issueSubtasks == true {
+			id = "~" + id
+		}
+		v.Set("issue_id", id)
+	}
+
+	if len(f.userIDs) > 0 {
+		var us []string
+		for _, u := range f.userIDs {
+			us = append(us, strconv.FormatInt(u, 10))
+		}
+		v.Set("user_id", strings.Join(us, "|"))
 	}
 
 	if f.activityID != nil {
 		v.Set("activity_id", strconv.FormatInt(*f.activityID, 10))
 	}
+
+	for n, e := range f.filters {
+		v.Set(n, e.expression())
+	}
 }
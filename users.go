@@ -48,9 +48,9 @@ type UserObject struct {
 	FirstName       string                  `json:"firstname"`
 	LastName        string                  `json:"lastname"`
 	Mail            string                  `json:"mail"`
-	CreatedOn       string                  `json:"created_on"`
-	LastLoginOn     string                  `json:"last_login_on"`
-	PasswdChangedOn string                  `json:"passwd_changed_on"`
+	CreatedOn       DateTime                `json:"created_on"`
+	LastLoginOn     *DateTime               `json:"last_login_on"`
+	PasswdChangedOn *DateTime               `json:"passwd_changed_on"`
 	TwofaScheme     *string                 `json:"twofa_scheme"` // has nil value if 2FA not enabled and "totp" string value otherwise
 	APIKey          *string                 `json:"api_key"`      // used only: get single user
 	Status          *UserStatus             `json:"status"`       // used only: get single user
@@ -105,6 +105,7 @@ type UserUpdateObject struct {
 	MailNotification *string                    `json:"mail_notification,omitempty"`
 	MustChangePasswd *bool                      `json:"must_change_passwd,omitempty"`
 	GeneratePassword *bool                      `json:"generate_password,omitempty"`
+	Status           *UserStatus                `json:"status,omitempty"`
 	CustomFields     *[]CustomFieldUpdateObject `json:"custom_fields,omitempty"`
 }
 
@@ -112,11 +113,13 @@ type UserUpdateObject struct {
 
 // UserAllGetRequest contains data for making request to get all users satisfying specified filters
 type UserAllGetRequest struct {
+	Sort    *UserGetRequestSort
 	Filters *UserGetRequestFilters
 }
 
 // UserMultiGetRequest contains data for making request to get limited users count satisfying specified filters
 type UserMultiGetRequest struct {
+	Sort    *UserGetRequestSort
 	Filters *UserGetRequestFilters
 	Offset  int64
 	Limit   int64
@@ -134,9 +137,10 @@ type UserCurrentGetRequest struct {
 
 // UserGetRequestFilters contains data for making users get request
 type UserGetRequestFilters struct {
-	status  *UserStatus
-	name    *string
-	groupID *int64
+	status    *UserStatus
+	anyStatus bool
+	name      *string
+	groupID   *int64
 }
 
 /* Results */
@@ -341,10 +345,28 @@ func (r *Context) UserDelete(id int64) (StatusCode, error) {
 	return status, err
 }
 
+// UserLock locks user with specified ID
+//
+// see: https://www.redmine.org/projects/redmine/wiki/Rest_Users#PUT
+func (r *Context) UserLock(id int64) (StatusCode, error) {
+	return r.userStatusSet(id, UserStatusLocked)
+}
+
+// UserUnlock unlocks user with specified ID
+//
+// see: https://www.redmine.org/projects/redmine/wiki/Rest_Users#PUT
+func (r *Context) UserUnlock(id int64) (StatusCode, error) {
+	return r.userStatusSet(id, UserStatusActive)
+}
+
 func (ur UserAllGetRequest) url() url.Values {
 
 	v := url.Values{}
 
+	if ur.Sort != nil {
+		ur.Sort.url(&v)
+	}
+
 	if ur.Filters != nil {
 		ur.Filters.url(&v)
 	}
@@ -356,6 +378,10 @@ func (ur UserMultiGetRequest) url() url.Values {
 
 	v := url.Values{}
 
+	if ur.Sort != nil {
+		ur.Sort.url(&v)
+	}
+
 	if ur.Filters != nil {
 		ur.Filters.url(&v)
 	}
@@ -418,6 +444,15 @@ func UserGetRequestFiltersInit() *UserGetRequestFilters {
 
 func (f *UserGetRequestFilters) StatusSet(s UserStatus) *UserGetRequestFilters {
 	f.status = &s
+	f.anyStatus = false
+	return f
+}
+
+// StatusAnySet makes request to get users of any status. Only active
+// users are returned by Redmine if status is not set
+func (f *UserGetRequestFilters) StatusAnySet() *UserGetRequestFilters {
+	f.status = nil
+	f.anyStatus = true
 	return f
 }
 
@@ -437,6 +472,11 @@ func (f *UserGetRequestFilters) url(v *url.Values) {
 		v.Set("status", strconv.FormatInt(int64(*f.status), 10))
 	}
 
+	// Empty status means any status
+	if f.anyStatus == true {
+		v.Set("status", "")
+	}
+
 	if f.name != nil {
 		v.Set("name", *f.name)
 	}
@@ -445,3 +485,14 @@ func (f *UserGetRequestFilters) url(v *url.Values) {
 		v.Set("group_id", strconv.FormatInt(*f.groupID, 10))
 	}
 }
+
+func (r *Context) userStatusSet(id int64, s UserStatus) (StatusCode, error) {
+	return r.UserUpdate(
+		id,
+		UserUpdate{
+			User: UserUpdateObject{
+				Status: &s,
+			},
+		},
+	)
+}
@@ -0,0 +1,194 @@
+package redmine
+
+import (
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+// VersionStatus defines version status type
+type VersionStatus string
+
+// VersionStatus const
+const (
+	VersionStatusOpen   VersionStatus = "open"
+	VersionStatusLocked VersionStatus = "locked"
+	VersionStatusClosed VersionStatus = "closed"
+)
+
+// VersionSharing defines version sharing type
+type VersionSharing string
+
+// VersionSharing const
+const (
+	VersionSharingNone        VersionSharing = "none"
+	VersionSharingDescendants VersionSharing = "descendants"
+	VersionSharingHierarchy   VersionSharing = "hierarchy"
+	VersionSharingTree        VersionSharing = "tree"
+	VersionSharingSystem      VersionSharing = "system"
+)
+
+/* Get */
+
+// VersionObject struct used for versions get operations
+type VersionObject struct {
+	ID             int64                  `json:"id"`
+	Project        IDName                 `json:"project"`
+	Name           string                 `json:"name"`
+	Description    string                 `json:"description"`
+	Status         VersionStatus          `json:"status"`
+	DueDate        *Date                  `json:"due_date"`
+	Sharing        VersionSharing         `json:"sharing"`
+	WikiPageTitle  string                 `json:"wiki_page_title"`
+	EstimatedHours float64                `json:"estimated_hours"`
+	SpentHours     float64                `json:"spent_hours"`
+	CustomFields   []CustomFieldGetObject `json:"custom_fields"`
+	CreatedOn      DateTime               `json:"created_on"`
+	UpdatedOn      DateTime               `json:"updated_on"`
+}
+
+/* Create */
+
+// VersionCreate struct used for versions create operations
+type VersionCreate struct {
+	Version VersionCreateObject `json:"version"`
+}
+
+type VersionCreateObject struct {
+	Name          string                     `json:"name"`
+	Description   *string                    `json:"description,omitempty"`
+	Status        *VersionStatus             `json:"status,omitempty"`
+	DueDate       *Date                      `json:"due_date,omitempty"`
+	Sharing       *VersionSharing            `json:"sharing,omitempty"`
+	WikiPageTitle *string                    `json:"wiki_page_title,omitempty"`
+	CustomFields  *[]CustomFieldUpdateObject `json:"custom_fields,omitempty"`
+}
+
+/* Update */
+
+// VersionUpdate struct used for versions update operations
+type VersionUpdate struct {
+	Version VersionUpdateObject `json:"version"`
+}
+
+type VersionUpdateObject struct {
+	Name          *string                    `json:"name,omitempty"`
+	Description   *string                    `json:"description,omitempty"`
+	Status        *VersionStatus             `json:"status,omitempty"`
+	DueDate       *Date                      `json:"due_date,omitempty"`
+	Sharing       *VersionSharing            `json:"sharing,omitempty"`
+	WikiPageTitle *string                    `json:"wiki_page_title,omitempty"`
+	CustomFields  *[]CustomFieldUpdateObject `json:"custom_fields,omitempty"`
+}
+
+/* Results */
+
+// VersionResult stores versions requests processing result
+type VersionResult struct {
+	Versions   []VersionObject `json:"versions"`
+	TotalCount int64           `json:"total_count"`
+}
+
+/* Internal types */
+
+type versionSingleResult struct {
+	Version VersionObject `json:"version"`
+}
+
+func (v VersionStatus) String() string {
+	return string(v)
+}
+
+func (v VersionSharing) String() string {
+	return string(v)
+}
+
+// VersionAllGet gets info for all versions available for project with specified ID
+// (including versions shared by other projects)
+//
+// see: https://www.redmine.org/projects/redmine/wiki/Rest_Versions#GET
+func (r *Context) VersionAllGet(projectID string) (VersionResult, StatusCode, error) {
+
+	var v VersionResult
+
+	status, err := r.Get(
+		&v,
+		url.URL{
+			Path: "/projects/" + projectID + "/versions.json",
+		},
+		http.StatusOK,
+	)
+
+	return v, status, err
+}
+
+// VersionSingleGet gets single version info with specified ID
+//
+// see: https://www.redmine.org/projects/redmine/wiki/Rest_Versions#GET-2
+func (r *Context) VersionSingleGet(id int64) (VersionObject, StatusCode, error) {
+
+	var v versionSingleResult
+
+	status, err := r.Get(
+		&v,
+		url.URL{
+			Path: "/versions/" + strconv.FormatInt(id, 10) + ".json",
+		},
+		http.StatusOK,
+	)
+
+	return v.Version, status, err
+}
+
+// VersionCreate creates new version for project with specified ID
+//
+// see: https://www.redmine.org/projects/redmine/wiki/Rest_Versions#POST
+func (r *Context) VersionCreate(projectID string, version VersionCreate) (VersionObject, StatusCode, error) {
+
+	var v versionSingleResult
+
+	status, err := r.Post(
+		version,
+		&v,
+		url.URL{
+			Path: "/projects/" + projectID + "/versions.json",
+		},
+		http.StatusCreated,
+	)
+
+	return v.Version, status, err
+}
+
+// VersionUpdate updates version with specified ID
+//
+// see: https://www.redmine.org/projects/redmine/wiki/Rest_Versions#PUT
+func (r *Context) VersionUpdate(id int64, version VersionUpdate) (StatusCode, error) {
+
+	status, err := r.Put(
+		version,
+		nil,
+		url.URL{
+			Path: "/versions/" + strconv.FormatInt(id, 10) + ".json",
+		},
+		http.StatusNoContent,
+	)
+
+	return status, err
+}
+
+// VersionDelete deletes version with specified ID
+//
+// see: https://www.redmine.org/projects/redmine/wiki/Rest_Versions#DELETE
+func (r *Context) VersionDelete(id int64) (StatusCode, error) {
+
+	status, err := r.Del(
+		nil,
+		nil,
+		url.URL{
+			Path: "/versions/" + strconv.FormatInt(id, 10) + ".json",
+		},
+		http.StatusNoContent,
+	)
+
+	return status, err
+}
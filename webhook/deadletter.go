@@ -0,0 +1,184 @@
+package webhook
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+)
+
+// DeadLetterStore is an interface to keep deliveries that could not be sent
+type DeadLetterStore interface {
+
+	// Save stores failed delivery
+	Save(Delivery) error
+
+	// List returns all stored deliveries
+	List() ([]Delivery, error)
+
+	// Delete removes delivery with specified ID. Missing delivery is not an error
+	Delete(id string) error
+}
+
+// MemoryDeadLetterStore stores failed deliveries in memory
+type MemoryDeadLetterStore struct {
+	mu sync.Mutex
+	ds []Delivery
+}
+
+// FileDeadLetterStore stores failed deliveries in file (one JSON document per line)
+type FileDeadLetterStore struct {
+	mu   sync.Mutex
+	path string
+}
+
+// MemoryDeadLetterStoreInit creates new in-memory dead letter store
+func MemoryDeadLetterStoreInit() *MemoryDeadLetterStore {
+	return &MemoryDeadLetterStore{}
+}
+
+// Save stores failed delivery
+func (s *MemoryDeadLetterStore) Save(d Delivery) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.ds = append(s.ds, d)
+	return nil
+}
+
+// List returns all stored deliveries
+func (s *MemoryDeadLetterStore) List() ([]Delivery, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Delivery{}, s.ds...), nil
+}
+
+// Delete removes delivery with specified ID
+func (s *MemoryDeadLetterStore) Delete(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.ds = slices.DeleteFunc(s.ds, func(d Delivery) bool {
+		return d.ID == id
+	})
+	return nil
+}
+
+// FileDeadLetterStoreInit creates new dead letter store within file with specified path
+func FileDeadLetterStoreInit(path string) *FileDeadLetterStore {
+	return &FileDeadLetterStore{
+		path: path,
+	}
+}
+
+// Save appends failed delivery to the file
+func (s *FileDeadLetterStore) Save(d Delivery) error {
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	b, err := json.Marshal(d)
+	if err != nil {
+		return err
+	}
+
+	f, err := os.OpenFile(s.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+
+	if _, err := f.Write(append(b, '\n')); err != nil {
+		f.Close()
+		return err
+	}
+
+	return f.Close()
+}
+
+// List reads all deliveries from the file
+func (s *FileDeadLetterStore) List() ([]Delivery, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.list()
+}
+
+// Delete removes delivery with specified ID. File is replaced atomically
+func (s *FileDeadLetterStore) Delete(id string) error {
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ds, err := s.list()
+	if err != nil {
+		return err
+	}
+
+	if slices.ContainsFunc(ds, func(d Delivery) bool { return d.ID == id }) == false {
+		return nil
+	}
+
+	f, err := os.CreateTemp(filepath.Dir(s.path), filepath.Base(s.path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+
+	for _, d := range ds {
+
+		if d.ID == id {
+			continue
+		}
+
+		b, err := json.Marshal(d)
+		if err != nil {
+			f.Close()
+			return err
+		}
+
+		if _, err := f.Write(append(b, '\n')); err != nil {
+			f.Close()
+			return err
+		}
+	}
+
+	if err := f.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(f.Name(), s.path)
+}
+
+func (s *FileDeadLetterStore) list() ([]Delivery, error) {
+
+	var ds []Delivery
+
+	f, err := os.Open(s.path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return ds, nil
+		}
+		return nil, err
+	}
+	defer f.Close()
+
+	sc := bufio.NewScanner(f)
+	sc.Buffer(make([]byte, 64*1024), 16*1024*1024)
+
+	for sc.Scan() {
+
+		var d Delivery
+
+		if len(sc.Bytes()) == 0 {
+			continue
+		}
+
+		if err := json.Unmarshal(sc.Bytes(), &d); err != nil {
+			return nil, err
+		}
+
+		ds = append(ds, d)
+	}
+
+	return ds, sc.Err()
+}
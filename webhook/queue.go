@@ -0,0 +1,108 @@
+package webhook
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// QueueStore is an interface to persist deliveries waiting to be sent, so
+// they survive dispatcher restarts
+type QueueStore interface {
+
+	// Load returns saved deliveries
+	Load() ([]Delivery, error)
+
+	// Save replaces saved deliveries with specified ones
+	Save([]Delivery) error
+}
+
+// MemoryQueueStore stores deliveries queue in memory
+type MemoryQueueStore struct {
+	mu sync.Mutex
+	ds []Delivery
+}
+
+// FileQueueStore stores deliveries queue in file
+type FileQueueStore struct {
+	path string
+}
+
+// MemoryQueueStoreInit creates new in-memory queue store
+func MemoryQueueStoreInit() *MemoryQueueStore {
+	return &MemoryQueueStore{}
+}
+
+// Load returns saved deliveries
+func (s *MemoryQueueStore) Load() ([]Delivery, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Delivery{}, s.ds...), nil
+}
+
+// Save replaces saved deliveries with specified ones
+func (s *MemoryQueueStore) Save(ds []Delivery) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.ds = append([]Delivery{}, ds...)
+	return nil
+}
+
+// FileQueueStoreInit creates new queue store within file with specified path
+func FileQueueStoreInit(path string) *FileQueueStore {
+	return &FileQueueStore{
+		path: path,
+	}
+}
+
+// Load reads deliveries from the file. Empty queue is returned if file does not exist
+func (s *FileQueueStore) Load() ([]Delivery, error) {
+
+	var ds []Delivery
+
+	b, err := os.ReadFile(s.path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) == true {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	if err := json.Unmarshal(b, &ds); err != nil {
+		return nil, err
+	}
+
+	return ds, nil
+}
+
+// Save writes deliveries into file. File is replaced atomically
+func (s *FileQueueStore) Save(ds []Delivery) error {
+
+	if ds == nil {
+		ds = []Delivery{}
+	}
+
+	b, err := json.Marshal(ds)
+	if err != nil {
+		return err
+	}
+
+	f, err := os.CreateTemp(filepath.Dir(s.path), filepath.Base(s.path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+
+	if _, err := f.Write(b); err != nil {
+		f.Close()
+		return err
+	}
+
+	if err := f.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(f.Name(), s.path)
+}
@@ -0,0 +1,491 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"sync"
+	"time"
+
+	redmine "github.com/nixys/nxs-go-redmine/v5"
+	"github.com/nixys/nxs-go-redmine/v5/poller"
+)
+
+const (
+	maxAttemptsDefault   = 5
+	retryIntervalDefault = 10 * time.Second
+	timeoutDefault       = 30 * time.Second
+	processInterval      = time.Second
+)
+
+// Headers set for every delivery request
+const (
+	HeaderSignature = "X-Redmine-Webhook-Signature"
+	HeaderEvent     = "X-Redmine-Webhook-Event"
+	HeaderDelivery  = "X-Redmine-Webhook-Delivery"
+)
+
+// Settings contains data to init dispatcher
+type Settings struct {
+	Subscriptions []Subscription
+	Client        *http.Client    // HTTP client used for deliveries. Client with 30 seconds timeout is used if not set
+	MaxAttempts   int             // Attempts count before delivery moves to dead letters. 5 if not set
+	RetryInterval time.Duration   // Delay before first retry, doubled for every next one. 10 seconds if not set
+	DeadLetters   DeadLetterStore // Store for failed deliveries. In-memory store is used if not set
+	Queue         QueueStore      // Store for deliveries waiting to be sent. In-memory store is used if not set
+}
+
+// Subscription describes an endpoint events are delivered to
+type Subscription struct {
+	ID         string
+	URL        string
+	Secret     string             // Key to sign payloads with HMAC-SHA256
+	ProjectIDs []int64            // Deliver events only for issues within specified projects. All projects if empty
+	TrackerIDs []int64            // Deliver events only for issues with specified trackers. All trackers if empty
+	EventTypes []poller.EventType // Deliver only events with specified types. All events if empty
+}
+
+// Payload is a JSON body sent to subscribers
+type Payload struct {
+	Event     poller.EventType                  `json:"event"`
+	CreatedOn time.Time                         `json:"created_on"`
+	User      redmine.IDName                    `json:"user"`
+	Issue     redmine.IssueObject               `json:"issue"`
+	Journal   *redmine.IssueJournalObject       `json:"journal,omitempty"`
+	Detail    *redmine.IssueJournalDetailObject `json:"detail,omitempty"`
+}
+
+// Delivery contains data of a single payload delivery to subscriber
+type Delivery struct {
+	ID             string           `json:"id"`
+	SubscriptionID string           `json:"subscription_id"`
+	URL            string           `json:"url"`
+	Event          poller.EventType `json:"event"`
+	Body           json.RawMessage  `json:"body"`
+	Attempts       int              `json:"attempts"`
+	NextAttempt    time.Time        `json:"next_attempt"`
+	LastError      string           `json:"last_error"`
+	secret         string
+}
+
+// Dispatcher delivers poller events to subscribed endpoints
+type Dispatcher struct {
+	subscriptions []Subscription
+	client        *http.Client
+	maxAttempts   int
+	retryInterval time.Duration
+	deadLetters   DeadLetterStore
+	queueStore    QueueStore
+
+	mu       sync.Mutex
+	loaded   bool       // Queue is loaded from the store
+	queue    []Delivery // Deliveries waiting to be sent
+	inflight []Delivery // Deliveries being sent by `Process()`
+	wake     chan struct{}
+}
+
+// Init creates new dispatcher
+func Init(s Settings) *Dispatcher {
+
+	d := &Dispatcher{
+		subscriptions: s.Subscriptions,
+		client:        s.Client,
+		maxAttempts:   s.MaxAttempts,
+		retryInterval: s.RetryInterval,
+		deadLetters:   s.DeadLetters,
+		queueStore:    s.Queue,
+		wake:          make(chan struct{}, 1),
+	}
+
+	if d.client == nil {
+		d.client = &http.Client{
+			Timeout: timeoutDefault,
+		}
+	}
+
+	if d.maxAttempts <= 0 {
+		d.maxAttempts = maxAttemptsDefault
+	}
+
+	if d.retryInterval == 0 {
+		d.retryInterval = retryIntervalDefault
+	}
+
+	if d.deadLetters == nil {
+		d.deadLetters = MemoryDeadLetterStoreInit()
+	}
+
+	if d.queueStore == nil {
+		d.queueStore = MemoryQueueStoreInit()
+	}
+
+	return d
+}
+
+// Attach registers dispatcher as a handler for all events of specified poller
+func (d *Dispatcher) Attach(p *poller.Poller) *Dispatcher {
+	p.HandlerAdd(d.Handle)
+	return d
+}
+
+// Handle enqueues deliveries of the event for all matching subscriptions.
+// It is a poller handler and may be registered by `poller.HandlerAdd()`.
+// Handle returns after the queue is saved into the queue store, so the
+// poller moves its checkpoint only past events whose deliveries are persisted
+func (d *Dispatcher) Handle(e poller.Event) error {
+
+	var ds []Delivery
+
+	for _, s := range d.subscriptions {
+
+		if s.match(e) == false {
+			continue
+		}
+
+		b, err := json.Marshal(Payload{
+			Event:     e.Type,
+			CreatedOn: e.CreatedOn,
+			User:      e.User,
+			Issue:     e.Issue,
+			Journal:   e.Journal,
+			Detail:    e.Detail,
+		})
+		if err != nil {
+			return fmt.Errorf("webhook: payload marshal error: %w", err)
+		}
+
+		id, err := deliveryID()
+		if err != nil {
+			return fmt.Errorf("webhook: delivery id generate error: %w", err)
+		}
+
+		ds = append(ds, Delivery{
+			ID:             id,
+			SubscriptionID: s.ID,
+			URL:            s.URL,
+			Event:          e.Type,
+			Body:           b,
+			NextAttempt:    time.Now(),
+			secret:         s.Secret,
+		})
+	}
+
+	if err := d.enqueue(ds...); err != nil {
+		return fmt.Errorf("webhook: %w", err)
+	}
+
+	return nil
+}
+
+// Redeliver puts delivery (e.g. taken from dead letters) back into the queue with
+// reset attempts counter. After the delivery is enqueued it is deleted from dead letters
+func (d *Dispatcher) Redeliver(dl Delivery) error {
+
+	for _, s := range d.subscriptions {
+		if s.ID == dl.SubscriptionID {
+			dl.secret = s.Secret
+			dl.URL = s.URL
+			dl.Attempts = 0
+			dl.NextAttempt = time.Now()
+			if err := d.enqueue(dl); err != nil {
+				return fmt.Errorf("webhook: %w", err)
+			}
+			if err := d.deadLetters.Delete(dl.ID); err != nil {
+				return fmt.Errorf("webhook: dead letter delete error: %w", err)
+			}
+			return nil
+		}
+	}
+
+	return fmt.Errorf("webhook: unknown subscription `%s`", dl.SubscriptionID)
+}
+
+// Pending returns count of deliveries waiting in queue
+func (d *Dispatcher) Pending() int {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	// Load error is returned by the next `Handle()` or `Process()` call
+	d.load()
+	return len(d.queue)
+}
+
+// Run processes deliveries queue until context is done
+func (d *Dispatcher) Run(ctx context.Context) error {
+
+	t := time.NewTicker(processInterval)
+	defer t.Stop()
+
+	for {
+
+		if err := d.Process(ctx); err != nil {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-t.C:
+		case <-d.wake:
+		}
+	}
+}
+
+// Process sends all deliveries due at this moment. Failed deliveries
+// are rescheduled or moved to dead letters when attempts are exhausted.
+// Deliveries that could not be saved into dead letters are kept in the
+// queue, all such errors are returned after the rest deliveries are processed.
+// Deliveries being sent are kept in the queue store until they are processed,
+// so they are sent again if dispatcher stops in the middle. If context is
+// done, processing stops and not sent deliveries are kept in the queue as is
+func (d *Dispatcher) Process(ctx context.Context) error {
+
+	var (
+		errs  []error
+		retry []Delivery
+	)
+
+	now := time.Now()
+
+	d.mu.Lock()
+	if err := d.load(); err != nil {
+		d.mu.Unlock()
+		return fmt.Errorf("webhook: %w", err)
+	}
+	var due, rest []Delivery
+	for _, dl := range d.queue {
+		if dl.NextAttempt.After(now) == true {
+			rest = append(rest, dl)
+		} else {
+			due = append(due, dl)
+		}
+	}
+	d.queue = rest
+	d.inflight = due
+	d.mu.Unlock()
+
+	for n, dl := range due {
+
+		err := ctx.Err()
+		if err == nil {
+			err = d.deliver(ctx, dl)
+		}
+
+		if err == nil {
+			continue
+		}
+
+		// Endpoint has not been really tried, so attempts are not counted
+		if ctx.Err() != nil {
+			retry = append(retry, due[n:]...)
+			errs = append(errs, ctx.Err())
+			break
+		}
+
+		dl.Attempts++
+		dl.LastError = err.Error()
+
+		if dl.Attempts >= d.maxAttempts {
+			err := d.deadLetters.Save(dl)
+			if err == nil {
+				continue
+			}
+			errs = append(errs, fmt.Errorf("webhook: delivery %s dead letter save error: %w", dl.ID, err))
+		}
+
+		dl.NextAttempt = time.Now().Add(d.retryInterval << (min(dl.Attempts, d.maxAttempts) - 1))
+
+		retry = append(retry, dl)
+	}
+
+	d.mu.Lock()
+	d.queue = append(d.queue, retry...)
+	d.inflight = nil
+	if err := d.save(); err != nil {
+		errs = append(errs, fmt.Errorf("webhook: %w", err))
+	}
+	d.mu.Unlock()
+
+	return errors.Join(errs...)
+}
+
+// Sign calculates signature of the body with specified secret
+// in format used by `X-Redmine-Webhook-Signature` header
+func Sign(secret string, body []byte) string {
+	m := hmac.New(sha256.New, []byte(secret))
+	m.Write(body)
+	return "sha256=" + hex.EncodeToString(m.Sum(nil))
+}
+
+// Verify checks the body signature received by subscriber
+func Verify(secret string, body []byte, signature string) bool {
+	return hmac.Equal([]byte(Sign(secret, body)), []byte(signature))
+}
+
+// enqueue adds deliveries into the queue and saves it into the queue store
+func (d *Dispatcher) enqueue(ds ...Delivery) error {
+
+	if len(ds) == 0 {
+		return nil
+	}
+
+	d.mu.Lock()
+
+	if err := d.load(); err != nil {
+		d.mu.Unlock()
+		return err
+	}
+
+	n := len(d.queue)
+	d.queue = append(d.queue, ds...)
+
+	if err := d.save(); err != nil {
+		d.queue = d.queue[:n]
+		d.mu.Unlock()
+		return err
+	}
+
+	d.mu.Unlock()
+
+	select {
+	case d.wake <- struct{}{}:
+	default:
+	}
+
+	return nil
+}
+
+// load reads the queue from the queue store once. Secrets of the deliveries
+// are restored from subscriptions, deliveries of unknown subscriptions are
+// moved to dead letters. Must be called with the lock held
+func (d *Dispatcher) load() error {
+
+	if d.loaded == true {
+		return nil
+	}
+
+	ds, err := d.queueStore.Load()
+	if err != nil {
+		return fmt.Errorf("queue load error: %w", err)
+	}
+
+	var q []Delivery
+
+	for _, dl := range ds {
+
+		i := slices.IndexFunc(d.subscriptions, func(s Subscription) bool {
+			return s.ID == dl.SubscriptionID
+		})
+		if i < 0 {
+			dl.LastError = fmt.Sprintf("unknown subscription `%s`", dl.SubscriptionID)
+			if err := d.deadLetters.Save(dl); err != nil {
+				return fmt.Errorf("dead letter save error: %w", err)
+			}
+			continue
+		}
+
+		dl.secret = d.subscriptions[i].Secret
+		q = append(q, dl)
+	}
+
+	d.queue = append(q, d.queue...)
+	d.loaded = true
+
+	// Deliveries moved to dead letters are removed from the store
+	if len(q) != len(ds) {
+		return d.save()
+	}
+
+	return nil
+}
+
+// save writes waiting and being sent deliveries into the queue store.
+// Must be called with the lock held
+func (d *Dispatcher) save() error {
+
+	ds := append(append([]Delivery{}, d.queue...), d.inflight...)
+
+	if err := d.queueStore.Save(ds); err != nil {
+		return fmt.Errorf("queue save error: %w", err)
+	}
+
+	return nil
+}
+
+func (d *Dispatcher) deliver(ctx context.Context, dl Delivery) error {
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, dl.URL, bytes.NewReader(dl.Body))
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HeaderEvent, string(dl.Event))
+	req.Header.Set(HeaderDelivery, dl.ID)
+	if dl.secret != "" {
+		req.Header.Set(HeaderSignature, Sign(dl.secret, dl.Body))
+	}
+
+	res, err := d.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	io.Copy(io.Discard, res.Body)
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return fmt.Errorf("unexpected status code has been returned (returned: %d, url: %s)", res.StatusCode, dl.URL)
+	}
+
+	return nil
+}
+
+func (s Subscription) match(e poller.Event) bool {
+
+	if len(s.EventTypes) > 0 && func() bool {
+		for _, t := range s.EventTypes {
+			if t == e.Type {
+				return true
+			}
+		}
+		return false
+	}() == false {
+		return false
+	}
+
+	if len(s.ProjectIDs) > 0 && contains(s.ProjectIDs, e.Issue.Project.ID) == false {
+		return false
+	}
+
+	if len(s.TrackerIDs) > 0 && contains(s.TrackerIDs, e.Issue.Tracker.ID) == false {
+		return false
+	}
+
+	return true
+}
+
+func contains(ids []int64, id int64) bool {
+	for _, e := range ids {
+		if e == id {
+			return true
+		}
+	}
+	return false
+}
+
+func deliveryID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
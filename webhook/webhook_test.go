@@ -0,0 +1,262 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	redmine "github.com/nixys/nxs-go-redmine/v5"
+	"github.com/nixys/nxs-go-redmine/v5/poller"
+)
+
+const testSecret = "secret"
+
+func TestDispatcher(t *testing.T) {
+
+	var (
+		received int
+		fail     = true
+	)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+
+		b, _ := io.ReadAll(req.Body)
+
+		if Verify(testSecret, b, req.Header.Get(HeaderSignature)) == false {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		if fail == true {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		received++
+	}))
+	defer srv.Close()
+
+	dl := FileDeadLetterStoreInit(filepath.Join(t.TempDir(), "dead.jsonl"))
+
+	d := Init(Settings{
+		Subscriptions: []Subscription{
+			{
+				ID:         "bot",
+				URL:        srv.URL,
+				Secret:     testSecret,
+				ProjectIDs: []int64{1},
+				EventTypes: []poller.EventType{poller.EventCommented},
+			},
+		},
+		MaxAttempts:   2,
+		RetryInterval: time.Millisecond,
+		DeadLetters:   dl,
+	})
+
+	events := []poller.Event{
+		{Type: poller.EventCommented, Issue: redmine.IssueObject{ID: 1, Project: redmine.IDName{ID: 1}}},
+		{Type: poller.EventCommented, Issue: redmine.IssueObject{ID: 2, Project: redmine.IDName{ID: 2}}},
+		{Type: poller.EventAssigned, Issue: redmine.IssueObject{ID: 1, Project: redmine.IDName{ID: 1}}},
+	}
+
+	for _, e := range events {
+		if err := d.Handle(e); err != nil {
+			t.Fatal("Dispatcher handle error:", err)
+		}
+	}
+
+	if d.Pending() != 1 {
+		t.Fatal("Dispatcher handle error: incorrect filtered deliveries count:", d.Pending())
+	}
+
+	// Both attempts fail, delivery goes to dead letters
+	for i := 0; i < 2; i++ {
+		time.Sleep(2 * time.Millisecond)
+		if err := d.Process(context.Background()); err != nil {
+			t.Fatal("Dispatcher process error:", err)
+		}
+	}
+
+	ds, err := dl.List()
+	if err != nil {
+		t.Fatal("Dispatcher dead letters list error:", err)
+	}
+
+	if len(ds) != 1 || ds[0].Attempts != 2 || d.Pending() != 0 {
+		t.Fatal("Dispatcher process error: delivery has not been moved to dead letters")
+	}
+
+	// Redeliver
+	fail = false
+
+	if err := d.Redeliver(ds[0]); err != nil {
+		t.Fatal("Dispatcher redeliver error:", err)
+	}
+
+	if err := d.Process(context.Background()); err != nil {
+		t.Fatal("Dispatcher process error:", err)
+	}
+
+	if received != 1 {
+		t.Fatal("Dispatcher process error: delivery has not been received")
+	}
+
+	if ds, err := dl.List(); err != nil || len(ds) != 0 {
+		t.Fatal("Dispatcher redeliver error: delivery is kept in dead letters:", err)
+	}
+
+	t.Logf("Dispatcher: success")
+}
+
+type failDeadLetterStore struct{}
+
+func (failDeadLetterStore) Save(Delivery) error {
+	return errors.New("store is unavailable")
+}
+
+func (failDeadLetterStore) List() ([]Delivery, error) {
+	return nil, nil
+}
+
+func (failDeadLetterStore) Delete(string) error {
+	return nil
+}
+
+func TestDispatcherDeadLetterError(t *testing.T) {
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer srv.Close()
+
+	d := Init(Settings{
+		Subscriptions: []Subscription{{ID: "bot", URL: srv.URL}},
+		MaxAttempts:   1,
+		RetryInterval: time.Millisecond,
+		DeadLetters:   failDeadLetterStore{},
+	})
+
+	for i := int64(1); i <= 2; i++ {
+		if err := d.Handle(poller.Event{Type: poller.EventIssueCreated, Issue: redmine.IssueObject{ID: i}}); err != nil {
+			t.Fatal("Dispatcher handle error:", err)
+		}
+	}
+
+	// Deliveries are kept in queue if dead letters are not saved
+	if err := d.Process(context.Background()); err == nil {
+		t.Fatal("Dispatcher process error: dead letter save error is not returned")
+	}
+
+	if d.Pending() != 2 {
+		t.Fatal("Dispatcher process error: deliveries are dropped:", d.Pending())
+	}
+}
+
+func TestDispatcherQueueStore(t *testing.T) {
+
+	var received int
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+
+		b, _ := io.ReadAll(req.Body)
+
+		if Verify(testSecret, b, req.Header.Get(HeaderSignature)) == false {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		received++
+	}))
+	defer srv.Close()
+
+	path := filepath.Join(t.TempDir(), "queue.json")
+
+	s := Settings{
+		Subscriptions: []Subscription{{ID: "bot", URL: srv.URL, Secret: testSecret}},
+		Queue:         FileQueueStoreInit(path),
+	}
+
+	// Deliveries are saved before handler returns
+	if err := Init(s).Handle(poller.Event{Type: poller.EventIssueCreated, Issue: redmine.IssueObject{ID: 1}}); err != nil {
+		t.Fatal("Dispatcher handle error:", err)
+	}
+
+	// Restarted dispatcher sends saved deliveries
+	s.Queue = FileQueueStoreInit(path)
+	d := Init(s)
+
+	if d.Pending() != 1 {
+		t.Fatal("Dispatcher queue error: saved deliveries are not loaded:", d.Pending())
+	}
+
+	if err := d.Process(context.Background()); err != nil {
+		t.Fatal("Dispatcher process error:", err)
+	}
+
+	if received != 1 {
+		t.Fatal("Dispatcher process error: saved delivery has not been received")
+	}
+
+	ds, err := FileQueueStoreInit(path).Load()
+	if err != nil {
+		t.Fatal("Queue store load error:", err)
+	}
+
+	if len(ds) != 0 {
+		t.Fatal("Dispatcher process error: sent deliveries are kept in store")
+	}
+}
+
+func TestDispatcherCancel(t *testing.T) {
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		cancel()
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	dl := MemoryDeadLetterStoreInit()
+
+	d := Init(Settings{
+		Subscriptions: []Subscription{{ID: "bot", URL: srv.URL}},
+		MaxAttempts:   1,
+		DeadLetters:   dl,
+	})
+
+	for i := int64(1); i <= 2; i++ {
+		if err := d.Handle(poller.Event{Type: poller.EventIssueCreated, Issue: redmine.IssueObject{ID: i}}); err != nil {
+			t.Fatal("Dispatcher handle error:", err)
+		}
+	}
+
+	// Context is cancelled while the first delivery is sent
+	if err := d.Process(ctx); errors.Is(err, context.Canceled) == false {
+		t.Fatal("Dispatcher process error: context error is not returned:", err)
+	}
+
+	ds, _ := dl.List()
+	if len(ds) != 0 {
+		t.Fatal("Dispatcher process error: deliveries are moved to dead letters on cancel")
+	}
+
+	if d.Pending() != 2 {
+		t.Fatal("Dispatcher process error: deliveries are dropped on cancel:", d.Pending())
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	for _, e := range d.queue {
+		if e.Attempts != 0 {
+			t.Fatal("Dispatcher process error: attempts are counted on cancel")
+		}
+	}
+}
@@ -20,8 +20,8 @@ type WikiMultiObject struct {
 	Title     string            `json:"title"`
 	Parent    *WikiParentObject `json:"parent"`
 	Version   int64             `json:"version"`
-	CreatedOn string            `json:"created_on"`
-	UpdatedOn string            `json:"updated_on"`
+	CreatedOn DateTime          `json:"created_on"`
+	UpdatedOn DateTime          `json:"updated_on"`
 }
 
 // WikiObject struct used for wiki get operations
@@ -32,8 +32,8 @@ type WikiObject struct {
 	Version     int64               `json:"version"`
 	Author      IDName              `json:"author"`
 	Comments    string              `json:"comments"`
-	CreatedOn   string              `json:"created_on"`
-	UpdatedOn   string              `json:"updated_on"`
+	CreatedOn   DateTime            `json:"created_on"`
+	UpdatedOn   DateTime            `json:"updated_on"`
 	Attachments *[]AttachmentObject `json:"attachments"`
 }
 
@@ -50,9 +50,10 @@ type WikiCreate struct {
 }
 
 type WikiCreateObject struct {
-	Text     string                    `json:"text"`
-	Comments *string                   `json:"comments,omitempty"`
-	Uploads  *[]AttachmentUploadObject `json:"uploads,omitempty"`
+	Text        string                    `json:"text"`
+	Comments    *string                   `json:"comments,omitempty"`
+	ParentTitle *string                   `json:"parent_title,omitempty"`
+	Uploads     *[]AttachmentUploadObject `json:"uploads,omitempty"`
 }
 
 /* Update */
@@ -63,10 +64,11 @@ type WikiUpdate struct {
 }
 
 type WikiUpdateObject struct {
-	Text     string                    `json:"text"`
-	Comments *string                   `json:"comments,omitempty"`
-	Version  *int64                    `json:"version,omitempty"`
-	Uploads  *[]AttachmentUploadObject `json:"uploads,omitempty"`
+	Text        string                    `json:"text"`
+	Comments    *string                   `json:"comments,omitempty"`
+	Version     *int64                    `json:"version,omitempty"`
+	ParentTitle *string                   `json:"parent_title,omitempty"`
+	Uploads     *[]AttachmentUploadObject `json:"uploads,omitempty"`
 }
 
 /* Requests */